@@ -0,0 +1,67 @@
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"hashmaps/chainedmap"
+)
+
+// concurrentMap is what SafeHashMap and ShardedMap have in common
+type concurrentMap interface {
+	Get(key int) (int, bool)
+	Set(key int, value int)
+}
+
+// BenchmarkConcurrent runs b.RunParallel workloads on SafeHashMap, one
+// RWMutex for the whole map, and on ShardedMap at GOMAXPROCS 1 to 8.
+// With the writes spread over shards the sharded map should scale with
+// the number of cores, the single lock shouldn't. On a one core machine
+// every GOMAXPROCS measures the same.
+func BenchmarkConcurrent(b *testing.B) {
+	const keySpace = 1 << 16
+	maps := []struct {
+		name string
+		make func() concurrentMap
+	}{
+		{name: "safe", make: func() concurrentMap { return chainedmap.MakeSafeHashMap[int, int]() }},
+		// made after GOMAXPROCS is set, the shard count depends on it
+		{name: "sharded", make: func() concurrentMap { return chainedmap.MakeShardedMap[int, int]() }},
+	}
+	workloads := []struct {
+		name   string
+		writes int // out of 10 operations, the rest are Gets
+	}{
+		{name: "set", writes: 10},
+		{name: "mixed", writes: 1},
+	}
+	for _, workload := range workloads {
+		for _, m := range maps {
+			for _, procs := range []int{1, 2, 4, 8} {
+				b.Run(fmt.Sprintf("%s/%s/procs=%d", workload.name, m.name, procs), func(b *testing.B) {
+					defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+					cm := m.make()
+					for key := 0; key < keySpace; key += 2 { // half of the Gets hit
+						cm.Set(key, key)
+					}
+					var seed atomic.Uint64
+					b.ResetTimer()
+					b.RunParallel(func(pb *testing.PB) {
+						random := rand.New(rand.NewPCG(seed.Add(1), 0))
+						for pb.Next() {
+							key := random.IntN(keySpace)
+							if random.IntN(10) < workload.writes {
+								cm.Set(key, key)
+							} else {
+								cm.Get(key)
+							}
+						}
+					})
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// Package benchmarks compares the hashmap implementations with each other
+// and with the built-in map: Set, Get, Delete and a mixed workload, for
+// int, string, float, defined int and struct keys at several sizes.
+// The queue.Queue ring buffer is compared with an append and reslice queue.
+//
+// The package has no code of its own, the benchmarks are in its test files
+// and run under go test, named workload/implementation/key type/size:
+//
+//	go test -bench 'Get/swissmap/string' -benchmem ./benchmarks
+//
+// Runs with -count 10 can be compared with benchstat.
+package benchmarks
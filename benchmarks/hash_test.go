@@ -0,0 +1,50 @@
+package benchmarks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"testing"
+
+	"hashmaps/chainedmap"
+)
+
+// gobSHA256 is how the maps hashed every key before they used hash/maphash,
+// and how they still hash key types without a fast path
+func gobSHA256[K comparable](key K) uint64 {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(key); err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(buffer.Bytes())
+	return binary.LittleEndian.Uint64(sum[:8])
+}
+
+// BenchmarkHash is a Set and a Get on the chained map per operation, over
+// 1000 distinct keys, with the gob and sha256 hash and with the default one
+func BenchmarkHash(b *testing.B) {
+	b.Run("gob-sha256/string", func(b *testing.B) {
+		benchmarkSetGet(b, chainedmap.MakeHashMapWithHasher[string, int](gobSHA256[string]), makeKeys(1000, stringKey))
+	})
+	b.Run("default/string", func(b *testing.B) {
+		benchmarkSetGet(b, chainedmap.MakeHashMap[string, int](), makeKeys(1000, stringKey))
+	})
+	b.Run("gob-sha256/int", func(b *testing.B) {
+		benchmarkSetGet(b, chainedmap.MakeHashMapWithHasher[int, int](gobSHA256[int]), makeKeys(1000, intKey))
+	})
+	b.Run("default/int", func(b *testing.B) {
+		benchmarkSetGet(b, chainedmap.MakeHashMap[int, int](), makeKeys(1000, intKey))
+	})
+}
+
+func benchmarkSetGet[K comparable](b *testing.B, m hashMap[K, int], keys []K) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		m.Set(key, i)
+		if m.Get(key) == nil {
+			b.Fatal("key not found")
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+
+	"hashmaps/chainedmap"
+	"hashmaps/cuckoo"
+	"hashmaps/openmap"
+	"hashmaps/robinhood"
+	"hashmaps/simplemap"
+	"hashmaps/swissmap"
+)
+
+// hashMap is the part of the map API the benchmarks use, every HashMap
+// in the repo has it
+type hashMap[K comparable, V any] interface {
+	Get(key K) *V
+	Set(key K, value V)
+	Remove(key K)
+}
+
+// builtinMap gives map[K]V the hashMap methods. Get returns a pointer to a copy
+// kept in the struct, &value of a local would allocate on every lookup.
+type builtinMap[K comparable, V any] struct {
+	m     map[K]V
+	found V
+}
+
+func (b *builtinMap[K, V]) Get(key K) *V {
+	value, ok := b.m[key]
+	if !ok {
+		return nil
+	}
+	b.found = value
+	return &b.found
+}
+
+func (b *builtinMap[K, V]) Set(key K, value V) {
+	b.m[key] = value
+}
+
+func (b *builtinMap[K, V]) Remove(key K) {
+	delete(b.m, key)
+}
+
+type implementation[K comparable] struct {
+	name    string
+	make    func() hashMap[K, int]
+	maxSize int // 0 means no limit
+}
+
+func implementations[K comparable]() []implementation[K] {
+	return []implementation[K]{
+		{name: "builtin", make: func() hashMap[K, int] { return &builtinMap[K, int]{m: make(map[K]int)} }},
+		// simplemap doubles its table on every collision, beyond a few
+		// thousand keys it takes gigabytes or gives up
+		{name: "simplemap", make: func() hashMap[K, int] { return simplemap.MakeHashMap[K, int]() }, maxSize: 1000},
+		{name: "chainedmap", make: func() hashMap[K, int] { return chainedmap.MakeHashMap[K, int]() }},
+		{name: "openmap", make: func() hashMap[K, int] { return openmap.MakeHashMap[K, int]() }},
+		{name: "robinhood", make: func() hashMap[K, int] { return robinhood.MakeHashMap[K, int]() }},
+		{name: "cuckoo", make: func() hashMap[K, int] { return cuckoo.MakeHashMap[K, int]() }},
+		{name: "swissmap", make: func() hashMap[K, int] { return swissmap.MakeHashMap[K, int]() }},
+	}
+}
+
+// structKey is the composite key type, the hashmaps gob encode it for hashing
+type structKey struct {
+	ID   int
+	Name string
+}
+
+// definedKey is an int under another name, which a type switch on int misses
+type definedKey int
+
+// sizes are the numbers of entries every workload runs at
+var sizes = []int{100, 1_000, 100_000}
+
+func BenchmarkSet(b *testing.B)    { runWorkload(b, "Set") }
+func BenchmarkGet(b *testing.B)    { runWorkload(b, "Get") }
+func BenchmarkDelete(b *testing.B) { runWorkload(b, "Delete") }
+func BenchmarkMixed(b *testing.B)  { runWorkload(b, "Mixed") }
+
+func runWorkload(b *testing.B, workload string) {
+	runKeyType(b, workload, "int", intKey)
+	runKeyType(b, workload, "string", stringKey)
+	runKeyType(b, workload, "float", func(i int) float64 { return float64(i) / 8 })
+	runKeyType(b, workload, "defined", func(i int) definedKey { return definedKey(i) })
+	runKeyType(b, workload, "struct", func(i int) structKey { return structKey{ID: i, Name: "key"} })
+}
+
+func intKey(i int) int {
+	return i
+}
+
+func stringKey(i int) string {
+	return "key-" + strconv.Itoa(i)
+}
+
+func runKeyType[K comparable](b *testing.B, workload, keyType string, key func(i int) K) {
+	for _, impl := range implementations[K]() {
+		for _, size := range sizes {
+			if impl.maxSize > 0 && size > impl.maxSize {
+				continue
+			}
+			b.Run(fmt.Sprintf("%s/%s/%d", impl.name, keyType, size), func(b *testing.B) {
+				keys := makeKeys(2*size, key) // the second half is never set before the benchmark
+				switch workload {
+				case "Set":
+					benchmarkSet(b, impl.make, keys[:size])
+				case "Get":
+					benchmarkGet(b, impl.make, keys[:size])
+				case "Delete":
+					benchmarkDelete(b, impl.make, keys[:size])
+				case "Mixed":
+					benchmarkMixed(b, impl.make, keys)
+				}
+			})
+		}
+	}
+}
+
+func makeKeys[K any](n int, key func(i int) K) []K {
+	keys := make([]K, n)
+	for i := range keys {
+		keys[i] = key(i)
+	}
+	return keys
+}
+
+func fill[K comparable](m hashMap[K, int], keys []K) {
+	for i, key := range keys {
+		m.Set(key, i)
+	}
+}
+
+// benchmarkSet fills a new map with keys over and over, growth included
+func benchmarkSet[K comparable](b *testing.B, makeMap func() hashMap[K, int], keys []K) {
+	b.ReportAllocs()
+	var m hashMap[K, int]
+	for i := 0; i < b.N; i++ {
+		if i%len(keys) == 0 {
+			m = makeMap()
+		}
+		m.Set(keys[i%len(keys)], i)
+	}
+}
+
+// benchmarkGet looks up keys that are all in the map
+func benchmarkGet[K comparable](b *testing.B, makeMap func() hashMap[K, int], keys []K) {
+	m := makeMap()
+	fill(m, keys)
+	order := rand.New(rand.NewPCG(1, 2)).Perm(len(keys))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if m.Get(keys[order[i%len(order)]]) == nil {
+			b.Fatal("key not found")
+		}
+	}
+}
+
+// benchmarkDelete empties a full map, refilling it with the timer stopped
+func benchmarkDelete[K comparable](b *testing.B, makeMap func() hashMap[K, int], keys []K) {
+	m := makeMap()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%len(keys) == 0 {
+			b.StopTimer()
+			fill(m, keys)
+			b.StartTimer()
+		}
+		m.Remove(keys[i%len(keys)])
+	}
+}
+
+// benchmarkMixed runs 80% Get, 10% Set and 10% Delete on random keys, half
+// of which are in the map at the start, so lookups both hit and miss
+func benchmarkMixed[K comparable](b *testing.B, makeMap func() hashMap[K, int], keys []K) {
+	m := makeMap()
+	fill(m, keys[:len(keys)/2])
+	random := rand.New(rand.NewPCG(1, 2))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[random.IntN(len(keys))]
+		switch op := random.IntN(10); {
+		case op < 8:
+			m.Get(key)
+		case op == 8:
+			m.Set(key, i)
+		default:
+			m.Remove(key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"hashmaps/chainedmap"
+	"hashmaps/openmap"
+	"hashmaps/robinhood"
+	"hashmaps/simplemap"
+)
+
+// BenchmarkSetGetDelete builds a map of 1000 int keys per operation: it
+// sets all of them, gets all of them and deletes half, so tombstones and
+// the tables they leave behind are part of the cost
+func BenchmarkSetGetDelete(b *testing.B) {
+	keys := makeKeys(1000, intKey)
+	impls := []struct {
+		name string
+		make func() hashMap[int, int]
+	}{
+		{name: "simplemap", make: func() hashMap[int, int] { return simplemap.MakeHashMap[int, int]() }},
+		{name: "chainedmap", make: func() hashMap[int, int] { return chainedmap.MakeHashMap[int, int]() }},
+		{name: "openmap", make: func() hashMap[int, int] { return openmap.MakeHashMap[int, int]() }},
+		{name: "robinhood", make: func() hashMap[int, int] { return robinhood.MakeHashMap[int, int]() }},
+	}
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m := impl.make()
+				fill(m, keys)
+				for _, key := range keys {
+					if m.Get(key) == nil {
+						b.Fatal("key not found")
+					}
+				}
+				for _, key := range keys[:len(keys)/2] {
+					m.Remove(key)
+				}
+			}
+		})
+	}
+}
+
+// probeStats is what openmap.ProbeStats and robinhood.ProbeStats have in common
+type probeStats struct {
+	max            int
+	mean, variance float64
+}
+
+// BenchmarkProbeLength fills both open addressing maps with random int keys
+// to exactly the given load, 2^17 slots, and reports how far the entries
+// sit from their home slot. Robin Hood hashing has the same mean distance
+// but a much lower max and variance. ns/op is the time to fill the map.
+func BenchmarkProbeLength(b *testing.B) {
+	const slots = 1 << 17
+	for _, load := range []float64{0.70, 0.85} {
+		n := int(load * slots)
+		keys := make([]int, n)
+		random := rand.New(rand.NewPCG(1, 2))
+		for i := range keys {
+			keys[i] = random.Int()
+		}
+		// a max load factor just above load keeps the map from doubling to 2^18
+		maxLoadFactor := load + 0.01
+		b.Run(fmt.Sprintf("openmap/%.2f", load), func(b *testing.B) {
+			var stats openmap.ProbeStats
+			for i := 0; i < b.N; i++ {
+				m, _ := openmap.MakeHashMapWithLoadFactor[int, int](maxLoadFactor)
+				fill(m, keys)
+				stats = m.ProbeStats()
+			}
+			reportProbeStats(b, probeStats{stats.Max, stats.Mean, stats.Variance})
+		})
+		b.Run(fmt.Sprintf("robinhood/%.2f", load), func(b *testing.B) {
+			var stats robinhood.ProbeStats
+			for i := 0; i < b.N; i++ {
+				m, _ := robinhood.MakeHashMapWithLoadFactor[int, int](maxLoadFactor)
+				fill(m, keys)
+				stats = m.ProbeStats()
+			}
+			reportProbeStats(b, probeStats{stats.Max, stats.Mean, stats.Variance})
+		})
+	}
+}
+
+func reportProbeStats(b *testing.B, stats probeStats) {
+	b.ReportMetric(float64(stats.max), "max-probe")
+	b.ReportMetric(stats.mean, "mean-probe")
+	b.ReportMetric(stats.variance, "probe-variance")
+}
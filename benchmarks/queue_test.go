@@ -0,0 +1,67 @@
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"hashmaps/queue"
+)
+
+// fifo is the part of the queue API the benchmarks use
+type fifo interface {
+	Enqueue(item int)
+	Dequeue() (int, bool)
+}
+
+// sliceQueue is the usual append and reslice queue. The slice header walks
+// forward through the array, so append keeps copying the live items into
+// new arrays and each old one stays reachable until the next copy.
+type sliceQueue struct {
+	items []int
+}
+
+func (q *sliceQueue) Enqueue(item int) {
+	q.items = append(q.items, item)
+}
+
+func (q *sliceQueue) Dequeue() (int, bool) {
+	if len(q.items) == 0 {
+		return 0, false
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	return item, true
+}
+
+// BenchmarkQueue keeps size items queued, enqueueing one and dequeueing
+// one per iteration, like a work queue in a steady state
+func BenchmarkQueue(b *testing.B) {
+	queues := []struct {
+		name string
+		make func() fifo
+	}{
+		{name: "slice", make: func() fifo { return &sliceQueue{} }},
+		{name: "ring", make: func() fifo { return queue.MakeQueue[int]() }},
+	}
+	for _, q := range queues {
+		for _, size := range sizes {
+			b.Run(fmt.Sprintf("%s/%d", q.name, size), func(b *testing.B) {
+				benchmarkQueue(b, q.make(), size)
+			})
+		}
+	}
+}
+
+func benchmarkQueue(b *testing.B, q fifo, size int) {
+	for i := 0; i < size; i++ {
+		q.Enqueue(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Enqueue(i)
+		if _, ok := q.Dequeue(); !ok {
+			b.Fatal("queue empty")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"hashmaps/chainedmap"
+	"hashmaps/swissmap"
+)
+
+// BenchmarkStringThroughput inserts 100k distinct string keys into a new
+// map per operation, or looks all of them up in a full one, and reports
+// the time per key. It compares the control byte groups of swissmap with
+// the chains of chainedmap and with the built-in map.
+func BenchmarkStringThroughput(b *testing.B) {
+	keys := makeKeys(100_000, stringKey)
+	impls := []struct {
+		name string
+		make func() hashMap[string, int]
+	}{
+		{name: "swissmap", make: func() hashMap[string, int] { return swissmap.MakeHashMap[string, int]() }},
+		{name: "chainedmap", make: func() hashMap[string, int] { return chainedmap.MakeHashMap[string, int]() }},
+		{name: "builtin", make: func() hashMap[string, int] { return &builtinMap[string, int]{m: make(map[string]int)} }},
+	}
+	for _, impl := range impls {
+		b.Run("insert/"+impl.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fill(impl.make(), keys)
+			}
+			reportPerKey(b, len(keys))
+		})
+		b.Run("lookup/"+impl.name, func(b *testing.B) {
+			m := impl.make()
+			fill(m, keys)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, key := range keys {
+					if m.Get(key) == nil {
+						b.Fatal("key not found")
+					}
+				}
+			}
+			reportPerKey(b, len(keys))
+		})
+	}
+}
+
+func reportPerKey(b *testing.B, keys int) {
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*keys), "ns/key")
+}
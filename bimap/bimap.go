@@ -0,0 +1,150 @@
+// Package bimap contains a bidirectional map: keys are unique and so are
+// values, and either one finds the other in O(1), e.g. for ID <-> name
+// translation tables.
+package bimap
+
+import (
+	"errors"
+	"iter"
+
+	"hashmaps/chainedmap"
+)
+
+// ConflictPolicy decides what Set does with a value that already belongs
+// to a different key. Giving an existing key a new value is never a conflict,
+// its old value is simply released.
+type ConflictPolicy int
+
+const (
+	ReplaceConflicting ConflictPolicy = iota // the other key is deleted, like a forced put
+	RejectConflicting                        // Set fails with ErrValueTaken
+)
+
+var (
+	ErrValueTaken            = errors.New("value already belongs to another key")
+	ErrInvalidConflictPolicy = errors.New("unknown ConflictPolicy")
+)
+
+func (p ConflictPolicy) valid() bool {
+	return p == ReplaceConflicting || p == RejectConflicting
+}
+
+// BiMap keeps a forward and an inverse chainedmap.HashMap in sync.
+type BiMap[K, V comparable] struct {
+	forward *chainedmap.HashMap[K, V]
+	inverse *chainedmap.HashMap[V, K]
+	policy  ConflictPolicy
+}
+
+func MakeBiMap[K, V comparable]() *BiMap[K, V] {
+	m, _ := MakeBiMapWithConflictPolicy[K, V](ReplaceConflicting) // the default policy is always valid
+	return m
+}
+
+func MakeBiMapWithConflictPolicy[K, V comparable](policy ConflictPolicy) (*BiMap[K, V], error) {
+	if !policy.valid() {
+		return nil, ErrInvalidConflictPolicy
+	}
+	return &BiMap[K, V]{
+		forward: chainedmap.MakeHashMap[K, V](),
+		inverse: chainedmap.MakeHashMap[V, K](),
+		policy:  policy,
+	}, nil
+}
+
+// Inverse is a view of the same pairs with keys and values swapped,
+// changes through either one show up in both
+func (m *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	return &BiMap[V, K]{forward: m.inverse, inverse: m.forward, policy: m.policy}
+}
+
+func (m *BiMap[K, V]) GetByKey(key K) (V, bool) {
+	if value := m.forward.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *BiMap[K, V]) GetByValue(value V) (K, bool) {
+	if key := m.inverse.Get(value); key != nil {
+		return *key, true
+	}
+	var zero K
+	return zero, false
+}
+
+// Set panics when value is taken under RejectConflicting,
+// and like HashMap.Set when key or value can't be stored
+func (m *BiMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *BiMap[K, V]) TrySet(key K, value V) error {
+	// both sides are looked up before anything changes,
+	// so a key or value that can't be hashed leaves the maps in sync
+	oldValue, err := m.forward.TryGet(key)
+	if err != nil {
+		return err
+	}
+	owner, err := m.inverse.TryGet(value)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		if *owner == key {
+			return nil
+		}
+		if m.policy == RejectConflicting {
+			return ErrValueTaken
+		}
+		m.forward.Remove(*owner)
+	}
+	if oldValue != nil {
+		m.inverse.Remove(*oldValue)
+	}
+	m.forward.Set(key, value)
+	m.inverse.Set(value, key)
+	return nil
+}
+
+// Remove is DeleteByKey for callers that don't care about the old value
+func (m *BiMap[K, V]) Remove(key K) {
+	m.DeleteByKey(key)
+}
+
+// DeleteByKey removes the pair of key and returns its value, ok is false when key wasn't there
+func (m *BiMap[K, V]) DeleteByKey(key K) (V, bool) {
+	value, ok := m.forward.Delete(key)
+	if ok {
+		m.inverse.Remove(value)
+	}
+	return value, ok
+}
+
+// DeleteByValue removes the pair of value and returns its key, ok is false when value wasn't there
+func (m *BiMap[K, V]) DeleteByValue(value V) (K, bool) {
+	key, ok := m.inverse.Delete(value)
+	if ok {
+		m.forward.Remove(key)
+	}
+	return key, ok
+}
+
+// Len returns the number of pairs, in O(1)
+func (m *BiMap[K, V]) Len() int {
+	return m.forward.Len()
+}
+
+// Range calls fn for every pair until fn returns false.
+// The map must not be modified by fn.
+func (m *BiMap[K, V]) Range(fn func(key K, value V) bool) {
+	m.forward.Range(fn)
+}
+
+// All is the range-over-func form of Range
+func (m *BiMap[K, V]) All() iter.Seq2[K, V] {
+	return m.forward.Range
+}
@@ -0,0 +1,156 @@
+// Package bktree provides a Burkhard-Keller tree: a map that can be searched
+// for all keys within some distance of a query, e.g. for typo tolerant lookups.
+package bktree
+
+import (
+	"iter"
+	"sort"
+)
+
+type node[K, V any] struct {
+	key      K
+	value    V
+	children map[int]*node[K, V] // distance to this node -> subtree
+}
+
+// Match is a search result
+type Match[K, V any] struct {
+	Key      K
+	Value    V
+	Distance int
+}
+
+// BKTree needs distance to be a metric: non negative, zero only for equal keys,
+// symmetric and satisfying the triangle inequality. Thanks to the latter
+// a search only descends into children whose edge distance is within
+// maxDist of the distance to the query.
+type BKTree[K, V any] struct {
+	root     *node[K, V]
+	size     int
+	distance func(a, b K) int
+}
+
+func MakeBKTree[K, V any](distance func(a, b K) int) *BKTree[K, V] {
+	return &BKTree[K, V]{distance: distance}
+}
+
+func (t *BKTree[K, V]) Len() int {
+	return t.size
+}
+
+// Set inserts key, or replaces the value of a key at distance 0
+func (t *BKTree[K, V]) Set(key K, value V) {
+	if t.root == nil {
+		t.root = &node[K, V]{key: key, value: value}
+		t.size++
+		return
+	}
+	current := t.root
+	for {
+		d := t.distance(key, current.key)
+		if d == 0 {
+			current.value = value
+			return
+		}
+		child, ok := current.children[d]
+		if !ok {
+			if current.children == nil {
+				current.children = make(map[int]*node[K, V])
+			}
+			current.children[d] = &node[K, V]{key: key, value: value}
+			t.size++
+			return
+		}
+		current = child
+	}
+}
+
+// Get is an exact lookup
+func (t *BKTree[K, V]) Get(key K) (V, bool) {
+	for current := t.root; current != nil; {
+		d := t.distance(key, current.key)
+		if d == 0 {
+			return current.value, true
+		}
+		current = current.children[d]
+	}
+	var zero V
+	return zero, false
+}
+
+// All yields every entry in no particular order.
+// The tree must not be modified during the loop.
+func (t *BKTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		var stack []*node[K, V]
+		if t.root != nil {
+			stack = append(stack, t.root)
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(n.key, n.value) {
+				return
+			}
+			for _, child := range n.children {
+				stack = append(stack, child)
+			}
+		}
+	}
+}
+
+// Search returns every entry within maxDist of key, closest first
+func (t *BKTree[K, V]) Search(key K, maxDist int) []Match[K, V] {
+	var matches []Match[K, V]
+	if t.root == nil {
+		return matches
+	}
+	stack := []*node[K, V]{t.root}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := t.distance(key, current.key)
+		if d <= maxDist {
+			matches = append(matches, Match[K, V]{Key: current.key, Value: current.value, Distance: d})
+		}
+		for edge, child := range current.children {
+			if edge >= d-maxDist && edge <= d+maxDist {
+				stack = append(stack, child)
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+	return matches
+}
+
+// Levenshtein is the edit distance between two strings counted in runes
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			substitution := previous[j-1]
+			if ra[i-1] != rb[j-1] {
+				substitution++
+			}
+			current[j] = minOf(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func minOf(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,108 @@
+package bktree
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"gumbo", "gambol", 2},
+		{"żółw", "zółw", 1}, // runes, not bytes
+		{"日本語", "日本", 1},
+	} {
+		if got := Levenshtein(tc.a, tc.b); got != tc.want {
+			t.Fatalf("Levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := Levenshtein(tc.b, tc.a); got != tc.want {
+			t.Fatalf("Levenshtein(%q, %q) = %d, want %d, it must be symmetric", tc.b, tc.a, got, tc.want)
+		}
+	}
+}
+
+func randomWord(r *rand.Rand) string {
+	word := make([]byte, 1+r.IntN(6))
+	for i := range word {
+		word[i] = "abcd"[r.IntN(4)] // a small alphabet, so words are close to each other
+	}
+	return string(word)
+}
+
+// Search must find what measuring the distance to every key finds: the
+// triangle inequality may prune subtrees, but never ones with a match
+func TestSearchAgainstScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tree := MakeBKTree[string, int](Levenshtein)
+	model := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		word := randomWord(r)
+		tree.Set(word, i)
+		model[word] = i
+	}
+	if tree.Len() != len(model) {
+		t.Fatalf("Len() = %d, want %d", tree.Len(), len(model))
+	}
+	for word, value := range model {
+		if got, ok := tree.Get(word); !ok || got != value {
+			t.Fatalf("Get(%q) = %d, %v, want %d, the last Set", word, got, ok, value)
+		}
+	}
+	if _, ok := tree.Get("abcdabcd"); ok {
+		t.Fatal("Get found a word longer than any inserted")
+	}
+	for i := 0; i < 200; i++ {
+		query, maxDist := randomWord(r), r.IntN(4)-1 // -1 matches nothing
+		var want []Match[string, int]
+		for word, value := range model {
+			if d := Levenshtein(query, word); d <= maxDist {
+				want = append(want, Match[string, int]{Key: word, Value: value, Distance: d})
+			}
+		}
+		got := tree.Search(query, maxDist)
+		for i := 1; i < len(got); i++ {
+			if got[i].Distance < got[i-1].Distance {
+				t.Fatalf("Search(%q, %d) isn't ordered by distance: %v", query, maxDist, got)
+			}
+		}
+		byKey := func(a, b Match[string, int]) int { return strings.Compare(a.Key, b.Key) }
+		slices.SortFunc(got, byKey)
+		slices.SortFunc(want, byKey)
+		if !slices.Equal(got, want) {
+			t.Fatalf("Search(%q, %d) = %v, want %v", query, maxDist, got, want)
+		}
+	}
+	visited := 0
+	for word, value := range tree.All() {
+		if model[word] != value {
+			t.Fatalf("All() passed %q=%d, want %d", word, value, model[word])
+		}
+		visited++
+	}
+	if visited != len(model) {
+		t.Fatalf("All() visited %d of %d entries", visited, len(model))
+	}
+}
+
+func TestEmptyTree(t *testing.T) {
+	tree := MakeBKTree[int, string](func(a, b int) int { return max(a-b, b-a) })
+	if matches := tree.Search(5, 10); len(matches) != 0 {
+		t.Fatalf("Search of an empty tree = %v", matches)
+	}
+	if _, ok := tree.Get(5); ok {
+		t.Fatal("Get of an empty tree found a key")
+	}
+	tree.Set(5, "five")
+	tree.Set(5, "FIVE")
+	if value, _ := tree.Get(5); value != "FIVE" || tree.Len() != 1 {
+		t.Fatalf("Get(5) = %q with Len() %d after setting it twice", value, tree.Len())
+	}
+}
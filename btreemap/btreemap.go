@@ -0,0 +1,401 @@
+// Package btreemap is a sorted map backed by a B-tree, with the same API as
+// treemap. A node holds many entries in one slice instead of one entry per
+// node, so a lookup follows a few pointers and does its comparisons on
+// neighbouring memory, and there is far less per-entry overhead for the
+// garbage collector.
+package btreemap
+
+import (
+	"cmp"
+	"errors"
+	"iter"
+	"slices"
+
+	"hashmaps/constraints"
+)
+
+type item[K, V any] struct {
+	key   K
+	value V
+}
+
+// Every node except the root holds between degree-1 and 2*degree-1 items,
+// an inner node has one child more than it has items. All leaves are at
+// the same depth.
+type node[K, V any] struct {
+	items    []item[K, V]
+	children []*node[K, V] // empty for leaves
+}
+
+func (n *node[K, V]) leaf() bool {
+	return len(n.children) == 0
+}
+
+// BTreeMap orders its keys with less, which must be a strict weak ordering.
+// Keys a and b are the same key when neither is less than the other.
+type BTreeMap[K, V any] struct {
+	root   *node[K, V] // nil when the map is empty
+	length int
+	degree int
+	less   func(a, b K) bool
+}
+
+// 32 keeps a node's items within a few cache lines for small keys and values,
+// and the tree shallow
+const defaultDegree = 32
+
+var ErrInvalidDegree = errors.New("B-tree degree must be at least 2")
+
+func MakeBTreeMap[K, V any](less func(a, b K) bool) *BTreeMap[K, V] {
+	m, _ := MakeBTreeMapWithDegree[K, V](defaultDegree, less) // the default degree is always valid
+	return m
+}
+
+// MakeOrderedBTreeMap orders the keys with <, NaNs sort before every other float
+func MakeOrderedBTreeMap[K constraints.Ordered, V any]() *BTreeMap[K, V] {
+	return MakeBTreeMap[K, V](cmp.Less[K])
+}
+
+// MakeBTreeMapWithDegree sets the fan-out: nodes hold up to 2*degree-1 entries
+// and have up to 2*degree children
+func MakeBTreeMapWithDegree[K, V any](degree int, less func(a, b K) bool) (*BTreeMap[K, V], error) {
+	if degree < 2 {
+		return nil, ErrInvalidDegree
+	}
+	return &BTreeMap[K, V]{degree: degree, less: less}, nil
+}
+
+func (m *BTreeMap[K, V]) maxItems() int {
+	return 2*m.degree - 1
+}
+
+// Get returns a pointer to the value of key, or nil
+func (m *BTreeMap[K, V]) Get(key K) *V {
+	for n := m.root; n != nil; {
+		i, found := m.find(n, key)
+		if found {
+			return &n.items[i].value
+		}
+		if n.leaf() {
+			return nil
+		}
+		n = n.children[i]
+	}
+	return nil
+}
+
+// Set splits every full node on the way down, so there is always room
+// for the new item in the leaf and for a split child's median in its parent
+func (m *BTreeMap[K, V]) Set(key K, value V) {
+	if m.root == nil {
+		m.root = &node[K, V]{items: make([]item[K, V], 0, m.maxItems())}
+	}
+	if len(m.root.items) == m.maxItems() {
+		oldRoot := m.root
+		m.root = &node[K, V]{
+			items:    make([]item[K, V], 0, m.maxItems()),
+			children: append(make([]*node[K, V], 0, m.maxItems()+1), oldRoot),
+		}
+		m.splitChild(m.root, 0)
+	}
+	n := m.root
+	for {
+		i, found := m.find(n, key)
+		if found {
+			n.items[i].value = value
+			return
+		}
+		if n.leaf() {
+			n.items = slices.Insert(n.items, i, item[K, V]{key: key, value: value})
+			m.length++
+			return
+		}
+		if len(n.children[i].items) == m.maxItems() {
+			m.splitChild(n, i)
+			switch median := n.items[i].key; {
+			case m.less(median, key):
+				i++
+			case !m.less(key, median):
+				n.items[i].value = value
+				return
+			}
+		}
+		n = n.children[i]
+	}
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *BTreeMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *BTreeMap[K, V]) Delete(key K) (V, bool) {
+	if m.root == nil {
+		var zero V
+		return zero, false
+	}
+	removed, ok := m.remove(m.root, key, false)
+	if len(m.root.items) == 0 {
+		if m.root.leaf() {
+			m.root = nil
+		} else {
+			m.root = m.root.children[0] // the tree got one level shallower
+		}
+	}
+	if ok {
+		m.length--
+	}
+	return removed.value, ok
+}
+
+// Len returns the number of entries, in O(1)
+func (m *BTreeMap[K, V]) Len() int {
+	return m.length
+}
+
+// Min returns the entry with the smallest key, ok is false when the map is empty
+func (m *BTreeMap[K, V]) Min() (key K, value V, ok bool) {
+	n := m.root
+	if n == nil {
+		return key, value, false
+	}
+	for !n.leaf() {
+		n = n.children[0]
+	}
+	return n.items[0].key, n.items[0].value, true
+}
+
+// Max returns the entry with the largest key, ok is false when the map is empty
+func (m *BTreeMap[K, V]) Max() (key K, value V, ok bool) {
+	n := m.root
+	if n == nil {
+		return key, value, false
+	}
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+	last := n.items[len(n.items)-1]
+	return last.key, last.value, true
+}
+
+// Floor returns the entry with the largest key <= key, ok is false when there is none
+func (m *BTreeMap[K, V]) Floor(key K) (K, V, bool) {
+	var floor *item[K, V]
+	for n := m.root; n != nil; {
+		i, found := m.find(n, key)
+		if found {
+			return n.items[i].key, n.items[i].value, true
+		}
+		if i > 0 {
+			floor = &n.items[i-1]
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	return floor.entry()
+}
+
+// Ceiling returns the entry with the smallest key >= key, ok is false when there is none
+func (m *BTreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	var ceiling *item[K, V]
+	for n := m.root; n != nil; {
+		i, found := m.find(n, key)
+		if found {
+			return n.items[i].key, n.items[i].value, true
+		}
+		if i < len(n.items) {
+			ceiling = &n.items[i]
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	return ceiling.entry()
+}
+
+// Range calls fn for every entry in ascending key order until fn returns false.
+// The map must not be modified by fn.
+func (m *BTreeMap[K, V]) Range(fn func(key K, value V) bool) {
+	if m.root != nil {
+		m.ascend(m.root, nil, nil, fn)
+	}
+}
+
+// RangeBetween is Range limited to the keys from from, inclusive, to to, exclusive
+func (m *BTreeMap[K, V]) RangeBetween(from, to K, fn func(key K, value V) bool) {
+	if m.root != nil {
+		m.ascend(m.root, &from, &to, fn)
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range,
+// Between is the one of RangeBetween
+func (m *BTreeMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *BTreeMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *BTreeMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+func (m *BTreeMap[K, V]) Between(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.RangeBetween(from, to, yield)
+	}
+}
+
+// entry unpacks an item, ok is false for nil
+func (it *item[K, V]) entry() (key K, value V, ok bool) {
+	if it == nil {
+		return key, value, false
+	}
+	return it.key, it.value, true
+}
+
+// find binary searches n for the first item whose key isn't less than key.
+// found tells whether it is key itself, otherwise i is also the child key is in.
+func (m *BTreeMap[K, V]) find(n *node[K, V], key K) (i int, found bool) {
+	i, j := 0, len(n.items)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if m.less(n.items[h].key, key) {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i, i < len(n.items) && !m.less(key, n.items[i].key)
+}
+
+// ascend visits the entries of n's subtree with keys in [from, to), a nil
+// bound is open. It returns false once fn did, or once it passed to.
+func (m *BTreeMap[K, V]) ascend(n *node[K, V], from, to *K, fn func(K, V) bool) bool {
+	start := 0
+	if from != nil {
+		start, _ = m.find(n, *from)
+	}
+	for i := start; i < len(n.items); i++ {
+		if !n.leaf() && !m.ascend(n.children[i], from, to, fn) {
+			return false
+		}
+		if to != nil && !m.less(n.items[i].key, *to) {
+			return false
+		}
+		if !fn(n.items[i].key, n.items[i].value) {
+			return false
+		}
+	}
+	if !n.leaf() {
+		return m.ascend(n.children[len(n.items)], from, to, fn)
+	}
+	return true
+}
+
+// splitChild splits the full child i of n in two around its median item,
+// which moves up into n
+func (m *BTreeMap[K, V]) splitChild(n *node[K, V], i int) {
+	child := n.children[i]
+	mid := m.degree - 1
+	median := child.items[mid]
+	right := &node[K, V]{items: append(make([]item[K, V], 0, m.maxItems()), child.items[mid+1:]...)}
+	clear(child.items[mid:]) // drops the references the moved items held
+	child.items = child.items[:mid]
+	if !child.leaf() {
+		right.children = append(make([]*node[K, V], 0, m.maxItems()+1), child.children[mid+1:]...)
+		clear(child.children[mid+1:])
+		child.children = child.children[:mid+1]
+	}
+	n.items = slices.Insert(n.items, i, median)
+	n.children = slices.Insert(n.children, i+1, right)
+}
+
+// remove deletes key from n's subtree, or its largest item when max is set.
+// Before going down into a child it makes sure the child has an item to
+// spare, so removing from it never leaves it below the minimum.
+func (m *BTreeMap[K, V]) remove(n *node[K, V], key K, max bool) (item[K, V], bool) {
+	var i int
+	var found bool
+	if max {
+		i = len(n.items)
+		if n.leaf() {
+			i--
+			found = true
+		}
+	} else {
+		i, found = m.find(n, key)
+	}
+	if n.leaf() {
+		if !found {
+			return item[K, V]{}, false
+		}
+		removed := n.items[i]
+		n.items = slices.Delete(n.items, i, i+1)
+		return removed, true
+	}
+	if len(n.children[i].items) < m.degree {
+		m.growChild(n, i)
+		return m.remove(n, key, max) // items moved around, look again
+	}
+	if found {
+		// key is in an inner node, its predecessor from the left subtree takes its place
+		removed := n.items[i]
+		n.items[i], _ = m.remove(n.children[i], key, true)
+		return removed, true
+	}
+	return m.remove(n.children[i], key, max)
+}
+
+// growChild brings child i of n up to degree items, by taking an item from
+// a sibling that can spare one, or else by merging it with a sibling
+func (m *BTreeMap[K, V]) growChild(n *node[K, V], i int) {
+	child := n.children[i]
+	switch {
+	case i > 0 && len(n.children[i-1].items) >= m.degree:
+		// rotate right through the parent
+		left := n.children[i-1]
+		child.items = slices.Insert(child.items, 0, n.items[i-1])
+		n.items[i-1] = left.items[len(left.items)-1]
+		clear(left.items[len(left.items)-1:])
+		left.items = left.items[:len(left.items)-1]
+		if !left.leaf() {
+			child.children = slices.Insert(child.children, 0, left.children[len(left.children)-1])
+			clear(left.children[len(left.children)-1:])
+			left.children = left.children[:len(left.children)-1]
+		}
+	case i < len(n.items) && len(n.children[i+1].items) >= m.degree:
+		// rotate left through the parent
+		right := n.children[i+1]
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.items = slices.Delete(right.items, 0, 1)
+		if !right.leaf() {
+			child.children = append(child.children, right.children[0])
+			right.children = slices.Delete(right.children, 0, 1)
+		}
+	default:
+		if i == len(n.items) {
+			i--
+		}
+		left, right := n.children[i], n.children[i+1]
+		left.items = append(append(left.items, n.items[i]), right.items...)
+		left.children = append(left.children, right.children...)
+		n.items = slices.Delete(n.items, i, i+1)
+		n.children = slices.Delete(n.children, i+1, i+2)
+	}
+}
@@ -0,0 +1,196 @@
+package btreemap
+
+import (
+	"cmp"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkNodes fails when a node other than the root has too few or too many
+// items, an inner node has the wrong number of children, keys are out of
+// order or leaves are at different depths
+func checkNodes(t *testing.T, m *BTreeMap[int, int]) {
+	t.Helper()
+	leafDepth := -1
+	var check func(n *node[int, int], depth int, low, high *int)
+	check = func(n *node[int, int], depth int, low, high *int) {
+		if n != m.root && (len(n.items) < m.degree-1 || len(n.items) > m.maxItems()) {
+			t.Fatalf("node with %d items at degree %d", len(n.items), m.degree)
+		}
+		for i, it := range n.items {
+			if i > 0 && n.items[i-1].key >= it.key || low != nil && it.key <= *low || high != nil && it.key >= *high {
+				t.Fatalf("key %d out of order", it.key)
+			}
+		}
+		if n.leaf() {
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaves at depths %d and %d", leafDepth, depth)
+			}
+			return
+		}
+		if len(n.children) != len(n.items)+1 {
+			t.Fatalf("inner node with %d items and %d children", len(n.items), len(n.children))
+		}
+		for i, child := range n.children {
+			childLow, childHigh := low, high
+			if i > 0 {
+				childLow = &n.items[i-1].key
+			}
+			if i < len(n.items) {
+				childHigh = &n.items[i].key
+			}
+			check(child, depth+1, childLow, childHigh)
+		}
+	}
+	if m.root != nil {
+		check(m.root, 0, nil, nil)
+	}
+}
+
+func between(t *testing.T, m *BTreeMap[int, int], from, to int) []int {
+	t.Helper()
+	var keys []int
+	m.RangeBetween(from, to, func(key, value int) bool {
+		if value != -key {
+			t.Fatalf("RangeBetween(%d, %d) passed %d with the value %d", from, to, key, value)
+		}
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Floor, Ceiling and RangeBetween must find what a search of the sorted
+// keys finds, for present and missing keys, the ends and past them. Degree
+// 2 splits and merges nodes on almost every change.
+func TestOrderedQueriesAgainstSortedSlice(t *testing.T) {
+	for _, degree := range []int{2, 3, defaultDegree} {
+		r := rand.New(rand.NewPCG(1, uint64(degree)))
+		m, err := MakeBTreeMapWithDegree[int, int](degree, cmp.Less[int])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []int // sorted, all even so odd probes are always missing
+		for i := 0; i < 3000; i++ {
+			key := 2 * r.IntN(500)
+			position, found := slices.BinarySearch(keys, key)
+			if r.IntN(3) == 0 {
+				if _, ok := m.Delete(key); ok != found {
+					t.Fatalf("degree %d: Delete(%d) = %v, want %v", degree, key, ok, found)
+				}
+				if found {
+					keys = slices.Delete(keys, position, position+1)
+				}
+			} else {
+				m.Set(key, -key)
+				if !found {
+					keys = slices.Insert(keys, position, key)
+				}
+			}
+			if i%100 == 0 {
+				checkNodes(t, m)
+			}
+		}
+		checkNodes(t, m)
+		if m.Len() != len(keys) || !slices.Equal(slices.Collect(m.Keys()), keys) {
+			t.Fatalf("degree %d: Len() = %d with keys out of order", degree, m.Len())
+		}
+		if low, _, _ := m.Min(); low != keys[0] {
+			t.Fatalf("degree %d: Min() = %d, want %d", degree, low, keys[0])
+		}
+		if high, _, _ := m.Max(); high != keys[len(keys)-1] {
+			t.Fatalf("degree %d: Max() = %d, want %d", degree, high, keys[len(keys)-1])
+		}
+		for probe := -3; probe <= 1003; probe++ {
+			position, found := slices.BinarySearch(keys, probe)
+			floor, floorValue, ok := m.Floor(probe)
+			switch {
+			case found:
+				if !ok || floor != probe || floorValue != -probe {
+					t.Fatalf("degree %d: Floor(%d) = %d, %v for a present key", degree, probe, floor, ok)
+				}
+			case position == 0:
+				if ok {
+					t.Fatalf("degree %d: Floor(%d) = %d below the smallest key", degree, probe, floor)
+				}
+			default:
+				if !ok || floor != keys[position-1] {
+					t.Fatalf("degree %d: Floor(%d) = %d, %v, want %d", degree, probe, floor, ok, keys[position-1])
+				}
+			}
+			ceiling, _, ok := m.Ceiling(probe)
+			if position == len(keys) {
+				if ok {
+					t.Fatalf("degree %d: Ceiling(%d) = %d above the largest key", degree, probe, ceiling)
+				}
+			} else if !ok || ceiling != keys[position] {
+				t.Fatalf("degree %d: Ceiling(%d) = %d, %v, want %d", degree, probe, ceiling, ok, keys[position])
+			}
+		}
+		for i := 0; i < 500; i++ {
+			from, to := r.IntN(1010)-5, r.IntN(1010)-5
+			low, _ := slices.BinarySearch(keys, from)
+			high, _ := slices.BinarySearch(keys, to)
+			want := keys[low:max(low, high)]
+			if got := between(t, m, from, to); !slices.Equal(got, want) {
+				t.Fatalf("degree %d: RangeBetween(%d, %d) = %v, want %v", degree, from, to, got, want)
+			}
+		}
+		for _, key := range keys {
+			m.Remove(key)
+		}
+		if m.Len() != 0 || m.root != nil {
+			t.Fatalf("degree %d: removing every key left %d entries", degree, m.Len())
+		}
+	}
+}
+
+func TestEmptyAndSingleEntry(t *testing.T) {
+	m, err := MakeBTreeMapWithDegree[int, int](2, cmp.Less[int])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := m.Max(); ok {
+		t.Fatal("Max of an empty map found a key")
+	}
+	if _, _, ok := m.Floor(0); ok {
+		t.Fatal("Floor of an empty map found a key")
+	}
+	if _, _, ok := m.Ceiling(0); ok {
+		t.Fatal("Ceiling of an empty map found a key")
+	}
+	if got := between(t, m, -10, 10); len(got) != 0 {
+		t.Fatalf("RangeBetween on an empty map = %v", got)
+	}
+	m.Set(5, -5)
+	for _, tc := range []struct {
+		from, to int
+		want     []int
+	}{
+		{5, 6, []int{5}},
+		{5, 5, nil}, // to is exclusive
+		{6, 4, nil}, // from after to
+		{0, 5, nil},
+		{6, 100, nil},
+	} {
+		if got := between(t, m, tc.from, tc.to); !slices.Equal(got, tc.want) {
+			t.Fatalf("RangeBetween(%d, %d) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+	if floor, _, ok := m.Floor(4); ok {
+		t.Fatalf("Floor(4) = %d below the only key", floor)
+	}
+	if ceiling, _, ok := m.Ceiling(6); ok {
+		t.Fatalf("Ceiling(6) = %d above the only key", ceiling)
+	}
+}
+
+func TestInvalidDegree(t *testing.T) {
+	if _, err := MakeBTreeMapWithDegree[int, int](1, cmp.Less[int]); !errors.Is(err, ErrInvalidDegree) {
+		t.Fatalf("degree 1: err = %v, want ErrInvalidDegree", err)
+	}
+}
@@ -0,0 +1,50 @@
+package cache
+
+// EvictReason tells OnEvict why an entry left the cache
+type EvictReason int
+
+const (
+	EvictedForSpace EvictReason = iota // pushed out to make room for another entry
+	Expired                            // its ttl ran out
+	Removed                            // Delete or Remove was called
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictedForSpace:
+		return "evicted for space"
+	case Expired:
+		return "expired"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Hooks are called after the cache changed, e.g. to count evictions or to
+// release what an evicted value holds. Nil hooks are skipped. They run
+// synchronously, while a cache that locks still holds its lock, so they
+// must not call methods of the cache.
+type Hooks[K comparable, V any] struct {
+	OnInsert func(key K, value V)              // key wasn't in the cache before
+	OnUpdate func(key K, oldValue, newValue V) // key's value was replaced
+	OnEvict  func(key K, value V, reason EvictReason)
+}
+
+func (h *Hooks[K, V]) insert(key K, value V) {
+	if h.OnInsert != nil {
+		h.OnInsert(key, value)
+	}
+}
+
+func (h *Hooks[K, V]) update(key K, oldValue, newValue V) {
+	if h.OnUpdate != nil {
+		h.OnUpdate(key, oldValue, newValue)
+	}
+}
+
+func (h *Hooks[K, V]) evict(key K, value V, reason EvictReason) {
+	if h.OnEvict != nil {
+		h.OnEvict(key, value, reason)
+	}
+}
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"iter"
+
+	"hashmaps/chainedmap"
+)
+
+// Cache is what LRUCache and LFUCache have in common, so the eviction
+// policy can be picked without touching the code that uses the cache
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Peek(key K) (V, bool)
+	Set(key K, value V) (evicted K, ok bool)
+	Remove(key K)
+	Delete(key K) (V, bool)
+	Len() int
+	Range(fn func(key K, value V) bool)
+	All() iter.Seq2[K, V]
+}
+
+// LFUCache holds up to a fixed number of entries and evicts the least
+// frequently used one to make room, among those the least recently used.
+//
+// Entries with the same use count share a bucket, a list ordered by last
+// use, and the buckets form a list ordered by count. A use moves an entry
+// from its bucket to the next one, creating it when the count is new, so
+// Get, Set and eviction are all O(1).
+type LFUCache[K comparable, V any] struct {
+	entries  *chainedmap.HashMap[K, *lfuEntry[K, V]]
+	buckets  lfuBucket[K, V] // sentinel, buckets.next has the lowest count
+	capacity int
+	hooks    Hooks[K, V]
+}
+
+type lfuEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	bucket     *lfuBucket[K, V]
+	prev, next *lfuEntry[K, V]
+}
+
+type lfuBucket[K comparable, V any] struct {
+	count      int
+	entries    lfuEntry[K, V] // sentinel, entries.next was used longest ago
+	prev, next *lfuBucket[K, V]
+}
+
+// MakeLFUCache takes WithHooks
+func MakeLFUCache[K comparable, V any](capacity int, opts ...Option) *LFUCache[K, V] {
+	hooks, _ := makeConfig[K, V](opts, false)
+	c := &LFUCache[K, V]{
+		entries:  chainedmap.MakeHashMap[K, *lfuEntry[K, V]](),
+		capacity: capacity,
+		hooks:    hooks,
+	}
+	c.buckets.prev, c.buckets.next = &c.buckets, &c.buckets
+	return c
+}
+
+// Get counts as a use of key
+func (c *LFUCache[K, V]) Get(key K) (V, bool) {
+	e := c.lookup(key)
+	if e == nil {
+		var zero V
+		return zero, false
+	}
+	c.touch(e)
+	return e.value, true
+}
+
+// Peek is Get without counting as a use
+func (c *LFUCache[K, V]) Peek(key K) (V, bool) {
+	if e := c.lookup(key); e != nil {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set counts as a use of key. A new key starts with a count of 1, to make room
+// for it the least frequently used entry is evicted and returned,
+// ok is false when nothing was evicted. With a capacity of 0 the new key
+// itself is evicted right away.
+func (c *LFUCache[K, V]) Set(key K, value V) (evicted K, ok bool) {
+	if e := c.lookup(key); e != nil {
+		old := e.value
+		e.value = value
+		c.touch(e)
+		c.hooks.update(key, old, value)
+		return evicted, false
+	}
+	if c.capacity <= 0 {
+		c.hooks.insert(key, value)
+		c.hooks.evict(key, value, EvictedForSpace)
+		return key, true
+	}
+	if c.entries.Len() >= c.capacity {
+		victim := c.buckets.next.entries.next
+		c.unlink(victim)
+		c.entries.Remove(victim.key)
+		c.hooks.evict(victim.key, victim.value, EvictedForSpace)
+		evicted, ok = victim.key, true
+	}
+	first := c.buckets.next
+	if first == &c.buckets || first.count != 1 {
+		first = c.insertBucketAfter(&c.buckets, 1)
+	}
+	e := &lfuEntry[K, V]{key: key, value: value}
+	c.entries.Set(key, e)
+	c.pushBack(first, e)
+	c.hooks.insert(key, value)
+	return evicted, ok
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (c *LFUCache[K, V]) Remove(key K) {
+	c.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (c *LFUCache[K, V]) Delete(key K) (V, bool) {
+	e, ok := c.entries.Delete(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.unlink(e)
+	c.hooks.evict(key, e.value, Removed)
+	return e.value, true
+}
+
+func (c *LFUCache[K, V]) Len() int {
+	return c.entries.Len()
+}
+
+// Range visits the entries in eviction order, from the least to the most
+// frequently used, without counting as uses. The cache must not be modified by fn.
+func (c *LFUCache[K, V]) Range(fn func(key K, value V) bool) {
+	for b := c.buckets.next; b != &c.buckets; b = b.next {
+		for e := b.entries.next; e != &b.entries; e = e.next {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// All is the range-over-func form of Range
+func (c *LFUCache[K, V]) All() iter.Seq2[K, V] {
+	return c.Range
+}
+
+func (c *LFUCache[K, V]) lookup(key K) *lfuEntry[K, V] {
+	if e := c.entries.Get(key); e != nil {
+		return *e
+	}
+	return nil
+}
+
+// touch moves e to the bucket for its count + 1
+func (c *LFUCache[K, V]) touch(e *lfuEntry[K, V]) {
+	from := e.bucket
+	to := from.next
+	if to == &c.buckets || to.count != from.count+1 {
+		to = c.insertBucketAfter(from, from.count+1)
+	}
+	c.unlink(e)
+	c.pushBack(to, e)
+}
+
+func (c *LFUCache[K, V]) insertBucketAfter(mark *lfuBucket[K, V], count int) *lfuBucket[K, V] {
+	b := &lfuBucket[K, V]{count: count, prev: mark, next: mark.next}
+	b.entries.prev, b.entries.next = &b.entries, &b.entries
+	mark.next.prev = b
+	mark.next = b
+	return b
+}
+
+func (c *LFUCache[K, V]) pushBack(b *lfuBucket[K, V], e *lfuEntry[K, V]) {
+	e.bucket = b
+	e.prev, e.next = b.entries.prev, &b.entries
+	b.entries.prev.next = e
+	b.entries.prev = e
+}
+
+// unlink takes e out of its bucket and drops the bucket once it's empty
+func (c *LFUCache[K, V]) unlink(e *lfuEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+	if b := e.bucket; b.entries.next == &b.entries {
+		b.prev.next = b.next
+		b.next.prev = b.prev
+	}
+	e.bucket = nil
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"iter"
+
+	"hashmaps/chainedmap"
+)
+
+// LRUCache holds up to a fixed number of entries and evicts the least
+// recently used one to make room. It is a chainedmap.LinkedHashMap in
+// access order: every Get and Set moves the entry to the back of the list,
+// so the entry to evict is always at the front.
+type LRUCache[K comparable, V any] struct {
+	entries  *chainedmap.LinkedHashMap[K, V]
+	capacity int
+	hooks    Hooks[K, V]
+}
+
+// MakeLRUCache takes WithHooks
+func MakeLRUCache[K comparable, V any](capacity int, opts ...Option) *LRUCache[K, V] {
+	hooks, _ := makeConfig[K, V](opts, false)
+	return &LRUCache[K, V]{
+		entries:  chainedmap.MakeLinkedHashMapWithAccessOrder[K, V](),
+		capacity: capacity,
+		hooks:    hooks,
+	}
+}
+
+// Get marks key as the most recently used
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+	if value := c.entries.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Peek is Get without changing the eviction order
+func (c *LRUCache[K, V]) Peek(key K) (V, bool) {
+	if value := c.entries.Peek(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set stores the value as the most recently used entry. When that goes over
+// the capacity the least recently used entry is evicted and returned,
+// ok is false when nothing was evicted.
+func (c *LRUCache[K, V]) Set(key K, value V) (evicted K, ok bool) {
+	if current := c.entries.Get(key); current != nil { // Get moves it to the back like Set would
+		old := *current
+		*current = value
+		c.hooks.update(key, old, value)
+		return evicted, false
+	}
+	c.entries.Set(key, value)
+	c.hooks.insert(key, value)
+	if c.entries.Len() <= c.capacity {
+		return evicted, false
+	}
+	evicted, evictedValue, _ := c.entries.Oldest()
+	c.entries.Remove(evicted)
+	c.hooks.evict(evicted, evictedValue, EvictedForSpace)
+	return evicted, true
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (c *LRUCache[K, V]) Remove(key K) {
+	c.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (c *LRUCache[K, V]) Delete(key K) (V, bool) {
+	value, ok := c.entries.Delete(key)
+	if ok {
+		c.hooks.evict(key, value, Removed)
+	}
+	return value, ok
+}
+
+func (c *LRUCache[K, V]) Len() int {
+	return c.entries.Len()
+}
+
+// Range visits the entries from the least to the most recently used,
+// without changing the order. The cache must not be modified by fn.
+func (c *LRUCache[K, V]) Range(fn func(key K, value V) bool) {
+	c.entries.Range(fn)
+}
+
+// All is the range-over-func form of Range
+func (c *LRUCache[K, V]) All() iter.Seq2[K, V] {
+	return c.Range
+}
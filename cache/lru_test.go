@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"slices"
+	"testing"
+)
+
+func lruKeys[K comparable, V any](c *LRUCache[K, V]) []K {
+	var keys []K
+	for key := range c.All() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestLRUEvictsLeastRecentlySet(t *testing.T) {
+	c := MakeLRUCache[int, string](3)
+	for i := 1; i <= 3; i++ {
+		if _, ok := c.Set(i, "v"); ok {
+			t.Fatalf("Set(%d) evicted below capacity", i)
+		}
+	}
+	for i := 4; i <= 6; i++ {
+		evicted, ok := c.Set(i, "v")
+		if !ok || evicted != i-3 {
+			t.Fatalf("Set(%d) evicted %d, %v, want %d", i, evicted, ok, i-3)
+		}
+	}
+	if got := lruKeys(c); !slices.Equal(got, []int{4, 5, 6}) {
+		t.Fatalf("entries %v, want [4 5 6]", got)
+	}
+}
+
+func TestLRUGetPromotes(t *testing.T) {
+	c := MakeLRUCache[int, string](3)
+	c.Set(1, "a")
+	c.Set(2, "b")
+	c.Set(3, "c")
+	c.Get(1) // 2 is the least recently used now
+	if evicted, _ := c.Set(4, "d"); evicted != 2 {
+		t.Fatalf("evicted %d, want 2", evicted)
+	}
+	if got := lruKeys(c); !slices.Equal(got, []int{3, 1, 4}) {
+		t.Fatalf("order %v, want [3 1 4]", got)
+	}
+}
+
+func TestLRUSetOfExistingKeyPromotes(t *testing.T) {
+	c := MakeLRUCache[int, string](2)
+	c.Set(1, "a")
+	c.Set(2, "b")
+	if _, ok := c.Set(1, "A"); ok {
+		t.Fatal("updating a key evicted")
+	}
+	if evicted, _ := c.Set(3, "c"); evicted != 2 {
+		t.Fatalf("evicted %d, want 2", evicted)
+	}
+	if value, ok := c.Get(1); !ok || value != "A" {
+		t.Fatalf("Get(1) = %q, %v, want \"A\", true", value, ok)
+	}
+}
+
+func TestLRUPeekDoesNotPromote(t *testing.T) {
+	c := MakeLRUCache[int, string](2)
+	c.Set(1, "a")
+	c.Set(2, "b")
+	if value, ok := c.Peek(1); !ok || value != "a" {
+		t.Fatalf("Peek(1) = %q, %v", value, ok)
+	}
+	if evicted, _ := c.Set(3, "c"); evicted != 1 {
+		t.Fatalf("evicted %d, want 1, Peek must not change the order", evicted)
+	}
+}
+
+func TestLRUDeleteFreesRoom(t *testing.T) {
+	c := MakeLRUCache[int, string](2)
+	c.Set(1, "a")
+	c.Set(2, "b")
+	if value, ok := c.Delete(1); !ok || value != "a" {
+		t.Fatalf("Delete(1) = %q, %v", value, ok)
+	}
+	if _, ok := c.Set(3, "c"); ok {
+		t.Fatal("Set evicted although Delete made room")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestLRUHooks(t *testing.T) {
+	var events []string
+	c := MakeLRUCache[int, string](1, WithHooks(Hooks[int, string]{
+		OnInsert: func(key int, value string) { events = append(events, "insert "+value) },
+		OnUpdate: func(key int, old, new string) { events = append(events, "update "+old+" "+new) },
+		OnEvict: func(key int, value string, reason EvictReason) {
+			events = append(events, "evict "+value+" "+reason.String())
+		},
+	}))
+	c.Set(1, "a")
+	c.Set(1, "b")
+	c.Set(2, "c")
+	c.Delete(2)
+	want := []string{"insert a", "update a b", "insert c", "evict b evicted for space", "evict c removed"}
+	if !slices.Equal(events, want) {
+		t.Fatalf("events %q, want %q", events, want)
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrHooksType   = errors.New("hooks are for another key or value type")
+	ErrClockUnused = errors.New("only TTLCache reads a clock")
+)
+
+// Option configures a cache made by one of the Make functions, options
+// combine freely, e.g.
+//
+//	c := cache.MakeTTLCache[string, int](time.Minute, cache.WithClock(clock.Now), cache.WithHooks(hooks))
+//
+// The Make functions panic when an option doesn't fit the cache.
+type Option func(*config)
+
+type config struct {
+	now   func() time.Time // nil means time.Now
+	hooks any              // a Hooks[K, V], checked against K and V by makeConfig
+}
+
+// WithClock replaces time.Now for a TTLCache, e.g. with a fake clock in tests
+func WithClock(now func() time.Time) Option {
+	return func(c *config) { c.now = now }
+}
+
+// WithHooks sets the hooks called on every change. The key and value types
+// of hooks have to be those of the cache.
+func WithHooks[K comparable, V any](hooks Hooks[K, V]) Option {
+	return func(c *config) { c.hooks = hooks }
+}
+
+// makeConfig applies opts and returns the hooks they set and the clock,
+// time.Now unless WithClock is among them. readsClock is false for the
+// caches that never look at the time.
+func makeConfig[K comparable, V any](opts []Option, readsClock bool) (Hooks[K, V], func() time.Time) {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	var hooks Hooks[K, V]
+	if c.hooks != nil {
+		var ok bool
+		if hooks, ok = c.hooks.(Hooks[K, V]); !ok {
+			panic(fmt.Errorf("%w: %T", ErrHooksType, c.hooks))
+		}
+	}
+	if c.now == nil {
+		return hooks, time.Now
+	}
+	if !readsClock {
+		panic(ErrClockUnused)
+	}
+	return hooks, c.now
+}
@@ -0,0 +1,137 @@
+// Package cache contains bounded maps that evict entries on their own.
+package cache
+
+import (
+	"iter"
+
+	"hashmaps/chainedmap"
+	"hashmaps/heap"
+)
+
+// evictionRank orders entries for eviction: lowest priority first, then the
+// least recently written
+type evictionRank struct {
+	priority int
+	seq      uint64
+}
+
+func lowerRank(a, b evictionRank) bool {
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	return a.seq < b.seq
+}
+
+type priorityEntry[V any] struct {
+	value V
+	cost  int64
+}
+
+// PriorityMap holds entries up to a total cost. When a write pushes it over
+// the bound, entries with the lowest priority are evicted first, and among
+// equal priorities the one written longest ago, e.g. must-keep data gets
+// a high priority and best effort data a low one.
+type PriorityMap[K comparable, V any] struct {
+	entries   *chainedmap.HashMap[K, priorityEntry[V]]
+	ranks     *heap.IndexedPriorityQueue[K, evictionRank]
+	cost      func(key K, value V) int64
+	maxCost   int64
+	totalCost int64
+	seq       uint64
+	hooks     Hooks[K, V]
+}
+
+// MakePriorityMap bounds the number of entries. It takes WithHooks.
+func MakePriorityMap[K comparable, V any](maxEntries int, opts ...Option) *PriorityMap[K, V] {
+	return MakePriorityMapWithCost[K, V](int64(maxEntries), func(K, V) int64 { return 1 }, opts...)
+}
+
+// MakePriorityMapWithCost bounds the sum of cost over all entries, e.g. their size in bytes
+func MakePriorityMapWithCost[K comparable, V any](maxCost int64, cost func(key K, value V) int64, opts ...Option) *PriorityMap[K, V] {
+	hooks, _ := makeConfig[K, V](opts, false)
+	return &PriorityMap[K, V]{
+		entries: chainedmap.MakeHashMap[K, priorityEntry[V]](),
+		ranks:   makeQueue[K, evictionRank](lowerRank),
+		cost:    cost,
+		maxCost: maxCost,
+		hooks:   hooks,
+	}
+}
+
+func (m *PriorityMap[K, V]) Len() int {
+	return m.entries.Len()
+}
+
+// Cost is the sum of cost over all entries
+func (m *PriorityMap[K, V]) Cost() int64 {
+	return m.totalCost
+}
+
+// Set stores the value with the given priority and returns the keys evicted to make room,
+// which can include key itself when everything else outranks it
+func (m *PriorityMap[K, V]) Set(key K, value V, priority int) []K {
+	m.seq++
+	cost := m.cost(key, value)
+	m.totalCost += cost
+	m.ranks.Push(key, evictionRank{priority: priority, seq: m.seq})
+	if existing := m.entries.Get(key); existing != nil {
+		m.totalCost -= existing.cost
+		old := existing.value
+		existing.value, existing.cost = value, cost
+		m.hooks.update(key, old, value)
+	} else {
+		m.entries.Set(key, priorityEntry[V]{value: value, cost: cost})
+		m.hooks.insert(key, value)
+	}
+
+	var evicted []K
+	for m.totalCost > m.maxCost {
+		victim, _, ok := m.ranks.Pop()
+		if !ok {
+			break
+		}
+		entry, _ := m.entries.Delete(victim)
+		m.totalCost -= entry.cost
+		evicted = append(evicted, victim)
+		m.hooks.evict(victim, entry.value, EvictedForSpace)
+	}
+	return evicted
+}
+
+// Get does not change the eviction order
+func (m *PriorityMap[K, V]) Get(key K) (V, bool) {
+	if entry := m.entries.Get(key); entry != nil {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *PriorityMap[K, V]) Priority(key K) (int, bool) {
+	rank, ok := m.ranks.Priority(key)
+	return rank.priority, ok
+}
+
+func (m *PriorityMap[K, V]) Delete(key K) bool {
+	entry, ok := m.entries.Delete(key)
+	if !ok {
+		return false
+	}
+	m.totalCost -= entry.cost
+	m.ranks.Remove(key)
+	m.hooks.evict(key, entry.value, Removed)
+	return true
+}
+
+// Range visits the entries in no particular order, without changing the
+// eviction order. The map must not be modified by fn.
+func (m *PriorityMap[K, V]) Range(fn func(key K, value V) bool) {
+	m.entries.Range(func(key K, entry priorityEntry[V]) bool {
+		return fn(key, entry.value)
+	})
+}
+
+// All is the range-over-func form of Range
+func (m *PriorityMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPriorityMapEvictsLowestPriorityFirst(t *testing.T) {
+	m := MakePriorityMap[string, int](3)
+	m.Set("keep", 1, 10)
+	m.Set("old", 2, 1)
+	m.Set("new", 3, 1)
+	if evicted := m.Set("mid", 4, 5); !slices.Equal(evicted, []string{"old"}) {
+		t.Fatalf("evicted %v, want the oldest of the lowest priority", evicted)
+	}
+	if evicted := m.Set("low", 5, 0); !slices.Equal(evicted, []string{"low"}) {
+		t.Fatalf("evicted %v, want the new entry everything else outranks", evicted)
+	}
+	if _, ok := m.Get("low"); ok || m.Len() != 3 {
+		t.Fatalf("Len() = %d, Get(low) found = %v", m.Len(), ok)
+	}
+	if priority, _ := m.Priority("mid"); priority != 5 {
+		t.Fatalf("Priority(mid) = %d, want 5", priority)
+	}
+	if !m.Delete("keep") || m.Delete("keep") {
+		t.Fatalf("Delete(keep) twice must be true, then false")
+	}
+}
+
+func TestPriorityMapCost(t *testing.T) {
+	m := MakePriorityMapWithCost[string, string](10, func(_, value string) int64 { return int64(len(value)) })
+	m.Set("a", "aaaa", 1)
+	m.Set("b", "bbbb", 2)
+	m.Set("a", "aa", 1) // replacing a value replaces its cost
+	if m.Cost() != 6 {
+		t.Fatalf("Cost() = %d, want 6", m.Cost())
+	}
+	if evicted := m.Set("c", "cccccc", 3); !slices.Equal(evicted, []string{"a"}) || m.Cost() != 10 {
+		t.Fatalf("evicted %v with cost %d, want [a] and 10", evicted, m.Cost())
+	}
+}
+
+func TestPriorityMapHooks(t *testing.T) {
+	var events []string
+	m := MakePriorityMap[string, string](2, WithHooks(Hooks[string, string]{
+		OnInsert: func(key, value string) { events = append(events, "insert "+key+"="+value) },
+		OnUpdate: func(key, old, new string) { events = append(events, "update "+key+"="+old+"->"+new) },
+		OnEvict: func(key, value string, reason EvictReason) {
+			events = append(events, "evict "+key+"="+value+" "+reason.String())
+		},
+	}))
+	m.Set("a", "1", 1)
+	m.Set("b", "2", 2)
+	m.Set("a", "3", 3)
+	m.Set("c", "4", 0)
+	m.Delete("b")
+	m.Delete("missing")
+	want := []string{
+		"insert a=1", "insert b=2", "update a=1->3",
+		"insert c=4", "evict c=4 evicted for space",
+		"evict b=2 removed",
+	}
+	if !slices.Equal(events, want) {
+		t.Fatalf("events %q, want %q", events, want)
+	}
+}
+
+func TestOptionsThatDontFit(t *testing.T) {
+	for name, construct := range map[string]func(){
+		"hooks for another value type": func() { MakeLRUCache[string, int](1, WithHooks(Hooks[string, string]{})) },
+		"clock for a PriorityMap":      func() { MakePriorityMap[string, int](1, WithClock(time.Now)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: no panic", name)
+				}
+			}()
+			construct()
+		}()
+	}
+}
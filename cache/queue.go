@@ -0,0 +1,13 @@
+package cache
+
+import (
+	"hashmaps/chainedmap"
+	"hashmaps/heap"
+)
+
+// makeQueue is a heap.IndexedPriorityQueue that finds its keys through a
+// chainedmap.HashMap, like the caches find their entries
+func makeQueue[K comparable, P any](less func(a, b P) bool) *heap.IndexedPriorityQueue[K, P] {
+	index := chainedmap.MakeHashMap[K, *heap.Handle[heap.Prioritized[K, P]]]()
+	return heap.MakeIndexedPriorityQueueWithIndex[K, P](less, index)
+}
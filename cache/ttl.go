@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"iter"
+	"sync"
+	"time"
+
+	"hashmaps/chainedmap"
+	"hashmaps/heap"
+)
+
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt int64 // in nanoseconds, 0 means never
+}
+
+// TTLCache forgets entries a while after they were set. Every entry gets the
+// default ttl unless SetWithTTL gives it its own, so expiration order is not
+// insertion order; a min-heap on the expiration time keeps the next entry to
+// expire at the top. Expired entries are dropped by the next call that
+// looks at the cache, and in the background by the janitor if it runs.
+// It is safe for concurrent use.
+type TTLCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	entries  *chainedmap.HashMap[K, ttlEntry[V]]
+	expiries *heap.IndexedPriorityQueue[K, int64] // keys that expire, by expiration time
+	ttl      time.Duration
+	now      func() time.Time
+	hooks    Hooks[K, V]
+	stop     chan struct{} // closed by Stop, nil while no janitor runs
+}
+
+// MakeTTLCache gives every entry the same default ttl, 0 means entries
+// don't expire. It takes WithClock and WithHooks; OnEvict learns about
+// entries whose ttl ran out as Expired, from whichever call dropped them,
+// the janitor's goroutine included.
+func MakeTTLCache[K comparable, V any](ttl time.Duration, opts ...Option) *TTLCache[K, V] {
+	hooks, now := makeConfig[K, V](opts, true)
+	return &TTLCache[K, V]{
+		entries:  chainedmap.MakeHashMap[K, ttlEntry[V]](),
+		expiries: makeQueue[K, int64](func(a, b int64) bool { return a < b }),
+		ttl:      ttl,
+		now:      now,
+		hooks:    hooks,
+	}
+}
+
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(c.now().UnixNano())
+	if entry := c.entries.Get(key); entry != nil {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// TTL returns how long key has left, 0 when it doesn't expire.
+// ok is false when key isn't there.
+func (c *TTLCache[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now().UnixNano()
+	c.expire(now)
+	entry := c.entries.Get(key)
+	if entry == nil || entry.expiresAt == 0 {
+		return 0, entry != nil
+	}
+	return time.Duration(entry.expiresAt - now), true
+}
+
+// Set stores the value with the default ttl, replacing the ttl key had before
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores the value with its own ttl, 0 or less means it doesn't expire
+func (c *TTLCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now().UnixNano()
+	c.expire(now)
+	entry := ttlEntry[V]{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now + int64(ttl)
+		c.expiries.Push(key, entry.expiresAt)
+	} else {
+		c.expiries.Remove(key)
+	}
+	if old := c.entries.Get(key); old != nil {
+		oldValue := old.value
+		*old = entry
+		c.hooks.update(key, oldValue, value)
+	} else {
+		c.entries.Set(key, entry)
+		c.hooks.insert(key, value)
+	}
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (c *TTLCache[K, V]) Remove(key K) {
+	c.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (c *TTLCache[K, V]) Delete(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(c.now().UnixNano())
+	entry, ok := c.entries.Delete(key)
+	if ok {
+		c.expiries.Remove(key)
+		c.hooks.evict(key, entry.value, Removed)
+	}
+	return entry.value, ok
+}
+
+// Len counts the entries that haven't expired
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(c.now().UnixNano())
+	return c.entries.Len()
+}
+
+// Range visits the entries that haven't expired, in no particular order.
+// It holds the lock for the whole iteration, so fn must not call methods of c.
+func (c *TTLCache[K, V]) Range(fn func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(c.now().UnixNano())
+	c.entries.Range(func(key K, entry ttlEntry[V]) bool {
+		return fn(key, entry.value)
+	})
+}
+
+// All is the range-over-func form of Range
+func (c *TTLCache[K, V]) All() iter.Seq2[K, V] {
+	return c.Range
+}
+
+// StartJanitor drops expired entries every interval in a background goroutine,
+// so memory is given back even for keys that are never looked at again.
+// It does nothing when the janitor already runs. Stop ends it.
+func (c *TTLCache[K, V]) StartJanitor(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	go c.janitor(interval, c.stop)
+}
+
+// Stop ends the janitor, if there is one. The cache keeps working.
+func (c *TTLCache[K, V]) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+func (c *TTLCache[K, V]) janitor(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			c.expire(c.now().UnixNano())
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (c *TTLCache[K, V]) expire(now int64) {
+	for {
+		key, expiresAt, ok := c.expiries.Peek()
+		if !ok || expiresAt > now {
+			return
+		}
+		c.expiries.Pop()
+		entry, _ := c.entries.Delete(key)
+		c.hooks.evict(key, entry.value, Expired)
+	}
+}
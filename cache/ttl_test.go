@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestTTLExpires(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	var expired []int
+	c := MakeTTLCache[int, string](time.Minute, WithClock(clock.Now), WithHooks(Hooks[int, string]{
+		OnEvict: func(key int, _ string, reason EvictReason) {
+			if reason == Expired {
+				expired = append(expired, key)
+			}
+		},
+	}))
+	c.Set(1, "a")
+	c.SetWithTTL(2, "b", 2*time.Minute)
+	c.SetWithTTL(3, "c", 0) // never expires
+	c.SetWithTTL(4, "d", 30*time.Second)
+	if ttl, ok := c.TTL(2); !ok || ttl != 2*time.Minute {
+		t.Fatalf("TTL(2) = %v, %v, want 2m, true", ttl, ok)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if _, ok := c.Get(1); ok {
+		t.Fatalf("Get(1) found the entry after its ttl")
+	}
+	if value, ok := c.Get(2); !ok || value != "b" {
+		t.Fatalf("Get(2) = %q, %v before its ttl ran out", value, ok)
+	}
+	if !slices.Equal(expired, []int{4, 1}) {
+		t.Fatalf("expired %v, want [4 1] in expiration order", expired)
+	}
+	c.Set(2, "b2") // a new ttl from now
+	clock.now = clock.now.Add(59 * time.Second)
+	if c.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 and 3", c.Len())
+	}
+	clock.now = clock.now.Add(time.Hour)
+	if c.Len() != 1 {
+		t.Fatalf("Len() = %d, want only the entry without ttl", c.Len())
+	}
+	if ttl, ok := c.TTL(3); !ok || ttl != 0 {
+		t.Fatalf("TTL(3) = %v, %v, want 0, true", ttl, ok)
+	}
+	if value, ok := c.Delete(3); !ok || value != "c" || c.Len() != 0 {
+		t.Fatalf("Delete(3) = %q, %v with %d left", value, ok, c.Len())
+	}
+}
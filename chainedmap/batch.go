@@ -0,0 +1,45 @@
+package chainedmap
+
+// GetMany looks up all keys and returns their values in the same order,
+// nil for missing keys. The work is split into passes: hash every key,
+// load every bucket head, then walk the chains. The loads of the second pass
+// don't depend on each other, so the CPU can have many cache misses in
+// flight at once instead of stalling on one lookup at a time.
+// Go has no portable prefetch instruction, the head loads play that role.
+func (m *HashMap[K, V]) GetMany(keys []K) ([]*V, error) {
+	values := make([]*V, len(keys))
+	normalized := make([]K, len(keys))
+	valid := make([]bool, len(keys))
+	bucketIndexes := make([]int, len(keys))
+	for i, key := range keys {
+		key, err := m.norm.Normalize(key)
+		if err != nil { // rejected keys are never stored
+			continue
+		}
+		hashedKey, err := m.tryHash(key)
+		if err != nil {
+			return nil, err
+		}
+		normalized[i] = key
+		valid[i] = true
+		bucketIndexes[i] = hashedKey
+	}
+
+	heads := make([]*KVPair[K, V], len(keys))
+	for i := range keys {
+		if valid[i] {
+			m.buckets.own(bucketIndexes[i]) // the caller may write through the pointers
+			heads[i] = m.buckets.head(bucketIndexes[i])
+		}
+	}
+
+	for i, head := range heads {
+		for pointer := head; pointer != nil; pointer = pointer.Next {
+			if m.norm.Equal(pointer.Key, normalized[i]) {
+				values[i] = &pointer.Value
+				break
+			}
+		}
+	}
+	return values, nil
+}
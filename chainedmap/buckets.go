@@ -0,0 +1,75 @@
+package chainedmap
+
+import "slices"
+
+// The bucket array is split into segments of at most segmentSize buckets,
+// so a huge map never needs one contiguous multi-gigabyte allocation and
+// the old and new tables don't have to fit next to each other in one piece.
+// Bucket i lives in segment i >> segmentBits, i.e. the high bits of the
+// bucket index pick the segment.
+const (
+	segmentBits = 16
+	segmentSize = 1 << segmentBits
+	segmentMask = segmentSize - 1
+)
+
+type bucketTable[K comparable, V any] struct {
+	segments [][]*KVPair[K, V]
+	length   int
+	shared   *sharedBuckets // non-nil while a ReadOnlyView may see the segments and nodes, see cow.go
+}
+
+func makeBucketTable[K comparable, V any](capacity int) bucketTable[K, V] {
+	table := bucketTable[K, V]{length: capacity}
+	for remaining := capacity; remaining > 0; remaining -= segmentSize {
+		size := remaining
+		if size > segmentSize {
+			size = segmentSize
+		}
+		table.segments = append(table.segments, make([]*KVPair[K, V], size))
+	}
+	return table
+}
+
+func (t *bucketTable[K, V]) len() int {
+	return t.length
+}
+
+// head returns the first pair in bucket i
+func (t *bucketTable[K, V]) head(i int) *KVPair[K, V] {
+	return t.segments[i>>segmentBits][i&segmentMask]
+}
+
+func (t *bucketTable[K, V]) setHead(i int, pair *KVPair[K, V]) {
+	t.segments[i>>segmentBits][i&segmentMask] = pair
+}
+
+// own makes bucket i safe to modify: while the table is shared, the first
+// write to a segment copies it and the first write to a bucket copies its
+// chain, so a ReadOnlyView keeps the nodes it saw
+func (t *bucketTable[K, V]) own(i int) {
+	if t.shared == nil {
+		return
+	}
+	s, j := i>>segmentBits, i&segmentMask
+	owned := t.shared.owned[s]
+	if owned == nil {
+		t.segments[s] = slices.Clone(t.segments[s])
+		owned = make([]uint64, (len(t.segments[s])+63)/64)
+		t.shared.owned[s] = owned
+	}
+	if owned[j/64]&(1<<(j%64)) != 0 {
+		return
+	}
+	owned[j/64] |= 1 << (j % 64)
+	var last *KVPair[K, V]
+	for pair := t.segments[s][j]; pair != nil; pair = pair.Next {
+		copied := &KVPair[K, V]{Key: pair.Key, Value: pair.Value}
+		if last == nil {
+			t.segments[s][j] = copied
+		} else {
+			last.Next = copied
+		}
+		last = copied
+	}
+}
@@ -0,0 +1,19 @@
+package chainedmap
+
+import (
+	"bytes"
+
+	"hashmaps/internal/hashing"
+)
+
+// MakeBytesKeyMap makes a map keyed by the content of byte slices, with no
+// string conversion on lookups. Set copies a key the first time it's stored,
+// so the caller may reuse or modify its slice afterwards; Get and Delete
+// don't copy. Keys yielded by Range and All are the map's own copies and
+// must not be modified.
+func MakeBytesKeyMap[V any]() *FuncHashMap[[]byte, V] {
+	seed := hashing.MakeSeed[[]byte]()
+	m := MakeHashMapFunc[[]byte, V](seed.Bytes, bytes.Equal)
+	m.clone = bytes.Clone
+	return m
+}
@@ -0,0 +1,58 @@
+package chainedmap
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytesKeyMapCopiesStoredKeys(t *testing.T) {
+	m := MakeBytesKeyMap[int]()
+	key := []byte("abc")
+	m.Set(key, 1)
+	key[0] = 'x' // the caller reuses its slice
+	if value := m.Get([]byte("abc")); value == nil || *value != 1 {
+		t.Fatalf("Get(abc) = %v after modifying the set slice, want &1", value)
+	}
+	if value := m.Get(key); value != nil {
+		t.Fatalf("Get(xbc) = &%d, the map must not alias the caller's slice", *value)
+	}
+	for stored := range m.Keys() {
+		if !bytes.Equal(stored, []byte("abc")) {
+			t.Fatalf("stored key %q, want %q", stored, "abc")
+		}
+	}
+}
+
+func TestBytesKeyMapUpdateKeepsFirstKey(t *testing.T) {
+	m := MakeBytesKeyMap[int]()
+	first := []byte("k")
+	m.Set(first, 1)
+	second := []byte("k")
+	m.Set(second, 2)
+	second[0] = 'z'
+	if value := m.Get([]byte("k")); value == nil || *value != 2 || m.Len() != 1 {
+		t.Fatalf("Get(k) = %v, Len() = %d, want &2, 1", value, m.Len())
+	}
+}
+
+func TestBytesKeyMapComparesContent(t *testing.T) {
+	m := MakeBytesKeyMap[string]()
+	m.Set([]byte{}, "empty")
+	m.Set(nil, "nil") // same content as the empty slice
+	m.Set([]byte("a\x00"), "a0")
+	m.Set([]byte("a"), "a")
+	if m.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3, nil and empty are one key", m.Len())
+	}
+	if value := m.Get([]byte{}); value == nil || *value != "nil" {
+		t.Fatalf("Get(empty) = %v, want &\"nil\"", value)
+	}
+	// a lookup with a slice of a larger buffer finds the key too
+	buffer := []byte("xa\x00y")
+	if value := m.Get(buffer[1:3]); value == nil || *value != "a0" {
+		t.Fatalf("Get(a\\x00) = %v, want &\"a0\"", value)
+	}
+	if _, ok := m.Delete(buffer[1:2]); !ok || m.Get([]byte("a")) != nil {
+		t.Fatal("Delete with a subslice didn't remove the key")
+	}
+}
@@ -0,0 +1,40 @@
+package chainedmap
+
+// CompareAndSwap stores new when the value of key is old, compared with
+// the map's Comparer. Unlike sync.Map.CompareAndSwap it doesn't panic
+// when V holds values that aren't comparable, see DefaultComparer.
+func (m *HashMap[K, V]) CompareAndSwap(key K, old, new V) bool {
+	return m.CompareAndSwapFunc(key, old, new, nil)
+}
+
+// CompareAndSwapFunc is CompareAndSwap comparing with equal, nil means the
+// map's Comparer
+func (m *HashMap[K, V]) CompareAndSwapFunc(key K, old, new V, equal func(a, b V) bool) bool {
+	equal = m.valuesEqual(equal)
+	if value := m.Get(key); value != nil && equal(*value, old) {
+		*value = new
+		return true
+	}
+	return false
+}
+
+// CompareAndDelete deletes key when its value is old, comparing like
+// CompareAndSwap
+func (m *HashMap[K, V]) CompareAndDelete(key K, old V) bool {
+	return m.CompareAndDeleteFunc(key, old, nil)
+}
+
+// CompareAndDeleteFunc is CompareAndDelete comparing with equal, nil means
+// the map's Comparer. Like Delete it panics when the key can't be hashed.
+func (m *HashMap[K, V]) CompareAndDeleteFunc(key K, old V, equal func(a, b V) bool) bool {
+	equal = m.valuesEqual(equal)
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return false
+	}
+	_, ok := m.removeAt(m.hash(key), key, func(value V) bool { return equal(value, old) })
+	if ok {
+		m.shrinkIfNeeded()
+	}
+	return ok
+}
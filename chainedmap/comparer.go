@@ -0,0 +1,77 @@
+package chainedmap
+
+import "reflect"
+
+// Comparer reports whether two values are equal. Equal, CompareAndSwap,
+// CompareAndDelete and their Func forms given a nil func compare values
+// with the map's Comparer, DefaultComparer unless made WithComparer.
+type Comparer[V any] func(a, b V) bool
+
+// DefaultComparer compares with == when V is comparable and with
+// reflect.DeepEqual when it isn't, e.g. for slices and maps. Interface
+// values holding something that isn't comparable are compared with
+// reflect.DeepEqual too, where == would panic.
+func DefaultComparer[V any]() Comparer[V] {
+	t := reflect.TypeFor[V]()
+	switch {
+	case !t.Comparable():
+		return deepEqual[V]
+	case holdsInterface(t):
+		return equalOrDeepEqual[V]
+	}
+	return equalValues[V]
+}
+
+// WithComparer sets the Comparer of the map, its value type has to be the
+// value type of the map
+func WithComparer[V any](comparer Comparer[V]) Option {
+	return func(c *config) { c.comparer = comparer }
+}
+
+func equalValues[V any](a, b V) bool {
+	return any(a) == any(b)
+}
+
+func deepEqual[V any](a, b V) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// equalOrDeepEqual is for comparable types with interfaces in them, only
+// the dynamic values tell whether == panics
+func equalOrDeepEqual[V any](a, b V) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = reflect.DeepEqual(a, b)
+		}
+	}()
+	return any(a) == any(b)
+}
+
+func holdsInterface(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Array:
+		return holdsInterface(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if holdsInterface(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// valuesEqual compares with eq, or with the map's Comparer when eq is nil.
+// A map that was never made, e.g. one decoded into a zero HashMap, has none
+// and uses DefaultComparer.
+func (m *HashMap[K, V]) valuesEqual(eq func(a, b V) bool) func(a, b V) bool {
+	switch {
+	case eq != nil:
+		return eq
+	case m.comparer != nil:
+		return m.comparer
+	}
+	return DefaultComparer[V]()
+}
@@ -0,0 +1,81 @@
+package chainedmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultComparer(t *testing.T) {
+	if !DefaultComparer[int]()(1, 1) || DefaultComparer[int]()(1, 2) {
+		t.Fatal("int values compared wrong")
+	}
+	if !DefaultComparer[[]int]()([]int{1, 2}, []int{1, 2}) {
+		t.Fatal("equal slices compared unequal")
+	}
+	// == would panic on the slices in the interfaces
+	if !DefaultComparer[any]()([]int{1}, []int{1}) || DefaultComparer[any]()([]int{1}, []int{2}) {
+		t.Fatal("slices in interfaces compared wrong")
+	}
+	type holder struct{ v any }
+	if !DefaultComparer[holder]()(holder{map[int]int{1: 1}}, holder{map[int]int{1: 1}}) {
+		t.Fatal("maps in struct fields compared unequal")
+	}
+	// pointers are compared by address, unlike with reflect.DeepEqual
+	a, b := 1, 1
+	if DefaultComparer[*int]()(&a, &b) {
+		t.Fatal("distinct pointers compared equal")
+	}
+}
+
+func TestCompareAndSwapSliceValues(t *testing.T) {
+	m := MakeHashMap[string, []int]()
+	m.Set("a", []int{1, 2})
+	if m.CompareAndSwap("a", []int{1, 3}, []int{9}) {
+		t.Fatal("CompareAndSwap swapped a different slice")
+	}
+	if !m.CompareAndSwap("a", []int{1, 2}, []int{3}) {
+		t.Fatal("CompareAndSwap didn't swap an equal slice")
+	}
+	if !m.CompareAndDelete("a", []int{3}) || m.Len() != 0 {
+		t.Fatal("CompareAndDelete didn't delete an equal slice")
+	}
+}
+
+func TestWithComparer(t *testing.T) {
+	foldCase := Comparer[string](strings.EqualFold)
+	m := MakeHashMap[int, string](WithComparer(foldCase))
+	other := MakeHashMap[int, string]()
+	m.Set(1, "Go")
+	other.Set(1, "GO")
+	if !m.Equal(other, nil) {
+		t.Fatal("Equal didn't use the map's Comparer")
+	}
+	if other.Equal(m, nil) {
+		t.Fatal("Equal with the default Comparer ignored case")
+	}
+	if !m.CompareAndSwapFunc(1, "go", "rust", nil) {
+		t.Fatal("CompareAndSwapFunc(nil) didn't use the map's Comparer")
+	}
+	if !m.Filter(func(int, string) bool { return true }).CompareAndDelete(1, "RUST") {
+		t.Fatal("Filter dropped the Comparer")
+	}
+}
+
+func TestComparerType(t *testing.T) {
+	_, err := TryMakeHashMap[int, int](WithComparer(Comparer[string](strings.EqualFold)))
+	if !errors.Is(err, ErrComparerType) {
+		t.Fatalf("TryMakeHashMap error = %v, want ErrComparerType", err)
+	}
+}
+
+func TestSyncMapCompareAndSwapSliceValues(t *testing.T) {
+	m := MakeSyncMap[int, []string]()
+	m.Store(1, []string{"a"})
+	if !m.CompareAndSwap(1, []string{"a"}, []string{"b"}) {
+		t.Fatal("CompareAndSwap didn't swap an equal slice")
+	}
+	if !m.CompareAndDelete(1, []string{"b"}) {
+		t.Fatal("CompareAndDelete didn't delete an equal slice")
+	}
+}
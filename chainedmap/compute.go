@@ -0,0 +1,47 @@
+package chainedmap
+
+// GetOrCompute returns the value of key, or calls compute, stores its result
+// and returns it when key is missing. The chain is walked only once.
+// compute must not modify the map. Like Set, it panics when the key is
+// rejected by the NaNPolicy or can't be hashed.
+func (m *HashMap[K, V]) GetOrCompute(key K, compute func() V) V {
+	value, _ := m.getOrInsert(key, compute)
+	return value
+}
+
+// getOrInsert reports whether the value was already there (loaded)
+func (m *HashMap[K, V]) getOrInsert(key K, compute func() V) (V, bool) {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		panic(err)
+	}
+	hashedKey := m.hash(key)
+	m.buckets.own(hashedKey)
+	var last *KVPair[K, V]
+	for pointer := m.buckets.head(hashedKey); pointer != nil; pointer = pointer.Next {
+		if m.norm.Equal(pointer.Key, key) {
+			return pointer.Value, true
+		}
+		last = pointer
+	}
+	value := compute()
+	m.appendPair(hashedKey, last, &KVPair[K, V]{Key: key, Value: value})
+	return value, false
+}
+
+// Upsert stores value when key is missing and merge(existing, value)
+// otherwise, walking the chain once, and returns what it stored:
+//
+//	m.Upsert(word, 1, func(old, new int) int { return old + new })
+//
+// merge must not modify the map. Like Set, it panics when the key is
+// rejected by the NaNPolicy or can't be hashed.
+func (m *HashMap[K, V]) Upsert(key K, value V, merge func(old, new V) V) V {
+	e := m.Entry(key)
+	if e.pair != nil {
+		e.pair.Value = merge(e.pair.Value, value)
+		return e.pair.Value
+	}
+	e.insert(value)
+	return value
+}
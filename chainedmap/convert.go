@@ -0,0 +1,22 @@
+package chainedmap
+
+// FromMap copies a built-in map. The table is sized for len(src) up front,
+// so the bulk load doesn't grow it over and over.
+func FromMap[K comparable, V any](src map[K]V) *HashMap[K, V] {
+	m := MakeHashMap[K, V]()
+	m.reserve(len(src))
+	for key, value := range src {
+		m.Set(key, value)
+	}
+	return m
+}
+
+// ToMap copies the entries into a built-in map
+func (m *HashMap[K, V]) ToMap() map[K]V {
+	dst := make(map[K]V, m.length)
+	m.Range(func(key K, value V) bool {
+		dst[key] = value
+		return true
+	})
+	return dst
+}
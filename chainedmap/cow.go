@@ -0,0 +1,70 @@
+package chainedmap
+
+import (
+	"iter"
+	"slices"
+)
+
+// sharedBuckets tracks which parts of a table the map copied since the
+// last Snapshot: owned[s] is nil until segment s is copied, then it has a
+// bit per bucket whose chain was copied too
+type sharedBuckets struct {
+	owned [][]uint64
+}
+
+// ReadOnlyView is the content of a HashMap at the time of Snapshot.
+// It shares buckets and nodes with the map, the map copies them before
+// modifying them, so writes to the map never show up in the view.
+// A view never changes and may be read from other goroutines while one
+// goroutine keeps writing to the map.
+type ReadOnlyView[K comparable, V any] struct {
+	m HashMap[K, V] // never written to, its table isn't marked as shared
+}
+
+// Snapshot returns a view of the current entries in O(1), the segment list
+// aside. The price is paid by the map afterwards: the first write to a
+// segment of up to 65536 buckets copies that segment, the first write to
+// a bucket copies its chain, and the next resize copies every node instead
+// of moving it. Get and GetMany count as writes, the pointers they return
+// can be written through. Taking another Snapshot shares everything again.
+func (m *HashMap[K, V]) Snapshot() *ReadOnlyView[K, V] {
+	view := &ReadOnlyView[K, V]{m: *m}
+	view.m.buckets.segments = slices.Clone(m.buckets.segments)
+	view.m.buckets.shared = nil
+	m.buckets.shared = &sharedBuckets{owned: make([][]uint64, len(m.buckets.segments))}
+	return view
+}
+
+// Get returns a copy of the value, ok is false when key wasn't there.
+// Like HashMap.Get it panics when the key can't be hashed.
+func (v *ReadOnlyView[K, V]) Get(key K) (V, bool) {
+	if value := v.m.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Len returns the number of entries, in O(1)
+func (v *ReadOnlyView[K, V]) Len() int {
+	return v.m.Len()
+}
+
+// Range calls fn for every entry until fn returns false. Unlike
+// HashMap.Range, fn may modify the map the view was taken from.
+func (v *ReadOnlyView[K, V]) Range(fn func(key K, value V) bool) {
+	v.m.Range(fn)
+}
+
+// All, Keys and Values are the range-over-func forms of Range
+func (v *ReadOnlyView[K, V]) All() iter.Seq2[K, V] {
+	return v.m.All()
+}
+
+func (v *ReadOnlyView[K, V]) Keys() iter.Seq[K] {
+	return v.m.Keys()
+}
+
+func (v *ReadOnlyView[K, V]) Values() iter.Seq[V] {
+	return v.m.Values()
+}
@@ -0,0 +1,126 @@
+package chainedmap
+
+import (
+	"maps"
+	"sync"
+	"testing"
+)
+
+func filledMap(n int) *HashMap[int, int] {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < n; i++ {
+		m.Set(i, i)
+	}
+	return m
+}
+
+func TestRangeStablePassesEveryEntryOnce(t *testing.T) {
+	const n = 100
+	m := filledMap(n)
+	seen := make(map[int]int)
+	m.RangeStable(func(key, value int) bool {
+		if _, ok := seen[key]; ok {
+			t.Fatalf("key %d passed twice", key)
+		}
+		seen[key] = value
+		m.Set(key+n, key)  // enough new entries to grow the table
+		m.Set(key, -value) // values set during the loop aren't seen
+		m.Delete((key + 1) % n)
+		return true
+	})
+	if len(seen) != n {
+		t.Fatalf("%d entries passed, want %d", len(seen), n)
+	}
+	for key, value := range seen {
+		if key >= n || value != key {
+			t.Fatalf("passed %d: %d, want only the entries from before the loop", key, value)
+		}
+	}
+	if m.Stats().Rehashes == 0 {
+		t.Fatal("the loop didn't grow the table, the test doesn't test much")
+	}
+}
+
+func TestRangeStableStops(t *testing.T) {
+	m := filledMap(10)
+	calls := 0
+	m.RangeStable(func(int, int) bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Fatalf("fn called %d times after returning false, want 3", calls)
+	}
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	m := filledMap(50)
+	want := maps.Collect(m.All())
+	view := m.Snapshot()
+
+	m.Set(0, 100)
+	*m.Get(1) = 100 // writes through the pointer
+	m.Delete(2)
+	for i := 50; i < 500; i++ {
+		m.Set(i, i)
+	}
+	m.Clear()
+	m.Set(3, 100)
+
+	if got := maps.Collect(view.All()); !maps.Equal(got, want) {
+		t.Fatalf("view changed with the map: %v", got)
+	}
+	if view.Len() != len(want) {
+		t.Fatalf("view Len() = %d, want %d", view.Len(), len(want))
+	}
+	if value, ok := view.Get(1); !ok || value != 1 {
+		t.Fatalf("view Get(1) = %d, %v, want 1, true", value, ok)
+	}
+	if value := m.Get(3); value == nil || *value != 100 || m.Len() != 1 {
+		t.Fatalf("map after Clear and Set: Get(3) = %v, Len() = %d", value, m.Len())
+	}
+}
+
+func TestSnapshotsOfSnapshots(t *testing.T) {
+	m := filledMap(20)
+	first := m.Snapshot()
+	m.Set(0, 100)
+	second := m.Snapshot()
+	m.Set(0, 200)
+	for _, c := range []struct {
+		view *ReadOnlyView[int, int]
+		want int
+	}{{first, 0}, {second, 100}} {
+		if value, _ := c.view.Get(0); value != c.want {
+			t.Fatalf("Get(0) = %d, want %d", value, c.want)
+		}
+	}
+	if value := m.Get(0); *value != 200 {
+		t.Fatalf("map Get(0) = %d, want 200", *value)
+	}
+}
+
+// A view may be read by other goroutines while the map is written, run with -race
+func TestSnapshotConcurrentReads(t *testing.T) {
+	m := filledMap(1000)
+	view := m.Snapshot()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sum := 0
+			for _, value := range view.All() {
+				sum += value
+			}
+			if sum != 999*1000/2 {
+				t.Errorf("view sum %d, want %d", sum, 999*1000/2)
+			}
+		}()
+	}
+	for i := 0; i < 5000; i++ {
+		m.Set(i%2000, -i)
+		m.Delete(i % 3000)
+	}
+	wg.Wait()
+}
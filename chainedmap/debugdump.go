@@ -0,0 +1,41 @@
+package chainedmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"hashmaps/internal/hashing"
+)
+
+// DebugDump writes the bucket table, one line per bucket with the chain in
+// lookup order and the full hash of every key, so collisions can be seen:
+//
+//	bucket 2: "asdf" (0x6b0c3e1f4a0d9f02) -> "sdf2222222" (0x1d4f83a9c7b2e16a)
+//
+// Empty buckets are written too, the position in the table is the point.
+// Keys are written with %#v. It returns the first error of w.
+func (m *HashMap[K, V]) DebugDump(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "%d entries in %d buckets\n", m.length, m.capacity)
+	for i := 0; i < m.buckets.len(); i++ {
+		fmt.Fprintf(out, "bucket %d:", i)
+		for pair := m.buckets.head(i); pair != nil; pair = pair.Next {
+			if pair != m.buckets.head(i) {
+				fmt.Fprint(out, " ->")
+			}
+			fmt.Fprintf(out, " %#v (%#016x)", pair.Key, m.fullHash(pair.Key))
+		}
+		fmt.Fprintln(out)
+	}
+	return out.Flush()
+}
+
+// fullHash is the hash of a stored key before it's reduced to a bucket index
+func (m *HashMap[K, V]) fullHash(key K) uint64 {
+	if m.hasher != nil {
+		return m.hasher(key)
+	}
+	hashedKey, _ := hashing.Hash(m.seed, key) // stored keys were hashed before
+	return hashedKey
+}
@@ -0,0 +1,78 @@
+package chainedmap
+
+// Entry is a key and the place for it in the map, found by one walk of the
+// bucket chain, for read-modify-write without a Get and a Set:
+//
+//	*m.Entry(word).OrInsert(0)++
+//	m.Entry(id).Update(func(u *User) { u.Visits++ }).OrInsertWith(newUser)
+//
+// An Entry is only valid until the map is modified by anything else.
+type Entry[K comparable, V any] struct {
+	m         *HashMap[K, V]
+	key       K
+	hashedKey int
+	pair      *KVPair[K, V] // nil when key isn't in the map
+	last      *KVPair[K, V] // the tail of the bucket, for appending when pair is nil
+}
+
+// Entry panics like Set when the key is rejected by the NaNPolicy or can't be hashed
+func (m *HashMap[K, V]) Entry(key K) Entry[K, V] {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		panic(err)
+	}
+	e := Entry[K, V]{m: m, key: key, hashedKey: m.hash(key)}
+	m.buckets.own(e.hashedKey)
+	for pointer := m.buckets.head(e.hashedKey); pointer != nil; pointer = pointer.Next {
+		if m.norm.Equal(pointer.Key, key) {
+			e.pair = pointer
+			return e
+		}
+		e.last = pointer
+	}
+	return e
+}
+
+// Key returns the key normalized the way the map stores it
+func (e Entry[K, V]) Key() K {
+	return e.key
+}
+
+// OrInsert stores value when the key is missing and returns a pointer to
+// the value in the map either way
+func (e Entry[K, V]) OrInsert(value V) *V {
+	if e.pair != nil {
+		return &e.pair.Value
+	}
+	return e.insert(value)
+}
+
+// OrInsertWith is OrInsert calling compute only when the key is missing.
+// compute must not modify the map.
+func (e Entry[K, V]) OrInsertWith(compute func() V) *V {
+	if e.pair != nil {
+		return &e.pair.Value
+	}
+	return e.insert(compute())
+}
+
+// Update calls fn with the value when the key is there, and returns the
+// entry for an OrInsert in case it wasn't
+func (e Entry[K, V]) Update(fn func(value *V)) Entry[K, V] {
+	if e.pair != nil {
+		fn(&e.pair.Value)
+	}
+	return e
+}
+
+func (e Entry[K, V]) insert(value V) *V {
+	m := e.m
+	pair := &KVPair[K, V]{Key: e.key, Value: value}
+	rehashes := m.rehashes
+	m.appendPair(e.hashedKey, e.last, pair)
+	if m.rehashes != rehashes {
+		// a resize after a Snapshot copies the nodes instead of moving them
+		return m.Get(e.key)
+	}
+	return &pair.Value
+}
@@ -0,0 +1,21 @@
+package chainedmap
+
+// Equal reports whether both maps hold the same keys with values that are
+// equal according to eq, or to m's Comparer when eq is nil. Capacity,
+// bucket layout and insertion order don't matter.
+func (m *HashMap[K, V]) Equal(other *HashMap[K, V], eq func(a, b V) bool) bool {
+	eq = m.valuesEqual(eq)
+	if m == other {
+		return true
+	}
+	if m.length != other.length {
+		return false
+	}
+	equal := true
+	m.Range(func(key K, value V) bool {
+		otherValue := other.Get(key)
+		equal = otherValue != nil && eq(value, *otherValue)
+		return equal
+	})
+	return equal
+}
@@ -0,0 +1,144 @@
+package chainedmap
+
+import "iter"
+
+type funcPair[K, V any] struct {
+	key   K
+	value V
+	next  *funcPair[K, V]
+}
+
+// FuncHashMap is HashMap for keys that aren't comparable, e.g. slices or
+// structs holding maps: buckets and chains work the same, but keys are
+// hashed and compared by the functions given to MakeHashMapFunc instead of
+// the built-in hash and ==. Unlike keys.SliceKey nothing is copied or
+// encoded, so a key that is mutated while in the map gets lost, the same
+// as mutating a key of a built-in map through a pointer. MakeBytesKeyMap
+// is the exception, it copies stored keys.
+type FuncHashMap[K, V any] struct {
+	buckets []*funcPair[K, V]
+	length  int
+	hash    func(key K) uint64
+	equal   func(a, b K) bool
+	clone   func(key K) K // copies keys on insert, nil for none
+}
+
+// MakeHashMapFunc needs equal keys to hash the same, and neither function
+// may be nil
+func MakeHashMapFunc[K, V any](hash func(key K) uint64, equal func(a, b K) bool) *FuncHashMap[K, V] {
+	return &FuncHashMap[K, V]{
+		buckets: make([]*funcPair[K, V], defaultCapacity),
+		hash:    hash,
+		equal:   equal,
+	}
+}
+
+func (m *FuncHashMap[K, V]) bucket(key K) int {
+	return int(m.hash(key) % uint64(len(m.buckets)))
+}
+
+func (m *FuncHashMap[K, V]) Get(key K) *V {
+	for pair := m.buckets[m.bucket(key)]; pair != nil; pair = pair.next {
+		if m.equal(pair.key, key) {
+			return &pair.value
+		}
+	}
+	return nil
+}
+
+func (m *FuncHashMap[K, V]) Set(key K, value V) {
+	i := m.bucket(key)
+	for pair := m.buckets[i]; pair != nil; pair = pair.next {
+		if m.equal(pair.key, key) {
+			pair.value = value
+			return
+		}
+	}
+	if m.clone != nil {
+		key = m.clone(key)
+	}
+	m.buckets[i] = &funcPair[K, V]{key: key, value: value, next: m.buckets[i]}
+	m.length++
+	if float64(m.length) > defaultMaxLoadFactor*float64(len(m.buckets)) {
+		m.resize(2 * len(m.buckets))
+	}
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *FuncHashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *FuncHashMap[K, V]) Delete(key K) (V, bool) {
+	for link := &m.buckets[m.bucket(key)]; *link != nil; link = &(*link).next {
+		if pair := *link; m.equal(pair.key, key) {
+			*link = pair.next
+			m.length--
+			// the same hysteresis as HashMap.shrinkIfNeeded
+			if len(m.buckets) > defaultCapacity && float64(m.length) < defaultMaxLoadFactor*float64(len(m.buckets))/4 {
+				m.resize(max(len(m.buckets)/2, defaultCapacity))
+			}
+			return pair.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Pop is Delete, like HashMap.Pop
+func (m *FuncHashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+// Len returns the number of entries, in O(1)
+func (m *FuncHashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false.
+// The map must not be modified by fn.
+func (m *FuncHashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for _, bucket := range m.buckets {
+		for pair := bucket; pair != nil; pair = pair.next {
+			if !fn(pair.key, pair.value) {
+				return
+			}
+		}
+	}
+}
+
+// All is the range-over-func form of Range
+func (m *FuncHashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *FuncHashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *FuncHashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+func (m *FuncHashMap[K, V]) resize(newCapacity int) {
+	oldBuckets := m.buckets
+	m.buckets = make([]*funcPair[K, V], newCapacity)
+	for _, bucket := range oldBuckets {
+		for pair := bucket; pair != nil; {
+			next := pair.next
+			i := m.bucket(pair.key)
+			pair.next = m.buckets[i]
+			m.buckets[i] = pair
+			pair = next
+		}
+	}
+}
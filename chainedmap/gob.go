@@ -0,0 +1,99 @@
+package chainedmap
+
+import (
+	bytes2 "bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSnapshot = errors.New("invalid HashMap snapshot")
+
+// snapshot is what GobEncode writes. Keys[i] belongs to Values[i].
+type snapshot[K comparable, V any] struct {
+	Capacity      int64
+	MinCapacity   int64 // 0 in snapshots written before it was added
+	MaxLoadFactor float64
+	NaNPolicy     NaNPolicy
+	Keys          []K
+	Values        []V
+}
+
+// GobEncode writes the entries together with the capacity, minimum
+// capacity, max load factor and NaNPolicy, so GobDecode restores a map
+// that behaves the same and doesn't have to grow again. A custom Hasher or
+// Comparer is a func and the seed is random per process, none of them can
+// be written, see GobDecode.
+func (m *HashMap[K, V]) GobEncode() ([]byte, error) {
+	s := snapshot[K, V]{
+		Capacity:      m.capacity,
+		MinCapacity:   m.minCapacity,
+		MaxLoadFactor: m.maxLoadFactor,
+		NaNPolicy:     m.norm.NaNPolicy(),
+		Keys:          make([]K, 0, m.length),
+		Values:        make([]V, 0, m.length),
+	}
+	m.Range(func(key K, value V) bool {
+		s.Keys = append(s.Keys, key)
+		s.Values = append(s.Values, value)
+		return true
+	})
+	var buffer bytes2.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(s); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// GobDecode replaces the whole content of the map with the snapshot.
+// The map keeps its Hasher, Comparer and seed, so a map made with
+// MakeHashMapWithHasher has to be decoded into a map made the same way,
+// and one made WithSeed gets the same layout as the encoded map had under
+// that seed. A zero HashMap gets a fresh seed, keys may then sit in other
+// buckets than before.
+//
+// The capacity and minimum capacity are restored as they were encoded,
+// once they pass the checks a live map keeps: at most maxCapacity, the
+// minimum not above the capacity, and room for the entries at the load
+// factor. Snapshots written before MinCapacity was added get the default.
+func (m *HashMap[K, V]) GobDecode(data []byte) error {
+	var s snapshot[K, V]
+	if err := gob.NewDecoder(bytes2.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	switch {
+	case s.Capacity < 1 || s.Capacity > maxCapacity:
+		return fmt.Errorf("%w: capacity %d", ErrInvalidSnapshot, s.Capacity)
+	case s.MinCapacity < 0 || s.MinCapacity > s.Capacity:
+		return fmt.Errorf("%w: minimum capacity %d for capacity %d", ErrInvalidSnapshot, s.MinCapacity, s.Capacity)
+	case !validLoadFactor(s.MaxLoadFactor):
+		return fmt.Errorf("%w: max load factor %v", ErrInvalidSnapshot, s.MaxLoadFactor)
+	case !s.NaNPolicy.Valid():
+		return fmt.Errorf("%w: NaNPolicy %d", ErrInvalidSnapshot, s.NaNPolicy)
+	case len(s.Keys) != len(s.Values):
+		return fmt.Errorf("%w: %d keys but %d values", ErrInvalidSnapshot, len(s.Keys), len(s.Values))
+	case s.Capacity < maxCapacity && float64(len(s.Keys)) > s.MaxLoadFactor*float64(s.Capacity):
+		return fmt.Errorf("%w: %d entries don't fit into capacity %d", ErrInvalidSnapshot, len(s.Keys), s.Capacity)
+	}
+	restored, _ := makeHashMap[K, V](s.NaNPolicy, s.MaxLoadFactor) // both were checked above
+	restored.hasher = m.hasher
+	if m.comparer != nil {
+		restored.comparer = m.comparer
+	}
+	if m.capacity > 0 { // m was made, not a zero HashMap
+		restored.seed = m.seed
+	}
+	restored.capacity = s.Capacity
+	restored.minCapacity = s.MinCapacity
+	if s.MinCapacity == 0 {
+		restored.minCapacity = min(defaultCapacity, s.Capacity)
+	}
+	restored.buckets = makeBucketTable[K, V](int(s.Capacity))
+	for i, key := range s.Keys { // the entries fit, TrySet won't grow the table
+		if err := restored.TrySet(key, s.Values[i]); err != nil {
+			return err
+		}
+	}
+	*m = *restored
+	return nil
+}
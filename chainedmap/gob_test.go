@@ -0,0 +1,121 @@
+package chainedmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	m := MakeHashMap[string, int](WithCapacity(20), WithLoadFactor(2), WithNaNPolicy(RejectNaN))
+	for i := 0; i < 20; i++ {
+		m.Set(string(rune('a'+i)), i)
+	}
+	data, err := m.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded HashMap[string, int]
+	if err := decoded.GobDecode(data); err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(decoded.ToMap(), m.ToMap()) {
+		t.Fatalf("decoded %v, want %v", decoded.ToMap(), m.ToMap())
+	}
+	if decoded.minCapacity != m.minCapacity || decoded.maxLoadFactor != 2 || decoded.norm.NaNPolicy() != RejectNaN {
+		t.Fatalf("decoded minCapacity %d, load factor %v, NaNPolicy %v, want %d, 2, RejectNaN",
+			decoded.minCapacity, decoded.maxLoadFactor, decoded.norm.NaNPolicy(), m.minCapacity)
+	}
+}
+
+// Clear keeps the table, so the capacity is far more than the entries need
+// and only the snapshot knows it
+func TestGobRoundTripKeepsTheCapacity(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	m.Clear()
+	m.Set(1, 1)
+	data, err := m.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := MakeHashMap[int, int]()
+	if err := decoded.GobDecode(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Stats().Capacity, m.Stats().Capacity; got != want {
+		t.Fatalf("decoded Stats().Capacity = %d, want %d", got, want)
+	}
+	if decoded.minCapacity != defaultCapacity || decoded.Len() != 1 {
+		t.Fatalf("decoded minCapacity %d, Len %d, want %d, 1", decoded.minCapacity, decoded.Len(), defaultCapacity)
+	}
+}
+
+func TestGobDecodeBeforeMinCapacity(t *testing.T) {
+	s := snapshot[int, int]{Capacity: 16, MaxLoadFactor: 0.75, Keys: []int{1}, Values: []int{1}}
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(s); err != nil {
+		t.Fatal(err)
+	}
+	var m HashMap[int, int]
+	if err := m.GobDecode(buffer.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if m.capacity != 16 || m.minCapacity != defaultCapacity {
+		t.Fatalf("capacity %d, minimum %d, want 16, %d", m.capacity, m.minCapacity, defaultCapacity)
+	}
+}
+
+func TestGobDecodeKeepsTheSeed(t *testing.T) {
+	seed := MakeSeed()
+	m := MakeHashMap[int, int](WithSeed(seed))
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+	data, err := m.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := MakeHashMap[int, int](WithSeed(seed))
+	if err := decoded.GobDecode(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := slices.Collect(decoded.Keys()), slices.Collect(m.Keys()); !slices.Equal(got, want) {
+		t.Fatalf("decoded order %v, want %v", got, want)
+	}
+}
+
+// Nothing in a snapshot may make GobDecode allocate past maxCapacity or
+// grow without end, whatever it claims
+func TestGobDecodeRejectsBadSnapshots(t *testing.T) {
+	many := make([]int, 10_000)
+	for i := range many {
+		many[i] = i
+	}
+	for _, s := range []snapshot[int, int]{
+		{Capacity: 0, MaxLoadFactor: 0.75},
+		{Capacity: maxCapacity * 2, MinCapacity: 4, MaxLoadFactor: 0.75},
+		{Capacity: 1 << 62, MinCapacity: 1 << 62, MaxLoadFactor: 0.75},
+		{Capacity: 4, MinCapacity: -1, MaxLoadFactor: 0.75},
+		{Capacity: 4, MinCapacity: 8, MaxLoadFactor: 0.75},
+		{Capacity: 4, MaxLoadFactor: -1},
+		{Capacity: 4, MaxLoadFactor: 1e-300, Keys: []int{1}, Values: []int{1}},
+		{Capacity: 4, MaxLoadFactor: 0.75, Keys: []int{1}},
+		{Capacity: 4, MaxLoadFactor: 0.75, Keys: many, Values: many},
+		{Capacity: 1 << 10, MaxLoadFactor: 1.0 / maxCapacity, Keys: many, Values: many},
+	} {
+		var buffer bytes.Buffer
+		if err := gob.NewEncoder(&buffer).Encode(s); err != nil {
+			t.Fatal(err)
+		}
+		var m HashMap[int, int]
+		if err := m.GobDecode(buffer.Bytes()); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Fatalf("GobDecode(%+v) error = %v, want ErrInvalidSnapshot", s, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package chainedmap
+
+// GroupBy buckets items by keyFn, each group keeps the items in their
+// order in the slice. Like Set it panics on a key it can't store.
+func GroupBy[T any, K comparable](items []T, keyFn func(T) K) *HashMap[K, []T] {
+	groups := MakeHashMap[K, []T]()
+	for _, item := range items {
+		key := keyFn(item)
+		if group := groups.Get(key); group != nil {
+			*group = append(*group, item)
+		} else {
+			groups.Set(key, []T{item})
+		}
+	}
+	return groups
+}
+
+// CountBy is GroupBy keeping only the size of each group
+func CountBy[T any, K comparable](items []T, keyFn func(T) K) *HashMap[K, int] {
+	counts := MakeHashMap[K, int]()
+	for _, item := range items {
+		key := keyFn(item)
+		if count := counts.Get(key); count != nil {
+			*count++
+		} else {
+			counts.Set(key, 1)
+		}
+	}
+	return counts
+}
@@ -0,0 +1,141 @@
+package chainedmap
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"unsafe"
+)
+
+// The table grows with the number of entries, not with the length of single
+// chains: once length/capacity would exceed maxLoadFactor the capacity doubles
+// and every node is moved into its new bucket. Each doubling is paid for by
+// the inserts since the previous one, so Set stays amortized O(1), and one
+// unlucky bucket can't make a nearly empty table grow.
+const defaultMaxLoadFactor = 0.75
+
+// maxCapacity is the most buckets a table gets. Set stops growing it there
+// and lets the chains get longer instead, while asking for more up front,
+// with WithCapacity or a load factor that can't hold a single entry even
+// there, fails with ErrCapacityOverflow or ErrInvalidLoadFactor.
+const maxCapacity = 1 << min(32, bits.UintSize-2)
+
+var (
+	ErrInvalidLoadFactor = errors.New("max load factor must be a finite number of at least 1/maxCapacity")
+	ErrCapacityOverflow  = errors.New("table would have to grow beyond maxCapacity")
+)
+
+func validLoadFactor(maxLoadFactor float64) bool {
+	return maxLoadFactor*maxCapacity >= 1 && !math.IsInf(maxLoadFactor, 1)
+}
+
+// MakeHashMapWithLoadFactor makes a map that grows once it holds more than
+// maxLoadFactor entries per bucket. Lower values mean shorter chains and
+// more memory, values above 1 are allowed.
+func MakeHashMapWithLoadFactor[K comparable, V any](maxLoadFactor float64) (*HashMap[K, V], error) {
+	return makeHashMap[K, V](CanonicalizeNaN, maxLoadFactor)
+}
+
+func (m *HashMap[K, V]) growIfNeeded() {
+	if m.capacity < maxCapacity && float64(m.length) > m.maxLoadFactor*float64(m.capacity) {
+		m.resize(min(m.capacity*2, maxCapacity))
+	}
+}
+
+// shrinkIfNeeded halves the table once the load factor drops below a quarter
+// of maxLoadFactor. Halving leaves it at half of maxLoadFactor, so a map
+// that hovers around one size doesn't shrink and grow again on every
+// other Delete and Set. It never goes below minCapacity.
+func (m *HashMap[K, V]) shrinkIfNeeded() {
+	if m.capacity > m.minCapacity && float64(m.length) < m.maxLoadFactor*float64(m.capacity)/4 {
+		m.resize(max(m.capacity/2, m.minCapacity))
+	}
+}
+
+// Compact shrinks the table to the smallest capacity that holds the entries
+// without exceeding the load factor, e.g. after a bulk delete when the map
+// won't grow again. Deletes already shrink it, but only down to a quarter
+// of the load factor. It returns the bytes given back, see releasedBytes,
+// 0 when there was nothing to give back.
+func (m *HashMap[K, V]) Compact() int {
+	newCapacity := max(int64(math.Ceil(float64(m.length)/m.maxLoadFactor)), m.minCapacity)
+	if newCapacity >= m.capacity {
+		return 0
+	}
+	released := releasedBytes[K, V](m.capacity-newCapacity, 0)
+	m.resize(newCapacity)
+	return released
+}
+
+// releasedBytes is what dropping buckets bucket slots and entries nodes
+// leaves for the garbage collector. It counts only the table and the nodes
+// themselves, not memory keys and values point to, so for strings, slices
+// and pointers it is a lower bound.
+func releasedBytes[K comparable, V any](buckets int64, entries int) int {
+	return int(buckets)*int(unsafe.Sizeof((*KVPair[K, V])(nil))) + entries*int(unsafe.Sizeof(KVPair[K, V]{}))
+}
+
+// grownCapacity doubles capacity until n entries fit without exceeding the
+// load factor. It returns ErrCapacityOverflow along with maxCapacity when
+// they don't fit into maxCapacity buckets either.
+func (m *HashMap[K, V]) grownCapacity(capacity int64, n int) (int64, error) {
+	for float64(n) > m.maxLoadFactor*float64(capacity) {
+		if capacity >= maxCapacity {
+			return maxCapacity, ErrCapacityOverflow
+		}
+		capacity = min(capacity*2, maxCapacity)
+	}
+	return capacity, nil
+}
+
+// reserve grows the table so n entries fit without exceeding the load
+// factor. When they can't, it returns ErrCapacityOverflow before allocating
+// anything. Callers that only presize for entries they Set anyway ignore
+// that, Set grows the table as far as it can on its own.
+func (m *HashMap[K, V]) reserve(n int) error {
+	newCapacity, err := m.grownCapacity(m.capacity, n)
+	if err != nil {
+		return err
+	}
+	if newCapacity != m.capacity {
+		m.resize(newCapacity)
+	}
+	return nil
+}
+
+// resizeFor resizes the table once to where Sets and Deletes would take it
+// one at a time for n entries: grown until they fit, up to maxCapacity, or
+// halved while the load factor stays below a quarter of maxLoadFactor
+func (m *HashMap[K, V]) resizeFor(n int) {
+	newCapacity, _ := m.grownCapacity(m.capacity, n)
+	for newCapacity > m.minCapacity && float64(n) < m.maxLoadFactor*float64(newCapacity)/4 {
+		newCapacity = max(newCapacity/2, m.minCapacity)
+	}
+	if newCapacity != m.capacity {
+		m.resize(newCapacity)
+	}
+}
+
+// resize moves the existing nodes into a table of newCapacity buckets
+// instead of allocating them again. Nodes a ReadOnlyView may still see
+// are copied instead, the new table isn't shared with anything.
+func (m *HashMap[K, V]) resize(newCapacity int64) {
+	oldBuckets := m.buckets
+	m.rehashes++
+	m.capacity = newCapacity
+	m.buckets = makeBucketTable[K, V](int(newCapacity))
+	for _, segment := range oldBuckets.segments {
+		for _, bucket := range segment {
+			for pair := bucket; pair != nil; {
+				next := pair.Next
+				if oldBuckets.shared != nil {
+					pair = &KVPair[K, V]{Key: pair.Key, Value: pair.Value}
+				}
+				hashedKey := m.hash(pair.Key)
+				pair.Next = m.buckets.head(hashedKey)
+				m.buckets.setHead(hashedKey, pair)
+				pair = next
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package chainedmap
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"unsafe"
+)
+
+// Every resize moves all entries, so inserts are amortized O(1) when the
+// entries moved by all resizes together stay within a constant times the
+// number of inserts
+func TestInsertsAreAmortizedConstant(t *testing.T) {
+	const n = 1 << 16
+	m := MakeHashMap[int, int]()
+	moved, rehashes := 0, 0
+	for i := 0; i < n; i++ {
+		m.Set(i, i)
+		if m.rehashes != rehashes {
+			rehashes = m.rehashes
+			moved += m.length
+		}
+	}
+	if moved > 2*n {
+		t.Fatalf("resizes moved %d entries for %d inserts, want at most %d", moved, n, 2*n)
+	}
+	if limit := 2 * n / defaultMaxLoadFactor; float64(m.capacity) > limit {
+		t.Fatalf("capacity %d for %d entries, want at most %v", m.capacity, n, limit)
+	}
+}
+
+// One long chain must not grow a table that is nearly empty
+func TestGrowthIgnoresChainLength(t *testing.T) {
+	m := MakeHashMapWithHasher[int, int](func(int) uint64 { return 0 })
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+	stats := m.Stats()
+	if stats.LongestChain != 100 {
+		t.Fatalf("longest chain %d, want all 100 keys in one bucket", stats.LongestChain)
+	}
+	if stats.LoadFactor <= defaultMaxLoadFactor/2 || stats.LoadFactor > defaultMaxLoadFactor {
+		t.Fatalf("load factor %v, want the table sized by the entry count", stats.LoadFactor)
+	}
+}
+
+func TestLoadFactor(t *testing.T) {
+	for _, maxLoadFactor := range []float64{0.5, 1, 4} {
+		m, err := MakeHashMapWithLoadFactor[int, int](maxLoadFactor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10_000; i++ {
+			m.Set(i, i)
+			if loadFactor := float64(m.Len()) / float64(m.capacity); loadFactor > maxLoadFactor {
+				t.Fatalf("load factor %v after %d inserts, want at most %v", loadFactor, i+1, maxLoadFactor)
+			}
+		}
+	}
+	// below 1/maxCapacity not even one entry fits, reserve used to double forever
+	for _, maxLoadFactor := range []float64{0, -1, math.NaN(), math.Inf(1), 0.5 / maxCapacity, math.SmallestNonzeroFloat64} {
+		if _, err := MakeHashMapWithLoadFactor[int, int](maxLoadFactor); err != ErrInvalidLoadFactor {
+			t.Fatalf("MakeHashMapWithLoadFactor(%v) = %v, want ErrInvalidLoadFactor", maxLoadFactor, err)
+		}
+	}
+}
+
+func TestDeletesShrink(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 1000; i++ {
+		m.Delete(i)
+	}
+	if m.capacity != defaultCapacity {
+		t.Fatalf("capacity %d after deleting everything, want %d", m.capacity, defaultCapacity)
+	}
+}
+
+func TestCapacityOverflow(t *testing.T) {
+	for _, n := range []int{maxCapacity, math.MaxInt} {
+		if _, err := TryMakeHashMap[int, int](WithCapacity(n)); !errors.Is(err, ErrCapacityOverflow) {
+			t.Fatalf("TryMakeHashMap(WithCapacity(%d)) = %v, want ErrCapacityOverflow", n, err)
+		}
+	}
+	if !validLoadFactor(1.0 / maxCapacity) {
+		t.Fatalf("load factor 1/maxCapacity is invalid, want one entry in the largest table")
+	}
+	if _, err := TryMakeHashMap[int, int](WithCapacity(2), WithLoadFactor(1.0/maxCapacity)); !errors.Is(err, ErrCapacityOverflow) {
+		t.Fatalf("TryMakeHashMap(WithCapacity(2)) with the smallest load factor = %v, want ErrCapacityOverflow", err)
+	}
+	if _, err := TryMakeHashMap[int, int](WithCapacity(-1)); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("TryMakeHashMap(WithCapacity(-1)) = %v, want ErrInvalidCapacity", err)
+	}
+	if _, err := TryMakeHashMap[int, int](WithLoadFactor(0)); !errors.Is(err, ErrInvalidLoadFactor) {
+		t.Fatalf("TryMakeHashMap(WithLoadFactor(0)) = %v, want ErrInvalidLoadFactor", err)
+	}
+
+	m := MakeHashMap[int, int]()
+	if capacity, err := m.grownCapacity(defaultCapacity, math.MaxInt); capacity != maxCapacity || !errors.Is(err, ErrCapacityOverflow) {
+		t.Fatalf("grownCapacity(MaxInt) = %d, %v, want maxCapacity, ErrCapacityOverflow", capacity, err)
+	}
+	if capacity, err := m.grownCapacity(3, int(defaultMaxLoadFactor*maxCapacity)); capacity != maxCapacity || err != nil {
+		t.Fatalf("grownCapacity of a full largest table = %d, %v, want maxCapacity, nil", capacity, err)
+	}
+	if err := m.reserve(math.MaxInt); !errors.Is(err, ErrCapacityOverflow) || m.capacity != defaultCapacity {
+		t.Fatalf("reserve(MaxInt) = %v with capacity %d, want ErrCapacityOverflow and nothing allocated", err, m.capacity)
+	}
+}
+
+func TestReleasedBytes(t *testing.T) {
+	const pointer, node = int(unsafe.Sizeof((*KVPair[int, int])(nil))), int(unsafe.Sizeof(KVPair[int, int]{}))
+	m := MakeHashMap[int, int]()
+	initial := m.Stats().Capacity
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	m.DeleteFunc(func(key, _ int) bool { return key >= 100 })
+	before := m.Stats().Capacity
+	released := m.Compact()
+	after := m.Stats().Capacity
+	if after >= before || released != (before-after)*pointer {
+		t.Fatalf("Compact from %d to %d buckets released %d bytes, want %d", before, after, released, (before-after)*pointer)
+	}
+	if released := m.Compact(); released != 0 {
+		t.Fatalf("second Compact released %d bytes, want 0", released)
+	}
+	if released := m.Clear(); released != 100*node || m.Len() != 0 || m.Stats().Capacity != after {
+		t.Fatalf("Clear of 100 entries released %d bytes, want %d and the table kept", released, 100*node)
+	}
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	grown := m.Stats().Capacity
+	want := (grown-initial)*pointer + 1000*node
+	if released := m.ClearAndShrink(); released != want || m.Stats().Capacity != initial {
+		t.Fatalf("ClearAndShrink released %d bytes and left %d buckets, want %d and %d", released, m.Stats().Capacity, want, initial)
+	}
+}
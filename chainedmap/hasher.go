@@ -0,0 +1,32 @@
+package chainedmap
+
+import "hashmaps/internal/hashing"
+
+// Hasher replaces the built-in key hashing, e.g. with a hash precomputed
+// on the key type or a deliberately bad one to exercise collisions.
+// Keys that are equal must hash the same; float keys are normalized
+// before they get here, see internal/keynorm.
+type Hasher[K comparable] func(key K) uint64
+
+// MakeHashMapWithHasher makes a map that buckets keys by hasher instead
+// of the built-in hash. A nil hasher means the built-in one.
+func MakeHashMapWithHasher[K comparable, V any](hasher Hasher[K]) *HashMap[K, V] {
+	m := MakeHashMap[K, V]()
+	m.hasher = hasher
+	return m
+}
+
+func (m *HashMap[K, V]) tryHash(key K) (int, error) {
+	if m.hasher != nil {
+		return int(m.hasher(key) % uint64(m.capacity)), nil
+	}
+	return m.defaultHash(key)
+}
+
+func (m *HashMap[K, V]) defaultHash(key K) (int, error) {
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return 0, err
+	}
+	return int(hashedKey % uint64(m.capacity)), nil
+}
@@ -0,0 +1,157 @@
+package chainedmap
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Hooks are called after a HookedHashMap changed, like cache.Hooks. A plain
+// map never evicts, so where a cache has OnEvict there is OnDelete, for
+// every entry that Delete, Remove, DeleteFunc or Clear took out; by the time
+// it runs the entry is gone. Nil hooks are skipped. They run synchronously
+// and must not modify the map.
+type Hooks[K comparable, V any] struct {
+	OnInsert func(key K, value V)              // key wasn't in the map before
+	OnUpdate func(key K, oldValue, newValue V) // key's value was replaced
+	OnDelete func(key K, value V)
+}
+
+// HookedHashMap is a HashMap that calls Hooks on every change, opt-in so
+// HashMap itself doesn't pay for the checks. Get returns a copy of the
+// value, a write through a pointer into the map would skip OnUpdate.
+type HookedHashMap[K comparable, V any] struct {
+	m     *HashMap[K, V]
+	hooks Hooks[K, V]
+}
+
+// WithHooks sets the hooks of a HookedHashMap. The key and value types of
+// hooks have to be those of the map.
+func WithHooks[K comparable, V any](hooks Hooks[K, V]) Option {
+	return func(c *config) { c.hooks = hooks }
+}
+
+// MakeHookedHashMap takes the options of MakeHashMap plus WithHooks, and
+// panics like it:
+//
+//	m := chainedmap.MakeHookedHashMap[string, int](chainedmap.WithHooks(hooks), chainedmap.WithCapacity(1024))
+func MakeHookedHashMap[K comparable, V any](opts ...Option) *HookedHashMap[K, V] {
+	h, err := TryMakeHookedHashMap[K, V](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func TryMakeHookedHashMap[K comparable, V any](opts ...Option) (*HookedHashMap[K, V], error) {
+	c := makeConfig(opts)
+	h := &HookedHashMap[K, V]{}
+	if c.hooks != nil {
+		hooks, ok := c.hooks.(Hooks[K, V])
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrHooksType, c.hooks)
+		}
+		h.hooks = hooks
+	}
+	m, err := makeFromConfig[K, V](c)
+	if err != nil {
+		return nil, err
+	}
+	h.m = m
+	return h, nil
+}
+
+func (h *HookedHashMap[K, V]) Get(key K) (V, bool) {
+	if value := h.m.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set walks the chain once, like Upsert, and calls OnInsert or OnUpdate
+func (h *HookedHashMap[K, V]) Set(key K, value V) {
+	e := h.m.Entry(key)
+	if e.pair != nil {
+		oldValue := e.pair.Value
+		e.pair.Value = value
+		if h.hooks.OnUpdate != nil {
+			h.hooks.OnUpdate(e.key, oldValue, value)
+		}
+		return
+	}
+	e.insert(value)
+	if h.hooks.OnInsert != nil {
+		h.hooks.OnInsert(e.key, value)
+	}
+}
+
+func (h *HookedHashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok := h.m.Delete(key)
+	if ok {
+		h.deleted(key, value)
+	}
+	return value, ok
+}
+
+// Pop is Delete, OnDelete runs for a popped entry too
+func (h *HookedHashMap[K, V]) Pop(key K) (V, bool) {
+	return h.Delete(key)
+}
+
+func (h *HookedHashMap[K, V]) Remove(key K) {
+	h.Delete(key)
+}
+
+// DeleteFunc calls OnDelete for every entry pred returned true for, once
+// all of them are removed
+func (h *HookedHashMap[K, V]) DeleteFunc(pred func(key K, value V) bool) int {
+	if h.hooks.OnDelete == nil {
+		return h.m.DeleteFunc(pred)
+	}
+	var deleted []KVPair[K, V]
+	n := h.m.DeleteFunc(func(key K, value V) bool {
+		if !pred(key, value) {
+			return false
+		}
+		deleted = append(deleted, KVPair[K, V]{Key: key, Value: value})
+		return true
+	})
+	for _, pair := range deleted {
+		h.hooks.OnDelete(pair.Key, pair.Value)
+	}
+	return n
+}
+
+// Clear empties the map, then calls OnDelete for every entry it held.
+// It returns the bytes HashMap.Clear gave back.
+func (h *HookedHashMap[K, V]) Clear() int {
+	if h.hooks.OnDelete == nil {
+		return h.m.Clear()
+	}
+	deleted := h.m.Iter().Collect()
+	released := h.m.Clear()
+	for _, pair := range deleted {
+		h.hooks.OnDelete(pair.Key, pair.Value)
+	}
+	return released
+}
+
+func (h *HookedHashMap[K, V]) Len() int {
+	return h.m.Len()
+}
+
+// Range and All are HashMap.Range and HashMap.All, the map must not be
+// modified during them
+func (h *HookedHashMap[K, V]) Range(fn func(key K, value V) bool) {
+	h.m.Range(fn)
+}
+
+func (h *HookedHashMap[K, V]) All() iter.Seq2[K, V] {
+	return h.m.Range
+}
+
+func (h *HookedHashMap[K, V]) deleted(key K, value V) {
+	if h.hooks.OnDelete != nil {
+		h.hooks.OnDelete(key, value)
+	}
+}
@@ -0,0 +1,119 @@
+package chainedmap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func recordingHooks(events *[]string) Hooks[string, int] {
+	return Hooks[string, int]{
+		OnInsert: func(key string, value int) { *events = append(*events, fmt.Sprintf("insert %s=%d", key, value)) },
+		OnUpdate: func(key string, oldValue, newValue int) {
+			*events = append(*events, fmt.Sprintf("update %s=%d->%d", key, oldValue, newValue))
+		},
+		OnDelete: func(key string, value int) { *events = append(*events, fmt.Sprintf("delete %s=%d", key, value)) },
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var events []string
+	h := MakeHookedHashMap[string, int](WithHooks(recordingHooks(&events)))
+	h.Set("a", 1)
+	h.Set("b", 2)
+	h.Set("a", 3)
+	h.Delete("b")
+	h.Delete("missing")
+	h.Remove("a")
+	want := []string{"insert a=1", "insert b=2", "update a=1->3", "delete b=2", "delete a=3"}
+	if !slices.Equal(events, want) {
+		t.Fatalf("events %q, want %q", events, want)
+	}
+	if h.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", h.Len())
+	}
+}
+
+func TestHooksOnBulkDeletes(t *testing.T) {
+	var events []string
+	h := MakeHookedHashMap[string, int](WithHooks(recordingHooks(&events)), WithCapacity(16))
+	for i, key := range []string{"a", "b", "c", "d"} {
+		h.Set(key, i)
+	}
+	events = nil
+	if n := h.DeleteFunc(func(_ string, value int) bool { return value%2 == 0 }); n != 2 {
+		t.Fatalf("DeleteFunc = %d, want 2", n)
+	}
+	h.Clear()
+	slices.Sort(events)
+	want := []string{"delete a=0", "delete b=1", "delete c=2", "delete d=3"}
+	if !slices.Equal(events, want) {
+		t.Fatalf("events %q, want %q", events, want)
+	}
+	if _, ok := h.Get("b"); ok || h.Len() != 0 {
+		t.Fatal("Clear left entries behind")
+	}
+}
+
+func TestNilHooksAreSkipped(t *testing.T) {
+	h := MakeHookedHashMap[string, int](WithHooks(Hooks[string, int]{}))
+	h.Set("a", 1)
+	h.Set("a", 2)
+	if value, ok := h.Get("a"); !ok || value != 2 {
+		t.Fatalf("Get(a) = %d, %v, want 2, true", value, ok)
+	}
+	h.Clear()
+}
+
+// Every hook runs after the change, OnDelete included, so it sees the entry gone
+func TestHooksRunAfterTheChange(t *testing.T) {
+	var h *HookedHashMap[string, int]
+	checks := 0
+	h = MakeHookedHashMap[string, int](WithHooks(Hooks[string, int]{
+		OnInsert: func(key string, value int) {
+			if got, ok := h.Get(key); !ok || got != value {
+				t.Fatalf("OnInsert(%s) ran before the insert", key)
+			}
+			checks++
+		},
+		OnUpdate: func(key string, _, newValue int) {
+			if got, _ := h.Get(key); got != newValue {
+				t.Fatalf("OnUpdate(%s) ran before the update", key)
+			}
+			checks++
+		},
+		OnDelete: func(key string, _ int) {
+			if _, ok := h.Get(key); ok {
+				t.Fatalf("OnDelete(%s) ran before the entry was removed", key)
+			}
+			checks++
+		},
+	}))
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		h.Set(key, i)
+	}
+	h.Set("a", 10)
+	h.Delete("a")
+	h.DeleteFunc(func(key string, _ int) bool { return key < "d" })
+	h.Clear()
+	if checks != 11 {
+		t.Fatalf("%d hooks ran, want 5 inserts, an update and 5 deletes", checks)
+	}
+}
+
+func TestHooksOption(t *testing.T) {
+	if _, err := TryMakeHashMap[string, int](WithHooks(Hooks[string, int]{})); !errors.Is(err, ErrHooksOnHashMap) {
+		t.Fatalf("TryMakeHashMap with hooks = %v, want ErrHooksOnHashMap", err)
+	}
+	if _, err := TryMakeHookedHashMap[string, string](WithHooks(Hooks[string, int]{})); !errors.Is(err, ErrHooksType) {
+		t.Fatalf("TryMakeHookedHashMap with hooks for another value type = %v, want ErrHooksType", err)
+	}
+	if _, err := TryMakeHookedHashMap[string, int](WithCapacity(-1)); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("TryMakeHookedHashMap(WithCapacity(-1)) = %v, want ErrInvalidCapacity", err)
+	}
+	h := MakeHookedHashMap[string, int](WithCapacity(100), WithLoadFactor(1))
+	if h.m.Stats().Capacity < 100 {
+		t.Fatalf("capacity %d, WithCapacity(100) must apply next to the hooks", h.m.Stats().Capacity)
+	}
+}
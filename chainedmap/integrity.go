@@ -0,0 +1,92 @@
+package chainedmap
+
+import "fmt"
+
+// IntegrityViolation describes one broken invariant found by VerifyIntegrity
+type IntegrityViolation struct {
+	Bucket  int // -1 when the problem isn't tied to a single bucket
+	Problem string
+}
+
+func (v IntegrityViolation) String() string {
+	if v.Bucket < 0 {
+		return v.Problem
+	}
+	return fmt.Sprintf("bucket %d: %s", v.Bucket, v.Problem)
+}
+
+// VerifyIntegrity walks the whole structure and reports every broken invariant:
+// the bucket table matches the capacity, chains are acyclic and don't share
+// nodes (e.g. stale Next pointers left behind by a rehash), every key hashes
+// to the bucket it's in, is stored normalized and is there only once,
+// Len matches the number of stored entries and the load factor is respected.
+// It's meant for debugging and fuzzing, an empty result means all is well.
+func (m *HashMap[K, V]) VerifyIntegrity() []IntegrityViolation {
+	var violations []IntegrityViolation
+	report := func(bucket int, format string, args ...any) {
+		violations = append(violations, IntegrityViolation{Bucket: bucket, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	if int64(m.buckets.len()) != m.capacity {
+		report(-1, "capacity is %d but the table has %d buckets", m.capacity, m.buckets.len())
+	}
+	total := 0
+	for i, segment := range m.buckets.segments {
+		total += len(segment)
+		if i < len(m.buckets.segments)-1 && len(segment) != segmentSize {
+			report(-1, "segment %d has %d buckets, only the last one may be shorter than %d", i, len(segment), segmentSize)
+		}
+	}
+	if total != m.buckets.len() {
+		report(-1, "segments hold %d buckets but the table claims %d", total, m.buckets.len())
+	}
+
+	owner := make(map[*KVPair[K, V]]int) // node -> bucket it was first reached from
+	stored := 0
+	for bucket := 0; bucket < total; bucket++ {
+		var chain []*KVPair[K, V]
+		for node := m.buckets.head(bucket); node != nil; node = node.Next {
+			if first, seen := owner[node]; seen {
+				if first == bucket {
+					report(bucket, "chain has a cycle after %d nodes", len(chain))
+				} else {
+					report(bucket, "node with key %v is also in the chain of bucket %d", node.Key, first)
+				}
+				break
+			}
+			owner[node] = bucket
+			chain = append(chain, node)
+			stored++
+		}
+
+		for i, node := range chain {
+			normalized, err := m.norm.Normalize(node.Key)
+			if err != nil {
+				report(bucket, "key %v should have been rejected: %v", node.Key, err)
+			} else if !m.norm.Equal(normalized, node.Key) || fmt.Sprint(normalized) != fmt.Sprint(node.Key) {
+				report(bucket, "key %v is stored without normalization", node.Key)
+			}
+			hashedKey, err := m.tryHash(node.Key)
+			if err != nil {
+				report(bucket, "key %v can't be hashed: %v", node.Key, err)
+			} else if hashedKey != bucket {
+				report(bucket, "key %v hashes to bucket %d", node.Key, hashedKey)
+			}
+			for _, other := range chain[:i] {
+				if m.norm.Equal(other.Key, node.Key) {
+					report(bucket, "key %v is stored more than once", node.Key)
+					break
+				}
+			}
+		}
+	}
+	if stored != m.length {
+		report(-1, "Len is %d but %d entries are stored", m.length, stored)
+	}
+	if !validLoadFactor(m.maxLoadFactor) {
+		report(-1, "max load factor %v is invalid", m.maxLoadFactor)
+	} else if float64(stored) > m.maxLoadFactor*float64(m.capacity) {
+		report(-1, "%d entries in %d buckets exceed the max load factor %v", stored, m.capacity, m.maxLoadFactor)
+	}
+	return violations
+}
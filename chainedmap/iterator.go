@@ -0,0 +1,95 @@
+package chainedmap
+
+import "iter"
+
+// Iterator is a lazy pipeline over the map entries. Filter, MapValues and Take
+// only wrap the source, nothing is visited until a terminal method like
+// Collect or Each runs, and no intermediate slices are built.
+// MapValues keeps the value type, methods can't introduce new type parameters,
+// the MapValues function builds a map of another value type.
+type Iterator[K comparable, V any] struct {
+	each func(yield func(K, V) bool)
+}
+
+func (m *HashMap[K, V]) Iter() *Iterator[K, V] {
+	return &Iterator[K, V]{each: m.Range}
+}
+
+func (it *Iterator[K, V]) Filter(pred func(K, V) bool) *Iterator[K, V] {
+	return &Iterator[K, V]{each: func(yield func(K, V) bool) {
+		it.each(func(key K, value V) bool {
+			if !pred(key, value) {
+				return true
+			}
+			return yield(key, value)
+		})
+	}}
+}
+
+func (it *Iterator[K, V]) MapValues(f func(V) V) *Iterator[K, V] {
+	return &Iterator[K, V]{each: func(yield func(K, V) bool) {
+		it.each(func(key K, value V) bool {
+			return yield(key, f(value))
+		})
+	}}
+}
+
+// Take stops the pipeline after n entries
+func (it *Iterator[K, V]) Take(n int) *Iterator[K, V] {
+	return &Iterator[K, V]{each: func(yield func(K, V) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		it.each(func(key K, value V) bool {
+			taken++
+			return yield(key, value) && taken < n
+		})
+	}}
+}
+
+// All runs the pipeline inside a range loop
+func (it *Iterator[K, V]) All() iter.Seq2[K, V] {
+	return it.each
+}
+
+// Each runs the pipeline until fn returns false
+func (it *Iterator[K, V]) Each(fn func(K, V) bool) {
+	it.each(fn)
+}
+
+func (it *Iterator[K, V]) Collect() []KVPair[K, V] {
+	var pairs []KVPair[K, V]
+	it.each(func(key K, value V) bool {
+		pairs = append(pairs, KVPair[K, V]{Key: key, Value: value})
+		return true
+	})
+	return pairs
+}
+
+func (it *Iterator[K, V]) Keys() []K {
+	var keys []K
+	it.each(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func (it *Iterator[K, V]) Values() []V {
+	var values []V
+	it.each(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+func (it *Iterator[K, V]) Count() int {
+	count := 0
+	it.each(func(K, V) bool {
+		count++
+		return true
+	})
+	return count
+}
@@ -0,0 +1,20 @@
+package chainedmap
+
+import "hashmaps/internal/mapjson"
+
+// MarshalJSON writes the map as internal/mapjson describes, the pairs of
+// non-string keys come bucket by bucket
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mapjson.Marshal(m.Range, m.Len())
+}
+
+// UnmarshalJSON adds the decoded entries through TrySet, so a map made
+// WithHasher hashes them with its Hasher and one with RejectNaN fails on a
+// NaN key. A zero HashMap, e.g. one that json.Unmarshal allocated, is made
+// with MakeHashMap's defaults first.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	if m.capacity == 0 {
+		*m = *MakeHashMap[K, V]()
+	}
+	return mapjson.Unmarshal(data, m.TrySet)
+}
@@ -0,0 +1,177 @@
+package chainedmap
+
+import (
+	"iter"
+
+	"hashmaps/list"
+)
+
+// LinkedHashMap is a HashMap that remembers the order of its entries, like
+// Java's LinkedHashMap or a Python dict. Every entry is also linked into a
+// list.List, Range and All walk that list instead of the buckets.
+//
+// By default the order is insertion order, setting an existing key keeps
+// its place. In access order every Get and Set moves the entry to the back,
+// so the front is always the least recently used entry, which is what an
+// LRU cache evicts.
+type LinkedHashMap[K comparable, V any] struct {
+	index       *HashMap[K, *list.Element[linkedEntry[K, V]]]
+	order       *list.List[linkedEntry[K, V]] // the front is the oldest entry
+	accessOrder bool
+}
+
+type linkedEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func MakeLinkedHashMap[K comparable, V any]() *LinkedHashMap[K, V] {
+	return &LinkedHashMap[K, V]{
+		index: MakeHashMap[K, *list.Element[linkedEntry[K, V]]](),
+		order: list.MakeList[linkedEntry[K, V]](),
+	}
+}
+
+// MakeLinkedHashMapWithAccessOrder orders the entries by last access instead of insertion
+func MakeLinkedHashMapWithAccessOrder[K comparable, V any]() *LinkedHashMap[K, V] {
+	l := MakeLinkedHashMap[K, V]()
+	l.accessOrder = true
+	return l
+}
+
+// Get moves the entry to the back in access order, Peek never does
+func (l *LinkedHashMap[K, V]) Get(key K) *V {
+	e := l.lookupEntry(key)
+	if e == nil {
+		return nil
+	}
+	if l.accessOrder {
+		l.order.MoveToBack(e)
+	}
+	return &e.Value.value
+}
+
+func (l *LinkedHashMap[K, V]) Peek(key K) *V {
+	if e := l.lookupEntry(key); e != nil {
+		return &e.Value.value
+	}
+	return nil
+}
+
+// Set appends new keys at the back. Like HashMap.Set it panics when the key
+// is rejected by the NaNPolicy or can't be hashed.
+func (l *LinkedHashMap[K, V]) Set(key K, value V) {
+	key, err := l.index.norm.Normalize(key)
+	if err != nil {
+		panic(err)
+	}
+	e, loaded := l.index.getOrInsert(key, func() *list.Element[linkedEntry[K, V]] {
+		return l.order.PushBack(linkedEntry[K, V]{key: key})
+	})
+	e.Value.value = value
+	if loaded && l.accessOrder {
+		l.order.MoveToBack(e)
+	}
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (l *LinkedHashMap[K, V]) Remove(key K) {
+	l.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (l *LinkedHashMap[K, V]) Delete(key K) (V, bool) {
+	e, ok := l.index.Delete(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return l.order.Remove(e).value, true
+}
+
+// Pop is Delete, the entry leaves the order wherever it is in it
+func (l *LinkedHashMap[K, V]) Pop(key K) (V, bool) {
+	return l.Delete(key)
+}
+
+// Len returns the number of entries, in O(1)
+func (l *LinkedHashMap[K, V]) Len() int {
+	return l.index.Len()
+}
+
+// Oldest returns the entry at the front: the first inserted, or in access
+// order the least recently used. ok is false when the map is empty.
+func (l *LinkedHashMap[K, V]) Oldest() (key K, value V, ok bool) {
+	if e := l.order.Front(); e != nil {
+		return e.Value.key, e.Value.value, true
+	}
+	return key, value, false
+}
+
+// Newest returns the entry at the back, ok is false when the map is empty
+func (l *LinkedHashMap[K, V]) Newest() (key K, value V, ok bool) {
+	if e := l.order.Back(); e != nil {
+		return e.Value.key, e.Value.value, true
+	}
+	return key, value, false
+}
+
+// MoveToBack makes key the newest entry whatever the order,
+// it reports whether key was there
+func (l *LinkedHashMap[K, V]) MoveToBack(key K) bool {
+	e := l.lookupEntry(key)
+	if e == nil {
+		return false
+	}
+	l.order.MoveToBack(e)
+	return true
+}
+
+// Range calls fn for every entry from the oldest to the newest until fn
+// returns false. It doesn't count as an access. The map must not be modified by fn.
+func (l *LinkedHashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for e := range l.order.All() {
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range
+func (l *LinkedHashMap[K, V]) All() iter.Seq2[K, V] {
+	return l.Range
+}
+
+func (l *LinkedHashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		l.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (l *LinkedHashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		l.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// Backward walks from the newest entry to the oldest
+func (l *LinkedHashMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range l.order.Backward() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func (l *LinkedHashMap[K, V]) lookupEntry(key K) *list.Element[linkedEntry[K, V]] {
+	if e := l.index.Get(key); e != nil {
+		return *e
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package chainedmap
+
+// Merge copies every entry of other into m, values from other win on conflicts.
+func (m *HashMap[K, V]) Merge(other *HashMap[K, V]) {
+	m.MergeFunc(other, func(_, newValue V) V { return newValue })
+}
+
+// MergeFunc is Merge where resolve picks the value for keys present in both
+// maps. Every key of other is looked up once, through Entry. The table is
+// grown up front only for the larger of the two maps, which the merged map
+// is at least as big as. Beyond that it grows as keys come in, since other
+// may share most of its keys with m.
+func (m *HashMap[K, V]) MergeFunc(other *HashMap[K, V], resolve func(old, new V) V) {
+	m.reserve(max(m.length, other.length))
+	other.Range(func(key K, value V) bool {
+		e := m.Entry(key)
+		if e.pair != nil {
+			e.pair.Value = resolve(e.pair.Value, value)
+		} else {
+			e.insert(value)
+		}
+		return true
+	})
+}
@@ -0,0 +1,47 @@
+package chainedmap
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMergeFunc(t *testing.T) {
+	m, other := MakeHashMap[string, int](), MakeHashMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	other.Set("b", 10)
+	other.Set("c", 20)
+	calls := 0
+	m.MergeFunc(other, func(old, new int) int {
+		calls++
+		return old + new
+	})
+	if want := map[string]int{"a": 1, "b": 12, "c": 20}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("MergeFunc = %v, want %v", m.ToMap(), want)
+	}
+	if calls != 1 {
+		t.Fatalf("resolve called %d times, want 1", calls)
+	}
+}
+
+func TestMergeSharedKeysDoesntGrow(t *testing.T) {
+	m := filledMap(1000)
+	capacity := m.capacity
+	m.Merge(m.Clone())
+	if m.capacity != capacity || m.Len() != 1000 {
+		t.Fatalf("capacity %d, Len %d after merging a copy, want %d, 1000", m.capacity, m.Len(), capacity)
+	}
+	m.Merge(m)
+	if m.Len() != 1000 {
+		t.Fatalf("Len %d after merging m into itself, want 1000", m.Len())
+	}
+}
+
+func TestMergeIntoEmptyGrowsOnce(t *testing.T) {
+	other := filledMap(1000)
+	m := MakeHashMap[int, int]()
+	m.Merge(other)
+	if m.rehashes != 1 || m.capacity != other.capacity {
+		t.Fatalf("rehashes %d, capacity %d, want 1, %d", m.rehashes, m.capacity, other.capacity)
+	}
+}
@@ -0,0 +1,247 @@
+//go:build !tinygo && !lighthash
+
+package chainedmap
+
+import (
+	bytes2 "bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"sort"
+)
+
+// Merkle tree over the map contents, so two replicas can compare a single
+// root hash instead of all entries, and narrow differences down to ranges.
+//
+// Entries are ordered canonically by the SHA-256 of their gob encoded key,
+// which doesn't depend on capacity or insertion order. The same digest space
+// is split into 2^depth equal ranges by MerkleRangeHashes, so ranges line up
+// between replicas no matter what they contain.
+// Values must gob encode deterministically, e.g. values containing maps don't.
+// Keys or values that can't be gob encoded at all are reported as errors.
+
+const (
+	merkleLeafPrefix = 0x00 // domain separation, a leaf can't pass for an inner node
+	merkleNodePrefix = 0x01
+)
+
+type merkleLeaf struct {
+	keyDigest [32]byte
+	hash      [32]byte
+}
+
+type MerkleSibling struct {
+	Hash [32]byte
+	Left bool // sibling is on the left of the path
+}
+
+type MerkleProof struct {
+	Index    int // position of the entry in the canonical order
+	Siblings []MerkleSibling
+}
+
+func gobEncode(value any) ([]byte, error) {
+	var buffer bytes2.Buffer
+	encoder := gob.NewEncoder(&buffer)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func gobDigest(value any) ([32]byte, error) {
+	encoded, err := gobEncode(value)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(encoded), nil
+}
+
+func merkleLeafHash(keyDigest [32]byte, value any) ([32]byte, error) {
+	valueDigest, err := gobDigest(value)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	data := make([]byte, 0, 1+2*sha256.Size)
+	data = append(data, merkleLeafPrefix)
+	data = append(data, keyDigest[:]...)
+	data = append(data, valueDigest[:]...)
+	return sha256.Sum256(data), nil
+}
+
+func merkleNodeHash(left, right [32]byte) [32]byte {
+	data := make([]byte, 0, 1+2*sha256.Size)
+	data = append(data, merkleNodePrefix)
+	data = append(data, left[:]...)
+	data = append(data, right[:]...)
+	return sha256.Sum256(data)
+}
+
+func (m *HashMap[K, V]) merkleLeaves() ([]merkleLeaf, error) {
+	var leaves []merkleLeaf
+	var err error
+	m.Iter().Each(func(key K, value V) bool {
+		var keyDigest, leafHash [32]byte
+		if keyDigest, err = gobDigest(key); err != nil {
+			return false
+		}
+		if leafHash, err = merkleLeafHash(keyDigest, value); err != nil {
+			return false
+		}
+		leaves = append(leaves, merkleLeaf{keyDigest: keyDigest, hash: leafHash})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes2.Compare(leaves[i].keyDigest[:], leaves[j].keyDigest[:]) < 0
+	})
+	return leaves, nil
+}
+
+// merkleLevels builds the tree bottom up, a node without a sibling is carried
+// to the next level unchanged rather than paired with a copy of itself
+func merkleLevels(leaves []merkleLeaf) [][][32]byte {
+	level := make([][32]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = leaf.hash
+	}
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, merkleNodeHash(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+func merkleRootOf(leaves []merkleLeaf) [32]byte {
+	if len(leaves) == 0 {
+		return sha256.Sum256(nil)
+	}
+	levels := merkleLevels(leaves)
+	return levels[len(levels)-1][0]
+}
+
+// MerkleRoot is equal for two maps exactly when they hold the same entries
+func (m *HashMap[K, V]) MerkleRoot() ([32]byte, error) {
+	leaves, err := m.merkleLeaves()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return merkleRootOf(leaves), nil
+}
+
+// MerkleProof proves that key is in the map with its current value,
+// anyone with the root can check it with VerifyMerkleProof
+func (m *HashMap[K, V]) MerkleProof(key K) (MerkleProof, bool, error) {
+	keyDigest, err := gobDigest(key)
+	if err != nil {
+		return MerkleProof{}, false, err
+	}
+	leaves, err := m.merkleLeaves()
+	if err != nil {
+		return MerkleProof{}, false, err
+	}
+	index := sort.Search(len(leaves), func(i int) bool {
+		return bytes2.Compare(leaves[i].keyDigest[:], keyDigest[:]) >= 0
+	})
+	if index == len(leaves) || leaves[index].keyDigest != keyDigest {
+		return MerkleProof{}, false, nil
+	}
+	proof := MerkleProof{Index: index}
+	position := index
+	levels := merkleLevels(leaves)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := position ^ 1
+		if sibling < len(level) {
+			proof.Siblings = append(proof.Siblings, MerkleSibling{Hash: level[sibling], Left: sibling < position})
+		}
+		position /= 2
+	}
+	return proof, true, nil
+}
+
+// VerifyMerkleProof is false as well when key or value can't be encoded
+func VerifyMerkleProof[K comparable, V any](root [32]byte, key K, value V, proof MerkleProof) bool {
+	keyDigest, err := gobDigest(key)
+	if err != nil {
+		return false
+	}
+	hash, err := merkleLeafHash(keyDigest, value)
+	if err != nil {
+		return false
+	}
+	for _, sibling := range proof.Siblings {
+		if sibling.Left {
+			hash = merkleNodeHash(sibling.Hash, hash)
+		} else {
+			hash = merkleNodeHash(hash, sibling.Hash)
+		}
+	}
+	return hash == root
+}
+
+// MerkleRangeHashes splits the key digest space into 2^depth ranges and returns
+// the Merkle root of each. Replicas exchange these and only need to sync
+// the ranges whose roots differ, see MerkleRange. depth is capped at 16.
+func (m *HashMap[K, V]) MerkleRangeHashes(depth int) ([][32]byte, error) {
+	depth = clampMerkleDepth(depth)
+	leaves, err := m.merkleLeaves()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([][32]byte, 1<<depth)
+	start := 0
+	for r := range hashes {
+		end := start
+		for end < len(leaves) && merkleRangeOf(leaves[end].keyDigest, depth) == r {
+			end++
+		}
+		hashes[r] = merkleRootOf(leaves[start:end])
+		start = end
+	}
+	return hashes, nil
+}
+
+// MerkleRange returns the entries of one range produced by MerkleRangeHashes
+func (m *HashMap[K, V]) MerkleRange(depth int, rangeIndex int) ([]KVPair[K, V], error) {
+	depth = clampMerkleDepth(depth)
+	var entries []KVPair[K, V]
+	var err error
+	m.Iter().Each(func(key K, value V) bool {
+		var keyDigest [32]byte
+		if keyDigest, err = gobDigest(key); err != nil {
+			return false
+		}
+		if merkleRangeOf(keyDigest, depth) == rangeIndex {
+			entries = append(entries, KVPair[K, V]{Key: key, Value: value})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func clampMerkleDepth(depth int) int {
+	if depth < 0 {
+		return 0
+	}
+	if depth > 16 {
+		return 16
+	}
+	return depth
+}
+
+// merkleRangeOf is the top depth bits of the key digest
+func merkleRangeOf(keyDigest [32]byte, depth int) int {
+	return int(uint32(keyDigest[0])<<8|uint32(keyDigest[1])) >> (16 - depth)
+}
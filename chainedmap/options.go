@@ -0,0 +1,146 @@
+package chainedmap
+
+import (
+	"errors"
+	"fmt"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+var (
+	ErrInvalidCapacity = errors.New("capacity must not be negative")
+	ErrHasherType      = errors.New("hasher is for another key type")
+	ErrComparerType    = errors.New("comparer is for another value type")
+	ErrHooksType       = errors.New("hooks are for another key or value type")
+	ErrHooksOnHashMap  = errors.New("hooks need a HookedHashMap, a HashMap can't see writes through the pointers it returns")
+)
+
+// Option configures a map made by MakeHashMap or TryMakeHashMap, e.g.
+//
+//	m := chainedmap.MakeHashMap[string, int](chainedmap.WithCapacity(1_000_000), chainedmap.WithLoadFactor(1))
+type Option func(*config)
+
+type config struct {
+	capacity      int
+	maxLoadFactor float64
+	nanPolicy     NaNPolicy
+	hasher        any // a Hasher[K], checked against K by TryMakeHashMap
+	comparer      any // a Comparer[V], checked against V by TryMakeHashMap
+	hooks         any // a Hooks[K, V], checked by TryMakeHookedHashMap
+	seed          *Seed
+}
+
+// WithCapacity sizes the table so n entries fit without growing, for bulk
+// loads. Deletes never shrink the table below that size. TryMakeHashMap
+// returns ErrCapacityOverflow when n entries don't fit into maxCapacity
+// buckets at the load factor.
+func WithCapacity(n int) Option {
+	return func(c *config) { c.capacity = n }
+}
+
+// WithLoadFactor is what MakeHashMapWithLoadFactor sets
+func WithLoadFactor(maxLoadFactor float64) Option {
+	return func(c *config) { c.maxLoadFactor = maxLoadFactor }
+}
+
+// NaNPolicy is what WithNaNPolicy and MakeHashMapWithNaNPolicy take, it is
+// internal/keynorm's, so the other map packages take the same values.
+// GobEncode keeps it with the entries.
+type NaNPolicy = keynorm.NaNPolicy
+
+const (
+	CanonicalizeNaN = keynorm.CanonicalizeNaN
+	RejectNaN       = keynorm.RejectNaN
+)
+
+var (
+	ErrNaNKey           = keynorm.ErrNaNKey
+	ErrInvalidNaNPolicy = keynorm.ErrInvalidNaNPolicy
+)
+
+// WithNaNPolicy is what MakeHashMapWithNaNPolicy sets
+func WithNaNPolicy(nanPolicy NaNPolicy) Option {
+	return func(c *config) { c.nanPolicy = nanPolicy }
+}
+
+// WithHasher is what MakeHashMapWithHasher sets. The key type of hasher
+// has to be the key type of the map.
+func WithHasher[K comparable](hasher Hasher[K]) Option {
+	return func(c *config) { c.hasher = hasher }
+}
+
+// Seed is the state of the built-in hash. Every map gets a random one,
+// maps made WithSeed of the same Seed hash equal keys the same way, e.g. so
+// a test sees the same bucket layout in every map. Seeds are random per
+// process, nothing is stable across runs. In builds with the tinygo or
+// lighthash tag a Seed salts lighthash.
+type Seed struct {
+	seed hashing.Seed
+}
+
+func MakeSeed() Seed {
+	return Seed{seed: hashing.MakeSeed[any]()}
+}
+
+func WithSeed(seed Seed) Option {
+	return func(c *config) { c.seed = &seed }
+}
+
+// MakeHashMap panics when an option is invalid, TryMakeHashMap returns the error
+func MakeHashMap[K comparable, V any](opts ...Option) *HashMap[K, V] {
+	m, err := TryMakeHashMap[K, V](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// TryMakeHashMap returns ErrHooksOnHashMap for WithHooks, see MakeHookedHashMap
+func TryMakeHashMap[K comparable, V any](opts ...Option) (*HashMap[K, V], error) {
+	c := makeConfig(opts)
+	if c.hooks != nil {
+		return nil, ErrHooksOnHashMap
+	}
+	return makeFromConfig[K, V](c)
+}
+
+func makeConfig(opts []Option) config {
+	c := config{maxLoadFactor: defaultMaxLoadFactor, nanPolicy: CanonicalizeNaN}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func makeFromConfig[K comparable, V any](c config) (*HashMap[K, V], error) {
+	if c.capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	m, err := makeHashMap[K, V](c.nanPolicy, c.maxLoadFactor)
+	if err != nil {
+		return nil, err
+	}
+	if c.hasher != nil {
+		hasher, ok := c.hasher.(Hasher[K])
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrHasherType, c.hasher)
+		}
+		m.hasher = hasher
+	}
+	if c.comparer != nil {
+		comparer, ok := c.comparer.(Comparer[V])
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrComparerType, c.comparer)
+		}
+		m.comparer = comparer
+	}
+	if c.seed != nil {
+		m.seed = hashing.For[K](c.seed.seed)
+	}
+	if err := m.reserve(c.capacity); err != nil {
+		return nil, fmt.Errorf("%w: capacity %d", err, c.capacity)
+	}
+	m.minCapacity = m.capacity
+	return m, nil
+}
@@ -0,0 +1,32 @@
+package chainedmap
+
+import "testing"
+
+type popper interface {
+	Set(key string, value int)
+	Pop(key string) (int, bool)
+	Len() int
+}
+
+func TestPop(t *testing.T) {
+	maps := map[string]popper{
+		"HashMap":       MakeHashMap[string, int](),
+		"SafeHashMap":   MakeSafeHashMap[string, int](),
+		"ShardedMap":    MakeShardedMap[string, int](),
+		"LinkedHashMap": MakeLinkedHashMap[string, int](),
+		"HookedHashMap": MakeHookedHashMap[string, int](),
+	}
+	for name, m := range maps {
+		m.Set("a", 1)
+		m.Set("b", 2)
+		if value, ok := m.Pop("a"); !ok || value != 1 {
+			t.Fatalf("%s: Pop(a) = %d, %v, want 1, true", name, value, ok)
+		}
+		if _, ok := m.Pop("a"); ok {
+			t.Fatalf("%s: second Pop(a) found the key", name)
+		}
+		if m.Len() != 1 {
+			t.Fatalf("%s: Len = %d, want 1", name, m.Len())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package chainedmap
+
+import (
+	"iter"
+	"sync"
+)
+
+// SafeHashMap is a HashMap guarded by a sync.RWMutex, safe for concurrent use.
+// Get returns a copy of the value instead of a pointer into the map,
+// a pointer would outlive the lock.
+type SafeHashMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  *HashMap[K, V]
+}
+
+func MakeSafeHashMap[K comparable, V any]() *SafeHashMap[K, V] {
+	return &SafeHashMap[K, V]{m: MakeHashMap[K, V]()}
+}
+
+func (s *SafeHashMap[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if value := s.m.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (s *SafeHashMap[K, V]) Set(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Set(key, value)
+}
+
+func (s *SafeHashMap[K, V]) Delete(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Delete(key)
+}
+
+// Pop is Delete, reading and removing under one write lock, so of
+// concurrent Pops of a key only one gets the value
+func (s *SafeHashMap[K, V]) Pop(key K) (V, bool) {
+	return s.Delete(key)
+}
+
+// Upsert is HashMap.Upsert under the write lock, so concurrent Upserts of
+// one key all count. merge must not call methods of s.
+func (s *SafeHashMap[K, V]) Upsert(key K, value V, merge func(old, new V) V) V {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.Upsert(key, value, merge)
+}
+
+// CompareAndSwap, CompareAndDelete and their Func forms are the HashMap
+// methods under the write lock. equal must not call methods of s.
+func (s *SafeHashMap[K, V]) CompareAndSwap(key K, old, new V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.CompareAndSwap(key, old, new)
+}
+
+func (s *SafeHashMap[K, V]) CompareAndSwapFunc(key K, old, new V, equal func(a, b V) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.CompareAndSwapFunc(key, old, new, equal)
+}
+
+func (s *SafeHashMap[K, V]) CompareAndDelete(key K, old V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.CompareAndDelete(key, old)
+}
+
+func (s *SafeHashMap[K, V]) CompareAndDeleteFunc(key K, old V, equal func(a, b V) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.CompareAndDeleteFunc(key, old, equal)
+}
+
+func (s *SafeHashMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Len()
+}
+
+// Range holds the read lock for the whole iteration. fn must not call
+// methods of s: writes deadlock right away, and a second read lock
+// deadlocks once a writer is waiting.
+func (s *SafeHashMap[K, V]) Range(fn func(key K, value V) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.m.Range(fn)
+}
+
+// All is the range-over-func form of Range, with the same locking:
+// the loop body must not call methods of s.
+func (s *SafeHashMap[K, V]) All() iter.Seq2[K, V] {
+	return s.Range
+}
@@ -0,0 +1,35 @@
+package chainedmap
+
+import "iter"
+
+// All, Keys and Values are the range-over-func forms of Range:
+//
+//	for key, value := range m.All() { ... }
+//
+// The map must not be modified during the loop.
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// RangeStable is Range over a Snapshot, fn may modify the map. Every entry
+// present when it starts is passed exactly once, with the value it had
+// then; entries set by fn are not passed, deleted ones still are.
+func (m *HashMap[K, V]) RangeStable(fn func(key K, value V) bool) {
+	m.Snapshot().Range(fn)
+}
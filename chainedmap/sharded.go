@@ -0,0 +1,197 @@
+package chainedmap
+
+import (
+	"errors"
+	"iter"
+	"runtime"
+	"sync"
+
+	"hashmaps/internal/hashing"
+)
+
+// ShardedMap spreads keys over independently locked HashMaps, so writers
+// to different shards don't wait for each other the way they do on a
+// single SafeHashMap. Like SafeHashMap, Get returns a copy of the value.
+type ShardedMap[K comparable, V any] struct {
+	seed   hashing.Seed // picks the shard, see internal/hashing
+	shards []shard[K, V]
+}
+
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  *HashMap[K, V]
+	_  [32]byte // keeps neighbouring locks out of one cache line
+}
+
+var ErrInvalidShardCount = errors.New("shard count must be positive")
+
+// MakeShardedMap uses 4 shards per GOMAXPROCS
+func MakeShardedMap[K comparable, V any]() *ShardedMap[K, V] {
+	s, _ := MakeShardedMapWithShards[K, V](4 * runtime.GOMAXPROCS(0)) // always positive
+	return s
+}
+
+func MakeShardedMapWithShards[K comparable, V any](shards int) (*ShardedMap[K, V], error) {
+	if shards < 1 {
+		return nil, ErrInvalidShardCount
+	}
+	s := &ShardedMap[K, V]{seed: hashing.MakeSeed[K](), shards: make([]shard[K, V], shards)}
+	for i := range s.shards {
+		s.shards[i].m = MakeHashMap[K, V]()
+	}
+	return s, nil
+}
+
+// shardFor panics like HashMap.Set when key can't be hashed. The shard
+// comes from the high 32 bits of the hash, the buckets inside the shard
+// from the low bits, so the keys of one shard still use all its buckets
+// even when both hashes are the same.
+func (s *ShardedMap[K, V]) shardFor(key K) *shard[K, V] {
+	key, err := s.shards[0].m.norm.Normalize(key) // every NaN has to go to the same shard
+	if err != nil {
+		panic(err)
+	}
+	hashedKey, err := hashing.Hash(s.seed, key)
+	if err != nil {
+		panic(err)
+	}
+	return &s.shards[(hashedKey>>32)*uint64(len(s.shards))>>32]
+}
+
+func (s *ShardedMap[K, V]) Get(key K) (V, bool) {
+	sh := s.shardFor(key)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	if value := sh.m.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (s *ShardedMap[K, V]) Set(key K, value V) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.m.Set(key, value)
+}
+
+func (s *ShardedMap[K, V]) Delete(key K) (V, bool) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.m.Delete(key)
+}
+
+// Pop is Delete under the lock of the key's shard, so of concurrent Pops
+// of a key only one gets the value
+func (s *ShardedMap[K, V]) Pop(key K) (V, bool) {
+	return s.Delete(key)
+}
+
+// Upsert is HashMap.Upsert under the lock of the key's shard.
+// merge must not call methods of s.
+func (s *ShardedMap[K, V]) Upsert(key K, value V, merge func(old, new V) V) V {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.m.Upsert(key, value, merge)
+}
+
+// Update calls fn with a pointer to the value of key under the lock of the
+// key's shard and returns what fn returns, so a read-modify-write can't
+// race another writer. A missing key is inserted with the result of create
+// first, or when create is nil fn isn't called and Update returns false.
+// fn and create must not call methods of s, and fn must not keep the pointer.
+func (s *ShardedMap[K, V]) Update(key K, create func() V, fn func(value *V) bool) bool {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	e := sh.m.Entry(key)
+	if e.pair != nil {
+		return fn(&e.pair.Value)
+	}
+	if create == nil {
+		return false
+	}
+	return fn(e.insert(create()))
+}
+
+// CompareAndSwap, CompareAndDelete and their Func forms are the HashMap
+// methods under the lock of the key's shard. equal must not call methods of s.
+func (s *ShardedMap[K, V]) CompareAndSwap(key K, old, new V) bool {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.m.CompareAndSwap(key, old, new)
+}
+
+func (s *ShardedMap[K, V]) CompareAndSwapFunc(key K, old, new V, equal func(a, b V) bool) bool {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.m.CompareAndSwapFunc(key, old, new, equal)
+}
+
+func (s *ShardedMap[K, V]) CompareAndDelete(key K, old V) bool {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.m.CompareAndDelete(key, old)
+}
+
+func (s *ShardedMap[K, V]) CompareAndDeleteFunc(key K, old V, equal func(a, b V) bool) bool {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.m.CompareAndDeleteFunc(key, old, equal)
+}
+
+// Len adds up the shards one at a time, with concurrent writers the
+// result may not match any single moment
+func (s *ShardedMap[K, V]) Len() int {
+	length := 0
+	for i := range s.shards {
+		s.shards[i].mu.RLock()
+		length += s.shards[i].m.Len()
+		s.shards[i].mu.RUnlock()
+	}
+	return length
+}
+
+// DeleteFunc is HashMap.DeleteFunc on one shard after another, under the
+// write lock of the current one. pred must not call methods of s.
+func (s *ShardedMap[K, V]) DeleteFunc(pred func(key K, value V) bool) int {
+	deleted := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		deleted += sh.m.DeleteFunc(pred)
+		sh.mu.Unlock()
+	}
+	return deleted
+}
+
+// Range visits the shards one after another, holding only the read lock of
+// the current one. Like Len it is not a snapshot of the whole map.
+// fn must not call methods of s.
+func (s *ShardedMap[K, V]) Range(fn func(key K, value V) bool) {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		keepGoing := true
+		sh.m.Range(func(key K, value V) bool {
+			keepGoing = fn(key, value)
+			return keepGoing
+		})
+		sh.mu.RUnlock()
+		if !keepGoing {
+			return
+		}
+	}
+}
+
+// All is the range-over-func form of Range
+func (s *ShardedMap[K, V]) All() iter.Seq2[K, V] {
+	return s.Range
+}
@@ -0,0 +1,57 @@
+package chainedmap
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedUpdate(t *testing.T) {
+	s, err := MakeShardedMapWithShards[int, int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	increment := func(value *int) bool {
+		*value++
+		return true
+	}
+	if s.Update(1, nil, increment) {
+		t.Fatalf("Update of a missing key without create = true, want false")
+	}
+	if _, ok := s.Get(1); ok {
+		t.Fatalf("Update without create inserted the key")
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s.Update(i%10, func() int { return 0 }, increment)
+			}
+		}()
+	}
+	wg.Wait()
+	for key := 0; key < 10; key++ {
+		if value, _ := s.Get(key); value != 800 {
+			t.Fatalf("Get(%d) = %d after 800 concurrent increments", key, value)
+		}
+	}
+}
+
+func TestShardedDeleteFunc(t *testing.T) {
+	s, err := MakeShardedMapWithShards[int, int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		s.Set(i, i)
+	}
+	if deleted := s.DeleteFunc(func(key, _ int) bool { return key%2 == 0 }); deleted != 50 {
+		t.Fatalf("DeleteFunc deleted %d, want 50", deleted)
+	}
+	for i := 0; i < 100; i++ {
+		if _, ok := s.Get(i); ok != (i%2 == 1) {
+			t.Fatalf("Get(%d) found = %v after deleting the even keys", i, ok)
+		}
+	}
+}
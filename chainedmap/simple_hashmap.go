@@ -0,0 +1,312 @@
+// Package chainedmap is a hashmap with separate chaining, colliding entries
+// are kept in a linked list per bucket.
+package chainedmap
+
+import (
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+type KVPair[K comparable, V any] struct {
+	Key   K
+	Value V
+	Next  *KVPair[K, V]
+}
+
+// This is the simple, but more sophisticated hashmap implementation
+// In case of hash collision buckets form a linked-list.
+
+type HashMap[K comparable, V any] struct {
+	capacity    int64
+	minCapacity int64             // deletes don't shrink the table below this, see WithCapacity
+	buckets     bucketTable[K, V] // see buckets.go
+
+	length        int     // number of entries, maintained by Set and Remove
+	maxLoadFactor float64 // the table doubles once length/capacity would go beyond this, see growth.go
+	rehashes      int     // tables built by resize, see Stats
+
+	hasher Hasher[K]    // nil means the built-in hash, see hasher.go
+	seed   hashing.Seed // for the built-in hash, see internal/hashing
+
+	comparer Comparer[V] // for Equal and CompareAndSwap, see comparer.go
+
+	norm keynorm.Normalizer[K] // float keys and the NaNPolicy, see internal/keynorm
+}
+
+// Get, Set, Delete and Remove panic when the key can't be hashed, e.g. a gob encoding failure.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *HashMap[K, V]) Get(key K) *V {
+	value, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (m *HashMap[K, V]) TryGet(key K) (*V, error) {
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return nil, nil
+	}
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		return nil, err
+	}
+	m.buckets.own(hashedKey) // the caller may write through the pointer
+	for pointer := m.buckets.head(hashedKey); pointer != nil; pointer = pointer.Next {
+		if m.norm.Equal(pointer.Key, key) {
+			return &pointer.Value, nil
+		}
+	}
+	return nil, nil
+}
+
+// Set also panics when the key is rejected by the NaNPolicy
+func (m *HashMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *HashMap[K, V]) TrySet(key K, value V) error {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		return err
+	}
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		return err
+	}
+	m.insertAt(hashedKey, key, value)
+	return nil
+}
+
+// Len returns the number of entries, in O(1)
+func (m *HashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, like sync.Map.Range.
+// The map must not be modified by fn, a Set that grows the table makes Range
+// skip or repeat entries. RangeStable allows it.
+func (m *HashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for _, segment := range m.buckets.segments {
+		for _, bucket := range segment {
+			for pair := bucket; pair != nil; pair = pair.Next {
+				if !fn(pair.Key, pair.Value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (m *HashMap[K, V]) insertAt(hashedKey int, key K, value V) {
+	m.buckets.own(hashedKey)
+	var last *KVPair[K, V]
+	for pointer := m.buckets.head(hashedKey); pointer != nil; pointer = pointer.Next {
+		if m.norm.Equal(pointer.Key, key) { // in place update of value
+			pointer.Value = value
+			return
+		}
+		last = pointer
+	}
+	m.appendPair(hashedKey, last, &KVPair[K, V]{Key: key, Value: value})
+}
+
+// appendPair links a new pair after last, the tail of its bucket (nil when
+// the bucket is empty), and grows the table when the load factor is exceeded
+func (m *HashMap[K, V]) appendPair(hashedKey int, last, pair *KVPair[K, V]) {
+	if last == nil {
+		m.buckets.setHead(hashedKey, pair)
+	} else {
+		last.Next = pair
+	}
+	m.length++
+	m.growIfNeeded()
+}
+
+func (m *HashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *HashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+// Pop is Delete under the name other map libraries use for get-and-remove
+func (m *HashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+func (m *HashMap[K, V]) TryDelete(key K) (V, bool, error) {
+	var value V
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return value, false, nil
+	}
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		return value, false, err
+	}
+	value, ok := m.removeAt(hashedKey, key, nil)
+	if ok {
+		m.shrinkIfNeeded()
+	}
+	return value, ok, nil
+}
+
+// removeAt unlinks key from its bucket, leaving the table size alone.
+// A non-nil match has to accept the value too.
+func (m *HashMap[K, V]) removeAt(hashedKey int, key K, match func(value V) bool) (V, bool) {
+	var value V
+	m.buckets.own(hashedKey)
+	head := m.buckets.head(hashedKey)
+	if head == nil {
+		return value, false
+	}
+	if m.norm.Equal(head.Key, key) { // key is in HEAD
+		if match != nil && !match(head.Value) {
+			return value, false
+		}
+		m.buckets.setHead(hashedKey, head.Next)
+		m.length--
+		return head.Value, true
+	}
+	prev := head
+	curr := head.Next
+	for curr != nil {
+		if m.norm.Equal(curr.Key, key) {
+			if match != nil && !match(curr.Value) {
+				return value, false
+			}
+			prev.Next = curr.Next
+			m.length--
+			return curr.Value, true
+		}
+		prev = prev.Next
+		curr = curr.Next
+	}
+	return value, false
+}
+
+// Clear removes all entries but keeps the bucket table, so refilling the map
+// to a similar size doesn't have to grow it again. It returns the bytes
+// the removed nodes took, see releasedBytes.
+func (m *HashMap[K, V]) Clear() int {
+	released := releasedBytes[K, V](0, m.length)
+	m.length = 0
+	if m.buckets.shared != nil {
+		m.buckets = makeBucketTable[K, V](m.buckets.len())
+		return released
+	}
+	for _, segment := range m.buckets.segments {
+		for i := range segment {
+			segment[i] = nil
+		}
+	}
+	return released
+}
+
+// DeleteFunc removes every entry pred returns true for in one pass over the
+// buckets and returns how many it removed, unlike deleting inside Range
+// it can't skip or repeat entries. The table is shrunk once at the end.
+// pred must not modify the map.
+func (m *HashMap[K, V]) DeleteFunc(pred func(key K, value V) bool) int {
+	deleted := 0
+	for i := 0; i < m.buckets.len(); i++ {
+		if m.buckets.head(i) == nil {
+			continue
+		}
+		m.buckets.own(i)
+		var prev *KVPair[K, V]
+		for pair := m.buckets.head(i); pair != nil; pair = pair.Next {
+			if !pred(pair.Key, pair.Value) {
+				prev = pair
+				continue
+			}
+			if prev == nil {
+				m.buckets.setHead(i, pair.Next)
+			} else {
+				prev.Next = pair.Next
+			}
+			deleted++
+		}
+	}
+	m.length -= deleted
+	m.resizeFor(m.length)
+	return deleted
+}
+
+// ClearAndShrink removes all entries and goes back to the initial capacity.
+// It returns the bytes the removed nodes and buckets took, see releasedBytes.
+func (m *HashMap[K, V]) ClearAndShrink() int {
+	released := releasedBytes[K, V](m.capacity-m.minCapacity, m.length)
+	m.capacity = m.minCapacity
+	m.buckets = makeBucketTable[K, V](int(m.minCapacity))
+	m.length = 0
+	return released
+}
+
+// Clone returns an independent copy: the bucket table and every node are
+// duplicated, so changes to one map never show up in the other.
+// Keys and values themselves are copied by assignment.
+func (m *HashMap[K, V]) Clone() *HashMap[K, V] {
+	clone := *m
+	clone.buckets = makeBucketTable[K, V](m.buckets.len())
+	for i := 0; i < m.buckets.len(); i++ {
+		var last *KVPair[K, V]
+		for pair := m.buckets.head(i); pair != nil; pair = pair.Next {
+			copied := &KVPair[K, V]{Key: pair.Key, Value: pair.Value}
+			if last == nil {
+				clone.buckets.setHead(i, copied)
+			} else {
+				last.Next = copied
+			}
+			last = copied
+		}
+	}
+	return &clone
+}
+
+// defaultCapacity is what new maps start with unless made WithCapacity
+const defaultCapacity = 4
+
+func MakeHashMapWithNaNPolicy[K comparable, V any](nanPolicy NaNPolicy) (*HashMap[K, V], error) {
+	return makeHashMap[K, V](nanPolicy, defaultMaxLoadFactor)
+}
+
+func makeHashMap[K comparable, V any](nanPolicy NaNPolicy, maxLoadFactor float64) (*HashMap[K, V], error) {
+	norm, err := keynorm.MakeNormalizer[K](nanPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if !validLoadFactor(maxLoadFactor) {
+		return nil, ErrInvalidLoadFactor
+	}
+	return &HashMap[K, V]{
+		capacity:      defaultCapacity,
+		minCapacity:   defaultCapacity,
+		buckets:       makeBucketTable[K, V](defaultCapacity),
+		maxLoadFactor: maxLoadFactor,
+		seed:          hashing.MakeSeed[K](),
+		comparer:      DefaultComparer[V](),
+		norm:          norm,
+	}, nil
+}
+
+var ErrKeyEncoding = hashing.ErrKeyEncoding
+
+func (m *HashMap[K, V]) hash(key K) int {
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		panic(err)
+	}
+	return hashedKey
+}
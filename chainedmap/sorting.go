@@ -0,0 +1,115 @@
+package chainedmap
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"sort"
+
+	"hashmaps/constraints"
+)
+
+// SortedByValue returns all entries ordered by value according to less
+func (m *HashMap[K, V]) SortedByValue(less func(a, b V) bool) []KVPair[K, V] {
+	pairs := m.Iter().Collect()
+	sort.SliceStable(pairs, func(i, j int) bool { return less(pairs[i].Value, pairs[j].Value) })
+	return pairs
+}
+
+// SortedByValueN returns only the first n entries of SortedByValue. It selects them
+// with a quickselect pass and sorts just those, O(len + n log n) instead of a full sort.
+// Entries with equal values may come in any order.
+func (m *HashMap[K, V]) SortedByValueN(less func(a, b V) bool, n int) []KVPair[K, V] {
+	pairs := m.Iter().Collect()
+	if n <= 0 {
+		return nil
+	}
+	if n < len(pairs) {
+		selectSmallest(pairs, n, less)
+		pairs = pairs[:n]
+	}
+	sort.Slice(pairs, func(i, j int) bool { return less(pairs[i].Value, pairs[j].Value) })
+	return pairs
+}
+
+// SortedByKey returns all entries in ascending key order. A NaN key, which
+// < can't order, sorts before every other key like cmp.Compare has it.
+func SortedByKey[K constraints.Ordered, V any](m *HashMap[K, V]) []KVPair[K, V] {
+	pairs := m.Iter().Collect()
+	slices.SortFunc(pairs, func(a, b KVPair[K, V]) int { return cmp.Compare(a.Key, b.Key) })
+	return pairs
+}
+
+// SortedKeys returns the keys in ascending order, for output that has to
+// be the same on every run, e.g. in test assertions. NaN sorts first.
+func SortedKeys[K constraints.Ordered, V any](m *HashMap[K, V]) []K {
+	keys := m.Iter().Keys()
+	slices.SortFunc(keys, cmp.Compare[K])
+	return keys
+}
+
+// SortedKeysFunc is SortedKeys for keys that aren't ordered by <
+func SortedKeysFunc[K comparable, V any](m *HashMap[K, V], less func(a, b K) bool) []K {
+	keys := m.Iter().Keys()
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+// RangeSorted is Range in ascending key order. The entries are copied and
+// sorted up front, so unlike Range fn may modify the map.
+func RangeSorted[K constraints.Ordered, V any](m *HashMap[K, V], fn func(key K, value V) bool) {
+	for _, pair := range SortedByKey(m) {
+		if !fn(pair.Key, pair.Value) {
+			return
+		}
+	}
+}
+
+// AllSorted is the range-over-func form of RangeSorted
+func AllSorted[K constraints.Ordered, V any](m *HashMap[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		RangeSorted(m, yield)
+	}
+}
+
+// selectSmallest moves the n smallest pairs, in no particular order, to the front
+func selectSmallest[K comparable, V any](pairs []KVPair[K, V], n int, less func(a, b V) bool) {
+	low, high := 0, len(pairs)-1
+	for low < high {
+		// median of three keeps sorted and reverse sorted input from going quadratic
+		mid := low + (high-low)/2
+		if less(pairs[mid].Value, pairs[low].Value) {
+			pairs[mid], pairs[low] = pairs[low], pairs[mid]
+		}
+		if less(pairs[high].Value, pairs[low].Value) {
+			pairs[high], pairs[low] = pairs[low], pairs[high]
+		}
+		if less(pairs[high].Value, pairs[mid].Value) {
+			pairs[high], pairs[mid] = pairs[mid], pairs[high]
+		}
+		pivot := pairs[mid].Value
+		i, j := low, high
+		for i <= j {
+			for less(pairs[i].Value, pivot) {
+				i++
+			}
+			for less(pivot, pairs[j].Value) {
+				j--
+			}
+			if i <= j {
+				pairs[i], pairs[j] = pairs[j], pairs[i]
+				i++
+				j--
+			}
+		}
+		// now pairs[low..j] <= pivot <= pairs[i..high]
+		switch {
+		case n-1 <= j:
+			high = j
+		case n-1 >= i:
+			low = i
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package chainedmap
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// With < a NaN key compares false against everything, which left the keys
+// after it unsorted
+func TestSortedKeysWithNaN(t *testing.T) {
+	m := MakeHashMap[float64, int]()
+	for i, key := range []float64{3, math.NaN(), 1, math.Inf(-1), 2, math.Inf(1), -1} {
+		m.Set(key, i)
+	}
+	keys := SortedKeys(m)
+	if len(keys) != 7 || !math.IsNaN(keys[0]) {
+		t.Fatalf("SortedKeys = %v, want NaN first", keys)
+	}
+	if want := []float64{math.Inf(-1), -1, 1, 2, 3, math.Inf(1)}; !slices.Equal(keys[1:], want) {
+		t.Fatalf("SortedKeys = %v, want NaN then %v", keys, want)
+	}
+	var ranged []float64
+	RangeSorted(m, func(key float64, _ int) bool {
+		ranged = append(ranged, key)
+		return true
+	})
+	if !slices.EqualFunc(ranged, keys, func(a, b float64) bool { return a == b || math.IsNaN(a) && math.IsNaN(b) }) {
+		t.Fatalf("RangeSorted visited %v, want %v", ranged, keys)
+	}
+	if pairs := SortedByKey(m); !math.IsNaN(pairs[0].Key) || pairs[0].Value != 1 || pairs[6].Key != math.Inf(1) {
+		t.Fatalf("SortedByKey = %v, want NaN first and +Inf last", pairs)
+	}
+}
@@ -0,0 +1,39 @@
+package chainedmap
+
+// Stats is a picture of the bucket table, for tuning the load factor and
+// for comparing the map with the other implementations
+type Stats struct {
+	Len          int
+	Capacity     int
+	LoadFactor   float64 // Len / Capacity
+	UsedBuckets  int     // buckets with at least one entry
+	LongestChain int
+	MeanChain    float64 // mean length of the non-empty chains, 1 is ideal
+	Rehashes     int     // times the table was rebuilt since the map was made, growing or shrinking
+}
+
+// Stats walks every bucket, so it is O(capacity)
+func (m *HashMap[K, V]) Stats() Stats {
+	stats := Stats{
+		Len:        m.length,
+		Capacity:   int(m.capacity),
+		LoadFactor: float64(m.length) / float64(m.capacity),
+		Rehashes:   m.rehashes,
+	}
+	for _, segment := range m.buckets.segments {
+		for _, bucket := range segment {
+			chain := 0
+			for pair := bucket; pair != nil; pair = pair.Next {
+				chain++
+			}
+			if chain > 0 {
+				stats.UsedBuckets++
+			}
+			stats.LongestChain = max(stats.LongestChain, chain)
+		}
+	}
+	if stats.UsedBuckets > 0 {
+		stats.MeanChain = float64(m.length) / float64(stats.UsedBuckets)
+	}
+	return stats
+}
@@ -0,0 +1,385 @@
+package chainedmap
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// SyncMap is sync.Map with type parameters, built on HashMap. It is meant
+// for the same read-mostly cases: keys written once and read many times,
+// or goroutines working on disjoint sets of keys.
+//
+// It works the same way. Loads of keys that are already there go to an
+// immutable HashMap published through an atomic pointer and take no lock.
+// New keys go to a dirty HashMap under a mutex. After as many lookups have
+// missed the read-only map as the dirty one holds, the dirty map becomes
+// the new read-only map.
+type SyncMap[K comparable, V any] struct {
+	mu     sync.Mutex
+	read   atomic.Pointer[readOnly[K, V]]
+	dirty  *HashMap[K, *entry[V]] // nil or a superset of the live read-only keys, guarded by mu
+	misses int                    // lookups that had to go to dirty since it was last promoted, guarded by mu
+
+	comparer Comparer[V] // for CompareAndSwap and CompareAndDelete
+}
+
+type readOnly[K comparable, V any] struct {
+	m       *HashMap[K, *entry[V]] // never modified once published, only its entries are
+	amended bool                   // dirty holds keys that m doesn't
+}
+
+// An entry is shared between the read-only and the dirty map.
+// p is nil when the key was deleted, expunged when it was deleted and
+// dirty was rebuilt without it, and points to the value otherwise.
+type entry[V any] struct {
+	p atomic.Pointer[V]
+}
+
+// expunged only serves as a unique pointer, see sync.Map
+var expunged = unsafe.Pointer(new(any))
+
+func isExpunged[V any](p *V) bool {
+	return unsafe.Pointer(p) == expunged
+}
+
+func MakeSyncMap[K comparable, V any]() *SyncMap[K, V] {
+	m := &SyncMap[K, V]{comparer: DefaultComparer[V]()}
+	m.read.Store(&readOnly[K, V]{m: MakeHashMap[K, *entry[V]]()})
+	return m
+}
+
+// lookup treats a nil map as empty, like indexing a nil built-in map
+func lookup[K comparable, V any](m *HashMap[K, *entry[V]], key K) *entry[V] {
+	if m == nil {
+		return nil
+	}
+	if e := m.Get(key); e != nil {
+		return *e
+	}
+	return nil
+}
+
+func (m *SyncMap[K, V]) Load(key K) (V, bool) {
+	read := m.read.Load()
+	e := lookup(read.m, key)
+	if e == nil && read.amended {
+		m.mu.Lock()
+		read = m.read.Load() // dirty may have been promoted while we waited for the lock
+		e = lookup(read.m, key)
+		if e == nil && read.amended {
+			e = lookup(m.dirty, key)
+			m.missLocked()
+		}
+		m.mu.Unlock()
+	}
+	if e == nil {
+		var zero V
+		return zero, false
+	}
+	return e.load()
+}
+
+func (m *SyncMap[K, V]) Store(key K, value V) {
+	if e := lookup(m.read.Load().m, key); e != nil && e.trySwap(&value) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	read := m.read.Load()
+	if e := lookup(read.m, key); e != nil {
+		if e.unexpungeLocked() {
+			// dirty was rebuilt without the key, it has to go back in
+			m.dirty.Set(key, e)
+		}
+		e.p.Store(&value)
+	} else if e := lookup(m.dirty, key); e != nil {
+		e.p.Store(&value)
+	} else {
+		m.amendLocked(read)
+		m.dirty.Set(key, newEntry(value))
+	}
+}
+
+// LoadOrStore returns the existing value of key if there is one.
+// Otherwise it stores value and returns it. loaded is true when the value was already there.
+func (m *SyncMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	if e := lookup(m.read.Load().m, key); e != nil {
+		if actual, loaded, ok := e.tryLoadOrStore(value); ok {
+			return actual, loaded
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	read := m.read.Load()
+	if e := lookup(read.m, key); e != nil {
+		if e.unexpungeLocked() {
+			m.dirty.Set(key, e)
+		}
+		actual, loaded, _ = e.tryLoadOrStore(value)
+	} else if e := lookup(m.dirty, key); e != nil {
+		actual, loaded, _ = e.tryLoadOrStore(value)
+		m.missLocked()
+	} else {
+		m.amendLocked(read)
+		m.dirty.Set(key, newEntry(value))
+		actual, loaded = value, false
+	}
+	return actual, loaded
+}
+
+// LoadAndDelete deletes key and returns the value it had, loaded is false when it wasn't there
+func (m *SyncMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	read := m.read.Load()
+	e := lookup(read.m, key)
+	if e == nil && read.amended {
+		m.mu.Lock()
+		read = m.read.Load()
+		e = lookup(read.m, key)
+		if e == nil && read.amended {
+			e = lookup(m.dirty, key)
+			m.dirty.Delete(key)
+			m.missLocked()
+		}
+		m.mu.Unlock()
+	}
+	if e == nil {
+		return value, false
+	}
+	return e.delete()
+}
+
+func (m *SyncMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// CompareAndSwap stores new when the value of key is old, compared with
+// DefaultComparer. Unlike sync.Map.CompareAndSwap it doesn't panic when V
+// holds values that aren't comparable.
+func (m *SyncMap[K, V]) CompareAndSwap(key K, old, new V) bool {
+	return m.CompareAndSwapFunc(key, old, new, nil)
+}
+
+// CompareAndSwapFunc is CompareAndSwap comparing with equal, nil means
+// DefaultComparer. equal may be called more than once when other
+// goroutines write to key meanwhile.
+func (m *SyncMap[K, V]) CompareAndSwapFunc(key K, old, new V, equal func(a, b V) bool) bool {
+	if equal == nil {
+		equal = m.comparer
+	}
+	read := m.read.Load()
+	if e := lookup(read.m, key); e != nil {
+		return e.tryCompareAndSwap(old, new, equal)
+	} else if !read.amended {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	read = m.read.Load()
+	swapped := false
+	if e := lookup(read.m, key); e != nil {
+		swapped = e.tryCompareAndSwap(old, new, equal)
+	} else if e := lookup(m.dirty, key); e != nil {
+		swapped = e.tryCompareAndSwap(old, new, equal)
+		m.missLocked()
+	}
+	return swapped
+}
+
+// CompareAndDelete deletes key when its value is old, comparing like
+// CompareAndSwap
+func (m *SyncMap[K, V]) CompareAndDelete(key K, old V) bool {
+	return m.CompareAndDeleteFunc(key, old, nil)
+}
+
+// CompareAndDeleteFunc is CompareAndDelete comparing with equal, nil means
+// DefaultComparer. Like Delete it only clears the entry, the key goes when
+// dirty is rebuilt.
+func (m *SyncMap[K, V]) CompareAndDeleteFunc(key K, old V, equal func(a, b V) bool) bool {
+	if equal == nil {
+		equal = m.comparer
+	}
+	read := m.read.Load()
+	e := lookup(read.m, key)
+	if e == nil && read.amended {
+		m.mu.Lock()
+		read = m.read.Load()
+		e = lookup(read.m, key)
+		if e == nil && read.amended {
+			e = lookup(m.dirty, key)
+			m.missLocked()
+		}
+		m.mu.Unlock()
+	}
+	return e != nil && e.tryCompareAndDelete(old, equal)
+}
+
+// Range calls fn for every key until fn returns false. Like sync.Map.Range
+// it isn't a snapshot: every key is visited at most once, and concurrent
+// writes may or may not be seen. It promotes the dirty map first, so
+// keys stored before the call are always visited.
+func (m *SyncMap[K, V]) Range(fn func(key K, value V) bool) {
+	read := m.read.Load()
+	if read.amended {
+		m.mu.Lock()
+		read = m.read.Load()
+		if read.amended {
+			read = &readOnly[K, V]{m: m.dirty}
+			m.read.Store(read)
+			m.dirty = nil
+			m.misses = 0
+		}
+		m.mu.Unlock()
+	}
+	read.m.Range(func(key K, e *entry[V]) bool {
+		value, ok := e.load()
+		if !ok {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+// All is the range-over-func form of Range
+func (m *SyncMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *SyncMap[K, V]) missLocked() {
+	m.misses++
+	if m.misses < m.dirty.Len() {
+		return
+	}
+	m.read.Store(&readOnly[K, V]{m: m.dirty})
+	m.dirty = nil
+	m.misses = 0
+}
+
+// amendLocked makes sure there is a dirty map and that the read-only map
+// knows a new key is about to go into it
+func (m *SyncMap[K, V]) amendLocked(read *readOnly[K, V]) {
+	if read.amended {
+		return
+	}
+	// rebuilding dirty copies every live entry, deleted ones are expunged
+	// so a later Store knows it has to add them to dirty again
+	m.dirty = MakeHashMap[K, *entry[V]]()
+	m.dirty.reserve(read.m.Len())
+	read.m.Range(func(key K, e *entry[V]) bool {
+		if !e.tryExpungeLocked() {
+			m.dirty.Set(key, e)
+		}
+		return true
+	})
+	m.read.Store(&readOnly[K, V]{m: read.m, amended: true})
+}
+
+func newEntry[V any](value V) *entry[V] {
+	e := &entry[V]{}
+	e.p.Store(&value)
+	return e
+}
+
+func (e *entry[V]) load() (V, bool) {
+	p := e.p.Load()
+	if p == nil || isExpunged(p) {
+		var zero V
+		return zero, false
+	}
+	return *p, true
+}
+
+// trySwap stores value unless the entry is expunged
+func (e *entry[V]) trySwap(value *V) bool {
+	for {
+		p := e.p.Load()
+		if isExpunged(p) {
+			return false
+		}
+		if e.p.CompareAndSwap(p, value) {
+			return true
+		}
+	}
+}
+
+// tryLoadOrStore gives up, ok is false, when the entry is expunged
+func (e *entry[V]) tryLoadOrStore(value V) (actual V, loaded, ok bool) {
+	p := e.p.Load()
+	if isExpunged(p) {
+		return actual, false, false
+	}
+	if p != nil {
+		return *p, true, true
+	}
+	stored := value // only escapes when the entry is empty
+	for {
+		if e.p.CompareAndSwap(nil, &stored) {
+			return value, false, true
+		}
+		p = e.p.Load()
+		if isExpunged(p) {
+			return actual, false, false
+		}
+		if p != nil {
+			return *p, true, true
+		}
+	}
+}
+
+func (e *entry[V]) tryCompareAndSwap(old, new V, equal func(a, b V) bool) bool {
+	p := e.p.Load()
+	if p == nil || isExpunged(p) || !equal(*p, old) {
+		return false
+	}
+	swapped := new
+	for {
+		if e.p.CompareAndSwap(p, &swapped) {
+			return true
+		}
+		p = e.p.Load()
+		if p == nil || isExpunged(p) || !equal(*p, old) {
+			return false
+		}
+	}
+}
+
+func (e *entry[V]) tryCompareAndDelete(old V, equal func(a, b V) bool) bool {
+	for {
+		p := e.p.Load()
+		if p == nil || isExpunged(p) || !equal(*p, old) {
+			return false
+		}
+		if e.p.CompareAndSwap(p, nil) {
+			return true
+		}
+	}
+}
+
+func (e *entry[V]) delete() (V, bool) {
+	for {
+		p := e.p.Load()
+		if p == nil || isExpunged(p) {
+			var zero V
+			return zero, false
+		}
+		if e.p.CompareAndSwap(p, nil) {
+			return *p, true
+		}
+	}
+}
+
+func (e *entry[V]) unexpungeLocked() bool {
+	return e.p.CompareAndSwap((*V)(expunged), nil)
+}
+
+func (e *entry[V]) tryExpungeLocked() bool {
+	p := e.p.Load()
+	for p == nil {
+		if e.p.CompareAndSwap(nil, (*V)(expunged)) {
+			return true
+		}
+		p = e.p.Load()
+	}
+	return isExpunged(p)
+}
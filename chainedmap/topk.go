@@ -0,0 +1,30 @@
+package chainedmap
+
+import "hashmaps/heap"
+
+// TopK returns the k entries with the largest values according to less,
+// largest first. It keeps the best k seen so far in a min-heap of size k,
+// whose top is the one to beat, which is O(len log k) time and O(min(k, len))
+// memory instead of sorting everything.
+func (m *HashMap[K, V]) TopK(k int, less func(a, b V) bool) []KVPair[K, V] {
+	if k <= 0 {
+		return nil
+	}
+	best := heap.MakeHeap(func(a, b KVPair[K, V]) bool { return less(a.Value, b.Value) })
+	m.Iter().Each(func(key K, value V) bool {
+		if best.Len() < k {
+			best.Push(KVPair[K, V]{Key: key, Value: value})
+			return true
+		}
+		if worst, _ := best.Peek(); less(worst.Value, value) {
+			best.Pop()
+			best.Push(KVPair[K, V]{Key: key, Value: value})
+		}
+		return true
+	})
+	top := make([]KVPair[K, V], best.Len())
+	for i := len(top) - 1; i >= 0; i-- { // the heap pops the smallest first
+		top[i], _ = best.Pop()
+	}
+	return top
+}
@@ -0,0 +1,52 @@
+package chainedmap
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{0, 1, 5, 100, 1000} {
+		m := MakeHashMap[int, int]()
+		var values []int
+		for i := 0; i < n; i++ {
+			value := random.IntN(50) // plenty of ties
+			m.Set(i, value)
+			values = append(values, value)
+		}
+		slices.SortFunc(values, func(a, b int) int { return b - a })
+		for _, k := range []int{1, 3, 10, 2000} {
+			top := m.TopK(k, less)
+			want := values[:min(k, n)]
+			got := make([]int, len(top))
+			for i, pair := range top {
+				got[i] = pair.Value
+				if *m.Get(pair.Key) != pair.Value {
+					t.Fatalf("TopK returned %d=%d, the map holds %d", pair.Key, pair.Value, *m.Get(pair.Key))
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("TopK(%d) of %d values = %v, want %v", k, n, got, want)
+			}
+		}
+	}
+	if m := filledMap(10); m.TopK(0, func(a, b int) bool { return a < b }) != nil {
+		t.Fatal("TopK(0) isn't nil")
+	}
+}
+
+func TestTopKHugeK(t *testing.T) {
+	m := filledMap(10)
+	for _, k := range []int{11, math.MaxInt / 2, math.MaxInt} {
+		if top := m.TopK(k, func(a, b int) bool { return a < b }); len(top) != 10 || top[0].Value != 9 || top[9].Value != 0 {
+			t.Fatalf("TopK(%d) of 10 entries = %v, want all 10 largest first", k, top)
+		}
+	}
+	if top := MakeHashMap[int, int]().TopK(math.MaxInt, func(a, b int) bool { return a < b }); len(top) != 0 {
+		t.Fatalf("TopK of an empty map = %v", top)
+	}
+}
@@ -0,0 +1,54 @@
+package chainedmap
+
+// Filter returns a new map with the entries pred returns true for, with
+// the same settings and Comparer as m. The matches are gathered first so
+// the new table is sized for them once. Iter().Filter is the lazy form.
+func (m *HashMap[K, V]) Filter(pred func(key K, value V) bool) *HashMap[K, V] {
+	var matches []KVPair[K, V]
+	m.Range(func(key K, value V) bool {
+		if pred(key, value) {
+			matches = append(matches, KVPair[K, V]{Key: key, Value: value})
+		}
+		return true
+	})
+	filtered := emptyLike[K, V, V](m, len(matches))
+	filtered.comparer = m.comparer
+	for _, pair := range matches {
+		filtered.insertNew(pair.Key, pair.Value)
+	}
+	return filtered
+}
+
+// emptyLike makes an empty map with the NaNPolicy, load factor, Hasher,
+// seed and minimum capacity of m, sized for n entries, so a map made
+// WithSeed keeps its layout. U may differ from V.
+func emptyLike[K comparable, V, U any](m *HashMap[K, V], n int) *HashMap[K, U] {
+	empty, _ := makeHashMap[K, U](m.norm.NaNPolicy(), m.maxLoadFactor) // both are valid in m
+	empty.hasher = m.hasher
+	empty.seed = m.seed
+	empty.minCapacity = m.minCapacity
+	empty.capacity, _ = m.grownCapacity(m.minCapacity, n) // at most maxCapacity, chains take the rest
+	empty.buckets = makeBucketTable[K, U](int(empty.capacity))
+	return empty
+}
+
+// insertNew is for keys taken from another map: they are normalized,
+// hashable and not in m yet, so the chain doesn't have to be walked
+func (m *HashMap[K, V]) insertNew(key K, value V) {
+	hashedKey := m.hash(key)
+	m.buckets.setHead(hashedKey, &KVPair[K, V]{Key: key, Value: value, Next: m.buckets.head(hashedKey)})
+	m.length++
+	m.growIfNeeded()
+}
+
+// MapValues returns a new map with the keys of m and f of every value,
+// with the same settings as m. It's a function because a method can't
+// introduce the type parameter U.
+func MapValues[K comparable, V, U any](m *HashMap[K, V], f func(V) U) *HashMap[K, U] {
+	mapped := emptyLike[K, V, U](m, m.length)
+	m.Range(func(key K, value V) bool {
+		mapped.insertNew(key, f(value))
+		return true
+	})
+	return mapped
+}
@@ -0,0 +1,36 @@
+package chainedmap
+
+import (
+	"maps"
+	"strconv"
+	"testing"
+)
+
+func TestTransformsKeepTheSeed(t *testing.T) {
+	m := MakeHashMap[string, int](WithSeed(MakeSeed()))
+	for i := 0; i < 100; i++ {
+		m.Set(strconv.Itoa(i), i)
+	}
+	filtered := m.Filter(func(string, int) bool { return true })
+	mapped := MapValues(m, func(value int) string { return strconv.Itoa(value) })
+	for key := range m.Keys() {
+		if filtered.hash(key) != m.hash(key) || mapped.hash(key) != m.hash(key) {
+			t.Fatalf("key %q moved to another bucket", key)
+		}
+	}
+}
+
+func TestFilterAndMapValues(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	even := m.Filter(func(key, _ int) bool { return key%2 == 0 })
+	if want := map[int]int{0: 0, 2: 2, 4: 4, 6: 6, 8: 8}; !maps.Equal(even.ToMap(), want) {
+		t.Fatalf("Filter = %v, want %v", even.ToMap(), want)
+	}
+	doubled := MapValues(even, func(value int) int { return 2 * value })
+	if want := map[int]int{0: 0, 2: 4, 4: 8, 6: 12, 8: 16}; !maps.Equal(doubled.ToMap(), want) {
+		t.Fatalf("MapValues = %v, want %v", doubled.ToMap(), want)
+	}
+}
@@ -0,0 +1,109 @@
+package chainedmap
+
+// Tx stages the writes of a Batch. Nothing reaches the map until the
+// callback returns, Get sees the staged writes.
+type Tx[K comparable, V any] struct {
+	m      *HashMap[K, V]
+	staged *FuncHashMap[K, stagedWrite[V]] // the last write per key, hashed like m
+	err    error                           // the first Set or Delete that failed
+}
+
+type stagedWrite[V any] struct {
+	value   V
+	deleted bool
+}
+
+// Set stages key=value. A key the map would refuse, e.g. one that can't be
+// hashed, fails the whole batch and Batch returns its error.
+func (tx *Tx[K, V]) Set(key K, value V) {
+	tx.stage(key, stagedWrite[V]{value: value})
+}
+
+func (tx *Tx[K, V]) Delete(key K) {
+	tx.stage(key, stagedWrite[V]{deleted: true})
+}
+
+// Get returns the value key will have once the batch is applied
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	if normalized, err := tx.m.norm.Normalize(key); err == nil {
+		if write := tx.staged.Get(normalized); write != nil {
+			return write.value, !write.deleted
+		}
+	}
+	if value := tx.m.Get(key); value != nil {
+		return *value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (tx *Tx[K, V]) stage(key K, write stagedWrite[V]) {
+	if tx.err != nil {
+		return
+	}
+	normalized, err := tx.m.norm.Normalize(key)
+	if err != nil && write.deleted { // rejected keys are never stored
+		return
+	}
+	if err == nil {
+		_, err = tx.m.tryHash(normalized)
+	}
+	if err != nil {
+		tx.err = err
+		return
+	}
+	tx.staged.Set(normalized, write)
+}
+
+// Batch runs fn and then applies all the Set and Delete calls it staged on
+// tx together, or none of them when fn returns an error or panics or one
+// of its keys was refused. fn must not modify m itself. The table is
+// resized at most once, for the final number of entries, instead of after
+// every write that crosses a threshold.
+func (m *HashMap[K, V]) Batch(fn func(tx *Tx[K, V]) error) error {
+	// staged keys are normalized and were hashed once, so fullHash can't fail
+	staged := MakeHashMapFunc[K, stagedWrite[V]](m.fullHash, m.norm.Equal)
+	tx := &Tx[K, V]{m: m, staged: staged}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if tx.err != nil {
+		return tx.err
+	}
+
+	final := m.length
+	staged.Range(func(key K, write stagedWrite[V]) bool {
+		switch exists := m.contains(key); {
+		case write.deleted && exists:
+			final--
+		case !write.deleted && !exists:
+			final++
+		}
+		return true
+	})
+	// deletes first, then the one resize, so the sets land in the final table
+	staged.Range(func(key K, write stagedWrite[V]) bool {
+		if write.deleted {
+			m.removeAt(m.hash(key), key, nil)
+		}
+		return true
+	})
+	m.resizeFor(final)
+	staged.Range(func(key K, write stagedWrite[V]) bool {
+		if !write.deleted {
+			m.insertAt(m.hash(key), key, write.value)
+		}
+		return true
+	})
+	return nil
+}
+
+// contains is for normalized keys that were hashed successfully before
+func (m *HashMap[K, V]) contains(key K) bool {
+	for pointer := m.buckets.head(m.hash(key)); pointer != nil; pointer = pointer.Next {
+		if m.norm.Equal(pointer.Key, key) {
+			return true
+		}
+	}
+	return false
+}
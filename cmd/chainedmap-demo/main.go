@@ -0,0 +1,30 @@
+// Command chainedmap-demo fills a chainedmap.HashMap, reads the entries back
+// and dumps the buckets to show which keys collided.
+package main
+
+import (
+	"os"
+
+	"hashmaps/chainedmap"
+)
+
+func main() {
+	myHashmap := chainedmap.MakeHashMap[string, int]()
+	myHashmap.Set("sdf", 1)
+	myHashmap.Set("asdf", 2)
+	myHashmap.Set("asdfs", 3)
+	myHashmap.Set("asd2342342f", 4)
+
+	myHashmap.Set("sdf2222222", 10)
+	myHashmap.Set("asdf2222222", 20)
+	myHashmap.Set("asdfs2222222", 30)
+	myHashmap.Set("asd2342342f2222222", 40)
+	println("-----------------------------")
+	println(*myHashmap.Get("sdf"))
+	println(*myHashmap.Get("asdf"))
+	println(*myHashmap.Get("asdf2222222"))
+	println(*myHashmap.Get("asd2342342f"))
+
+	println("-----------------------------")
+	myHashmap.DebugDump(os.Stdout)
+}
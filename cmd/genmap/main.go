@@ -0,0 +1,227 @@
+// Command genmap writes a map implementation specialized for one key and value
+// type. The generated code has no type parameters and calls hash/maphash
+// directly, with a random seed per map like the generic maps, for the hot
+// paths where even those show up in profiles.
+//
+// Typical use is a go:generate line next to the code that needs the map:
+//
+//	//go:generate go run hashmaps/cmd/genmap -name userScores -key string -value float64 -o user_scores_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// hashers maps the supported key types to how the generated code hashes
+// them: strings with maphash.String, integers as 8 little endian bytes
+var hashers = map[string]string{
+	"string": "string",
+	"int":    "integer",
+	"int8":   "integer",
+	"int16":  "integer",
+	"int32":  "integer",
+	"int64":  "integer",
+	"uint":   "integer",
+	"uint8":  "integer",
+	"uint16": "integer",
+	"uint32": "integer",
+	"uint64": "integer",
+	"rune":   "integer",
+	"byte":   "integer",
+}
+
+type params struct {
+	Package   string
+	Name      string
+	EntryName string
+	MakeName  string
+	Key       string
+	Value     string
+	Hasher    string
+	Imports   []string
+	Command   string
+}
+
+func main() {
+	packageName := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file, defaults to $GOPACKAGE set by go generate")
+	name := flag.String("name", "", "name of the generated map type")
+	key := flag.String("key", "", "key type: string or a built-in integer type")
+	value := flag.String("value", "", "value type, any Go type expression")
+	imports := flag.String("imports", "", "comma separated import paths needed by the value type")
+	output := flag.String("o", "", "output file, stdout when empty")
+	flag.Parse()
+
+	command := "genmap " + strings.Join(os.Args[1:], " ")
+	source, err := generate(command, *packageName, *name, *key, *value, *imports)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "genmap:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(source)
+		return
+	}
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "genmap:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the formatted source, command goes into its header
+func generate(command, packageName, name, key, value, imports string) ([]byte, error) {
+	if packageName == "" || name == "" || key == "" || value == "" {
+		return nil, fmt.Errorf("-package, -name, -key and -value are required")
+	}
+	hasher, ok := hashers[key]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %q, use string or a built-in integer type", key)
+	}
+	entryName := string(unicode.ToLower(rune(name[0]))) + name[1:] + "Entry"
+	importPaths := []string{"hash/maphash"}
+	if hasher == "integer" {
+		importPaths = append(importPaths, "encoding/binary")
+	}
+	for _, path := range strings.Split(imports, ",") {
+		if path = strings.TrimSpace(path); path != "" && !slices.Contains(importPaths, path) {
+			importPaths = append(importPaths, path)
+		}
+	}
+	slices.Sort(importPaths)
+
+	var buffer bytes.Buffer
+	err := mapTemplate.Execute(&buffer, params{
+		Package:   packageName,
+		Name:      name,
+		EntryName: entryName,
+		MakeName:  "Make" + string(unicode.ToUpper(rune(name[0]))) + name[1:],
+		Key:       key,
+		Value:     value,
+		Hasher:    hasher,
+		Imports:   importPaths,
+		Command:   command,
+	})
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code doesn't compile, check -value and -imports: %w", err)
+	}
+	return formatted, nil
+}
+
+var mapTemplate = template.Must(template.New("map").Parse(`// Code generated by {{.Command}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+type {{.EntryName}} struct {
+	key   {{.Key}}
+	value {{.Value}}
+	next  *{{.EntryName}}
+}
+
+// {{.Name}} is a chained hash map from {{.Key}} to {{.Value}}.
+// The bucket count is a power of two and doubles when there are more
+// entries than buckets. Every map hashes with its own random seed, so keys
+// chosen to collide in one process don't collide in another.
+type {{.Name}} struct {
+	buckets []*{{.EntryName}}
+	length  int
+	seed    maphash.Seed
+}
+
+func {{.MakeName}}() *{{.Name}} {
+	return &{{.Name}}{buckets: make([]*{{.EntryName}}, 8), seed: maphash.MakeSeed()}
+}
+
+func (m *{{.Name}}) Len() int {
+	return m.length
+}
+
+func (m *{{.Name}}) Get(key {{.Key}}) ({{.Value}}, bool) {
+	for e := m.buckets[m.index(key)]; e != nil; e = e.next {
+		if e.key == key {
+			return e.value, true
+		}
+	}
+	var zero {{.Value}}
+	return zero, false
+}
+
+func (m *{{.Name}}) Set(key {{.Key}}, value {{.Value}}) {
+	i := m.index(key)
+	for e := m.buckets[i]; e != nil; e = e.next {
+		if e.key == key {
+			e.value = value
+			return
+		}
+	}
+	m.buckets[i] = &{{.EntryName}}{key: key, value: value, next: m.buckets[i]}
+	m.length++
+	if m.length > len(m.buckets) {
+		m.grow()
+	}
+}
+
+func (m *{{.Name}}) Delete(key {{.Key}}) bool {
+	i := m.index(key)
+	for link := &m.buckets[i]; *link != nil; link = &(*link).next {
+		if (*link).key == key {
+			*link = (*link).next
+			m.length--
+			return true
+		}
+	}
+	return false
+}
+
+// Range visits entries in no particular order until fn returns false
+func (m *{{.Name}}) Range(fn func(key {{.Key}}, value {{.Value}}) bool) {
+	for _, bucket := range m.buckets {
+		for e := bucket; e != nil; e = e.next {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func (m *{{.Name}}) grow() {
+	old := m.buckets
+	m.buckets = make([]*{{.EntryName}}, 2*len(old))
+	for _, bucket := range old {
+		for e := bucket; e != nil; {
+			next := e.next
+			i := m.index(e.key)
+			e.next = m.buckets[i]
+			m.buckets[i] = e
+			e = next
+		}
+	}
+}
+
+func (m *{{.Name}}) index(key {{.Key}}) int {
+{{- if eq .Hasher "string"}}
+	h := maphash.String(m.seed, key)
+{{- else}}
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(key))
+	h := maphash.Bytes(m.seed, b[:])
+{{- end}}
+	return int(h & uint64(len(m.buckets)-1))
+}
+`))
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var cases = []struct {
+	golden                    string
+	name, key, value, imports string
+}{
+	{"string_float64.go.golden", "userScores", "string", "float64", ""},
+	{"int32_duration.go.golden", "Timeouts", "int32", "[]time.Duration", "time"},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tc := range cases {
+		source, err := generate("genmap", "main", tc.name, tc.key, tc.value, tc.imports)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.golden, err)
+		}
+		path := filepath.Join("testdata", tc.golden)
+		if *update {
+			if err := os.WriteFile(path, source, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%v, run go test -update to create it", err)
+		}
+		if !bytes.Equal(source, want) {
+			t.Fatalf("generated code differs from %s, run go test -update and review the diff", path)
+		}
+	}
+}
+
+func TestGenerateRejects(t *testing.T) {
+	for _, args := range [][5]string{
+		{"", "m", "string", "int", ""},
+		{"p", "m", "float64", "int", ""}, // NaN keys would never be found again
+		{"p", "m", "string", "int)", ""},
+	} {
+		if _, err := generate("genmap", args[0], args[1], args[2], args[3], args[4]); err == nil {
+			t.Fatalf("generate%q succeeded", args)
+		}
+	}
+}
+
+// usesGenerated checks the golden files against a Go map. It runs as its
+// own program, the generated code has to compile and vet on its own.
+const usesGenerated = `package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+func main() {
+	scores, want := MakeUserScores(), map[string]float64{}
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i % 700)
+		scores.Set(key, float64(i))
+		want[key] = float64(i)
+		if i%3 == 0 {
+			key = strconv.Itoa(i / 2)
+			_, found := want[key]
+			if scores.Delete(key) != found {
+				fail("Delete(%s) = %v", key, !found)
+			}
+			delete(want, key)
+		}
+	}
+	if scores.Len() != len(want) {
+		fail("Len() = %d, want %d", scores.Len(), len(want))
+	}
+	for key, value := range want {
+		if got, ok := scores.Get(key); !ok || got != value {
+			fail("Get(%s) = %v, %v, want %v", key, got, ok, value)
+		}
+	}
+	ranged := 0
+	scores.Range(func(key string, value float64) bool {
+		ranged++
+		return want[key] == value
+	})
+	if ranged != len(want) {
+		fail("Range visited %d of %d entries", ranged, len(want))
+	}
+
+	timeouts := MakeTimeouts()
+	for i := int32(-500); i < 500; i++ {
+		timeouts.Set(i, []time.Duration{time.Duration(i)})
+	}
+	if got, ok := timeouts.Get(-500); !ok || got[0] != -500 || timeouts.Len() != 1000 {
+		fail("Get(-500) = %v, %v with Len() %d", got, ok, timeouts.Len())
+	}
+	if _, ok := timeouts.Get(500); ok {
+		fail("Get(500) found a key never set")
+	}
+}
+`
+
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"go.mod":  []byte("module gentest\n\ngo 1.23\n"),
+		"main.go": []byte(usesGenerated),
+	}
+	for _, tc := range cases {
+		source, err := os.ReadFile(filepath.Join("testdata", tc.golden))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[strings.TrimSuffix(tc.golden, ".golden")] = source
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"vet", "."}, {"run", "."}} {
+		cmd := exec.Command(goCommand, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=", "GOTOOLCHAIN=local")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %v\n%s", args[0], err, output)
+		}
+	}
+}
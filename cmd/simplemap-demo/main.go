@@ -0,0 +1,25 @@
+// Command simplemap-demo fills a simplemap.HashMap and reads the entries back.
+package main
+
+import "hashmaps/simplemap"
+
+func main() {
+	myHashmap := simplemap.MakeHashMap[string, int]()
+	myHashmap.Set("sdf", 1)
+	myHashmap.Set("asdf", 2)
+	myHashmap.Set("asdfs", 3)
+	myHashmap.Set("asd2342342f", 4)
+
+	myHashmap.Set("sdf2222222", 10)
+	myHashmap.Set("asdf2222222", 20)
+	myHashmap.Set("asdfs2222222", 30)
+	myHashmap.Set("asd2342342f2222222", 40)
+	println("-----------------------------")
+	println(myHashmap.Get("sdf"))
+	println(myHashmap.Get("asdf"))
+	println(myHashmap.Get("asdfs"))
+	println(myHashmap.Get("asd2342342f"))
+	println(myHashmap.Get("non-existent"))
+
+	println(myHashmap)
+}
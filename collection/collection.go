@@ -0,0 +1,54 @@
+// Package collection is the iteration contract shared by every structure in
+// this repo: an All method returning a standard iterator. Maps and other
+// keyed structures yield pairs as an iter.Seq2, sets, heaps and other bags
+// of items yield an iter.Seq. Nothing has to import this package to satisfy
+// it, the interfaces only name what the types already have, so generic code
+// can accept any of them:
+//
+//	func Dump[K comparable, V any](c collection.Iterable2[K, V]) {
+//		for key, value := range c.All() { ... }
+//	}
+//
+// Callers that want a Next() style iterator instead of a range loop get
+// one from iter.Pull or iter.Pull2.
+//
+// Unless a type says otherwise the order is unspecified, and the structure
+// must not be modified while iterating.
+package collection
+
+import "iter"
+
+type Iterable[T any] interface {
+	All() iter.Seq[T]
+}
+
+type Iterable2[K, V any] interface {
+	All() iter.Seq2[K, V]
+}
+
+// Collect gathers the items of c in iteration order
+func Collect[T any](c Iterable[T]) []T {
+	var items []T
+	for item := range c.All() {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Count walks c once, for structures that don't keep a length
+func Count[T any](c Iterable[T]) int {
+	n := 0
+	for range c.All() {
+		n++
+	}
+	return n
+}
+
+// Count2 is Count for keyed structures
+func Count2[K, V any](c Iterable2[K, V]) int {
+	n := 0
+	for range c.All() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,39 @@
+// Package constraints defines the type sets shared by the generic code in this
+// repo, so helpers written on top of it can use the same vocabulary.
+package constraints
+
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+type Integer interface {
+	Signed | Unsigned
+}
+
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is any integer or floating point type
+type Number interface {
+	Integer | Float
+}
+
+// Ordered covers the types that support < and can be sorted directly
+type Ordered interface {
+	Integer | Float | ~string
+}
+
+// Hashable is implemented by key types that compute their own hash, e.g.
+// structs with cached or derived fields that shouldn't be hashed, or that
+// gob can't encode. The maps in this repo check once per map whether the
+// key type has this method and then use it instead of their own hashing;
+// the result is still mixed with the map's seed. Keys that are == must
+// return the same hash, so Hash may only look at fields that == compares.
+type Hashable interface {
+	Hash() uint64
+}
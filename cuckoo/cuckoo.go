@@ -0,0 +1,355 @@
+// Package cuckoo is a hashmap with cuckoo hashing: two tables and two hash
+// functions, and every key is in one of exactly two slots, its slot in the
+// first table or its slot in the second. Lookups and deletes look at those
+// two slots and nothing else, so they are O(1) in the worst case.
+//
+// Inserts pay for it: a key whose slots are both taken kicks out the
+// occupant of the first one, which moves to its other slot, possibly kicking
+// out the next key, and so on. When that goes on for too long the keys
+// form a cycle that can't be resolved, and the whole map is rehashed with
+// new hash functions.
+package cuckoo
+
+import (
+	"errors"
+	"iter"
+	"math/bits"
+	"slices"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+type KVPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type slot[K comparable, V any] struct {
+	occupied bool
+	pair     KVPair[K, V]
+}
+
+type HashMap[K comparable, V any] struct {
+	capacity int64 // slots per table
+	tables   [2][]slot[K, V]
+	length   int
+	seed     hashing.Seed // picks both hash functions, see internal/hashing
+
+	norm keynorm.Normalizer[K] // float keys and the NaNPolicy, see internal/keynorm
+}
+
+const (
+	defaultCapacity = 8
+	// with two tables of one slot per bucket inserts start failing a lot
+	// beyond half full, so the tables double well before that
+	maxLoadFactor = 0.45
+	// kicks before an insert is treated as a cycle
+	maxKicks = 64
+	// rehashes with new hash functions at the same capacity before giving up and doubling
+	maxRehashAttempts = 4
+)
+
+// maxCapacity is the most slots per table. Keys that still don't fit there
+// are refused with ErrCapacityOverflow, and so are three keys with the same
+// hash, e.g. structs holding a NaN, which never equal themselves and share
+// both slots at every capacity.
+const maxCapacity = 1 << min(32, bits.UintSize-2)
+
+var (
+	ErrKeyEncoding      = hashing.ErrKeyEncoding
+	ErrCapacityOverflow = errors.New("keys don't fit into maxCapacity slots per table")
+)
+
+func MakeHashMap[K comparable, V any]() *HashMap[K, V] {
+	m, _ := MakeHashMapWithNaNPolicy[K, V](CanonicalizeNaN) // the default policy is always valid
+	return m
+}
+
+// NaNPolicy is what MakeHashMapWithNaNPolicy takes, it is internal/keynorm's,
+// so the other map packages take the same values. A NaN key rejected under
+// RejectNaN is turned away before it can kick out an entry.
+type NaNPolicy = keynorm.NaNPolicy
+
+const (
+	CanonicalizeNaN = keynorm.CanonicalizeNaN
+	RejectNaN       = keynorm.RejectNaN
+)
+
+var (
+	ErrNaNKey           = keynorm.ErrNaNKey
+	ErrInvalidNaNPolicy = keynorm.ErrInvalidNaNPolicy
+)
+
+func MakeHashMapWithNaNPolicy[K comparable, V any](nanPolicy NaNPolicy) (*HashMap[K, V], error) {
+	norm, err := keynorm.MakeNormalizer[K](nanPolicy)
+	if err != nil {
+		return nil, err
+	}
+	m := &HashMap[K, V]{
+		capacity: defaultCapacity,
+		seed:     hashing.MakeSeed[K](),
+		norm:     norm,
+	}
+	m.tables[0] = make([]slot[K, V], defaultCapacity)
+	m.tables[1] = make([]slot[K, V], defaultCapacity)
+	return m, nil
+}
+
+// Get, Set, Delete and Remove panic when the key can't be hashed, e.g. a gob encoding failure,
+// or when Set can't find a slot for it, see maxCapacity.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *HashMap[K, V]) Get(key K) *V {
+	value, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (m *HashMap[K, V]) TryGet(key K) (*V, error) {
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return nil, nil
+	}
+	s, err := m.find(key)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return &s.pair.Value, nil
+}
+
+// Set also panics when the key is rejected by the NaNPolicy
+func (m *HashMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *HashMap[K, V]) TrySet(key K, value V) error {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		return err
+	}
+	s, err := m.find(key)
+	if err != nil {
+		return err
+	}
+	if s != nil {
+		s.pair.Value = value
+		return nil
+	}
+	if m.capacity < maxCapacity && float64(m.length+1) > maxLoadFactor*float64(2*m.capacity) {
+		if err := m.rehash(min(m.capacity*2, maxCapacity), nil); err != nil {
+			return err
+		}
+	}
+	if homeless, swapped, ok := m.place(KVPair[K, V]{Key: key, Value: value}); !ok {
+		if err := m.rehash(m.capacity, &homeless); err != nil {
+			unplace(homeless, swapped) // back to the map without key
+			return err
+		}
+	}
+	m.length++
+	return nil
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *HashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *HashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+// Pop is Delete, the popped slot is emptied and no other key moves
+func (m *HashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+func (m *HashMap[K, V]) TryDelete(key K) (V, bool, error) {
+	var value V
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return value, false, nil
+	}
+	s, err := m.find(key)
+	if err != nil || s == nil {
+		return value, false, err
+	}
+	value = s.pair.Value
+	*s = slot[K, V]{}
+	m.length--
+	return value, true, nil
+}
+
+// Len returns the number of entries, in O(1)
+func (m *HashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, like sync.Map.Range.
+// The map must not be modified by fn.
+func (m *HashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for t := range m.tables {
+		for i := range m.tables[t] {
+			s := &m.tables[t][i]
+			if s.occupied && !fn(s.pair.Key, s.pair.Value) {
+				return
+			}
+		}
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// positions returns the slot of key in each table. Both come from one
+// 64 bit hash, the second one after remixing it.
+func (m *HashMap[K, V]) positions(key K) ([2]int, error) {
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return [2]int{}, err
+	}
+	return [2]int{
+		int(hashedKey % uint64(m.capacity)),
+		int(hashing.Mix(hashedKey) % uint64(m.capacity)),
+	}, nil
+}
+
+// find returns the slot holding key, or nil
+func (m *HashMap[K, V]) find(key K) (*slot[K, V], error) {
+	positions, err := m.positions(key)
+	if err != nil {
+		return nil, err
+	}
+	for t, i := range positions {
+		s := &m.tables[t][i]
+		if s.occupied && m.norm.Equal(s.pair.Key, key) {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// place puts a pair whose key isn't in the map yet into one of its two slots,
+// kicking out the occupant when both are taken. After maxKicks it gives up
+// and returns the pair that is left without a slot, which may be a different
+// one than it started with, and the slots it swapped pairs with on the way.
+func (m *HashMap[K, V]) place(pair KVPair[K, V]) (KVPair[K, V], []*slot[K, V], bool) {
+	var swapped []*slot[K, V]
+	t := 0
+	for kick := 0; kick < maxKicks; kick++ {
+		// keys in the map, or on their way in, were hashed before
+		positions, _ := m.positions(pair.Key)
+		if !m.tables[0][positions[0]].occupied {
+			m.tables[0][positions[0]] = slot[K, V]{occupied: true, pair: pair}
+			return pair, nil, true
+		}
+		if !m.tables[1][positions[1]].occupied {
+			m.tables[1][positions[1]] = slot[K, V]{occupied: true, pair: pair}
+			return pair, nil, true
+		}
+		// both taken, evict from alternating tables so the chain moves on
+		s := &m.tables[t][positions[t]]
+		pair, s.pair = s.pair, pair
+		swapped = append(swapped, s)
+		t = 1 - t
+	}
+	return pair, swapped, false
+}
+
+// unplace undoes a place that failed. Swapping back in reverse order puts
+// every kicked pair into its old slot and leaves the first pair homeless.
+func unplace[K comparable, V any](homeless KVPair[K, V], swapped []*slot[K, V]) {
+	for i := len(swapped) - 1; i >= 0; i-- {
+		homeless, swapped[i].pair = swapped[i].pair, homeless
+	}
+}
+
+// rehash moves every entry, plus extra when it isn't nil, into tables of
+// newCapacity slots with new hash functions. It retries with other hash
+// functions until every pair finds a slot, doubling the capacity after
+// maxRehashAttempts failures. When the pairs can't fit, it returns
+// ErrCapacityOverflow and leaves the map as it was.
+func (m *HashMap[K, V]) rehash(newCapacity int64, extra *KVPair[K, V]) error {
+	var pairs []KVPair[K, V]
+	m.Range(func(key K, value V) bool {
+		pairs = append(pairs, KVPair[K, V]{Key: key, Value: value})
+		return true
+	})
+	if extra != nil {
+		pairs = append(pairs, *extra)
+	}
+	oldCapacity, oldSeed, oldTables := m.capacity, m.seed, m.tables
+	for attempt := 1; ; attempt++ {
+		m.capacity = newCapacity
+		m.seed = hashing.MakeSeed[K]()
+		m.tables[0] = make([]slot[K, V], newCapacity)
+		m.tables[1] = make([]slot[K, V], newCapacity)
+		placed := true
+		for _, pair := range pairs {
+			if _, _, ok := m.place(pair); !ok {
+				placed = false
+				break
+			}
+		}
+		if placed {
+			return nil
+		}
+		if attempt%maxRehashAttempts == 0 {
+			if newCapacity >= maxCapacity || m.sameHashes(pairs) > 2 {
+				m.capacity, m.seed, m.tables = oldCapacity, oldSeed, oldTables
+				return ErrCapacityOverflow
+			}
+			newCapacity = min(newCapacity*2, maxCapacity)
+		}
+	}
+}
+
+// sameHashes returns the most pairs that share one hash with the current
+// seed. Pairs with the same hash share both slots, so more than two never
+// fit, and with a fresh seed in every attempt that only lasts for keys
+// that hash the same with every seed.
+func (m *HashMap[K, V]) sameHashes(pairs []KVPair[K, V]) int {
+	hashes := make([]uint64, len(pairs))
+	for i, pair := range pairs {
+		hashes[i], _ = hashing.Hash(m.seed, pair.Key)
+	}
+	slices.Sort(hashes)
+	most, run := 0, 0
+	for i := range hashes {
+		if i > 0 && hashes[i] == hashes[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		most = max(most, run)
+	}
+	return most
+}
@@ -0,0 +1,98 @@
+package cuckoo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i*i)
+	}
+	for i := 0; i < 1000; i += 2 {
+		if value, ok := m.Delete(i); !ok || value != i*i {
+			t.Fatalf("Delete(%d) = %d, %v, want %d, true", i, value, ok, i*i)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		value := m.Get(i)
+		if deleted := i%2 == 0; deleted != (value == nil) || !deleted && *value != i*i {
+			t.Fatalf("Get(%d) = %v after deleting the even keys", i, value)
+		}
+	}
+	if m.Len() != 500 {
+		t.Fatalf("Len() = %d, want 500", m.Len())
+	}
+}
+
+func TestRejectNaNReturnsError(t *testing.T) {
+	m, err := MakeHashMapWithNaNPolicy[float64, int](RejectNaN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.TrySet(math.NaN(), 1); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("TrySet(NaN) = %v, want ErrNaNKey", err)
+	}
+	if value, err := m.TryGet(math.NaN()); value != nil || err != nil {
+		t.Fatalf("TryGet(NaN) = %v, %v, want nil, nil", value, err)
+	}
+	if _, ok, err := m.TryDelete(math.NaN()); ok || err != nil {
+		t.Fatalf("TryDelete(NaN) = %v, %v, want false, nil", ok, err)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d after a rejected key, want 0", m.Len())
+	}
+}
+
+func TestInvalidNaNPolicy(t *testing.T) {
+	if _, err := MakeHashMapWithNaNPolicy[float64, int](NaNPolicy(-1)); !errors.Is(err, ErrInvalidNaNPolicy) {
+		t.Fatalf("MakeHashMapWithNaNPolicy(-1) = %v, want ErrInvalidNaNPolicy", err)
+	}
+}
+
+// point{NaN} never equals itself, so every Set adds another key, and all of
+// them hash the same with every seed. Two share their two slots, a third
+// used to double the tables until allocation failed.
+type point struct {
+	X float64
+}
+
+func TestKeysThatNeverFit(t *testing.T) {
+	m := MakeHashMap[point, int]()
+	for i := 0; i < 100; i++ {
+		m.Set(point{float64(i)}, i)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.TrySet(point{math.NaN()}, -1); err != nil {
+			t.Fatalf("TrySet of NaN key %d = %v, want nil", i, err)
+		}
+	}
+	if err := m.TrySet(point{math.NaN()}, -1); !errors.Is(err, ErrCapacityOverflow) {
+		t.Fatalf("TrySet of a third NaN key = %v, want ErrCapacityOverflow", err)
+	}
+	if m.Len() != 102 {
+		t.Fatalf("Len() = %d, want 102", m.Len())
+	}
+	for i := 0; i < 100; i++ {
+		if value := m.Get(point{float64(i)}); value == nil || *value != i {
+			t.Fatalf("Get(%d) = %v after the failed TrySet, want %d", i, value, i)
+		}
+	}
+	nans := 0
+	for key := range m.Keys() {
+		if math.IsNaN(key.X) {
+			nans++
+		}
+	}
+	if nans != 2 {
+		t.Fatalf("%d NaN keys in the map, want the 2 that fit", nans)
+	}
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrCapacityOverflow) {
+			t.Fatalf("Set panicked with %v, want ErrCapacityOverflow", err)
+		}
+	}()
+	m.Set(point{math.NaN()}, -1)
+}
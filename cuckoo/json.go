@@ -0,0 +1,19 @@
+package cuckoo
+
+import "hashmaps/internal/mapjson"
+
+// MarshalJSON writes the map as internal/mapjson describes, the pairs of
+// non-string keys in the first table come before those in the second
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mapjson.Marshal(m.Range, m.Len())
+}
+
+// UnmarshalJSON adds the decoded entries through TrySet, which may kick
+// entries between the tables and grow them as a Set would. A zero HashMap,
+// e.g. one that json.Unmarshal allocated, is made ready first.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	if m.capacity == 0 {
+		*m = *MakeHashMap[K, V]()
+	}
+	return mapjson.Unmarshal(data, m.TrySet)
+}
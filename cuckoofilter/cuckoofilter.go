@@ -0,0 +1,235 @@
+// Package cuckoofilter is a cuckoo filter: a set that answers "maybe" or
+// "definitely not" in a fraction of the memory of the items, like a Bloom
+// filter, but that can also delete items.
+//
+// It stores a 16 bit fingerprint per item, in one of two buckets of 4
+// slots. The second bucket is computed from the first and the fingerprint
+// alone, so a fingerprint can be moved to its other bucket without knowing
+// the item, which is what makes cuckoo displacement and deletion work.
+// With 16 bit fingerprints the false positive rate stays below 0.02%
+// up to a 95% load.
+package cuckoofilter
+
+import (
+	"errors"
+	"math/bits"
+
+	"hashmaps/internal/hashing"
+)
+
+const (
+	bucketSize = 4
+	// 4 slot buckets fill up to about 95% before inserts start to fail
+	targetLoad = 0.95
+	// displacements before an item is declared homeless
+	maxKicks = 500
+)
+
+type fingerprint uint16 // 0 marks an empty slot
+
+type bucket [bucketSize]fingerprint
+
+// Filter is not safe for concurrent use.
+type Filter[T comparable] struct {
+	buckets []bucket
+	mask    uint64 // len(buckets) - 1, a power of two
+	count   int
+	seed    hashing.Seed // see internal/hashing
+
+	// victim holds the fingerprint left over when an insert ran out of
+	// kicks. It is still a member, but the filter takes no more items.
+	victim      fingerprint
+	victimIndex uint64
+
+	random uint64 // xorshift state, picks which slot to kick out
+}
+
+var (
+	ErrInvalidCapacity = errors.New("filter capacity must be positive")
+	ErrItemEncoding    = hashing.ErrKeyEncoding // the maps' ErrKeyEncoding
+)
+
+// MakeFilter sizes the filter to hold at least capacity items
+func MakeFilter[T comparable](capacity int) (*Filter[T], error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCapacity
+	}
+	buckets := int(float64(capacity)/(bucketSize*targetLoad)) + 1
+	buckets = 1 << bits.Len(uint(buckets-1)) // the alternate index is computed with a xor, so a power of two
+	return &Filter[T]{
+		buckets: make([]bucket, buckets),
+		mask:    uint64(buckets - 1),
+		seed:    hashing.MakeSeed[T](),
+		random:  0x9E3779B97F4A7C15,
+	}, nil
+}
+
+// Add, MayContain and Delete panic when the item can't be hashed, e.g. a gob encoding failure.
+// TryAdd, TryMayContain and TryDelete return such errors instead and never panic.
+
+// Add inserts item, it returns false when the filter is full.
+// Adding the same item twice stores it twice, and it has to be deleted twice.
+func (f *Filter[T]) Add(item T) bool {
+	added, err := f.TryAdd(item)
+	if err != nil {
+		panic(err)
+	}
+	return added
+}
+
+func (f *Filter[T]) TryAdd(item T) (bool, error) {
+	if f.victim != 0 {
+		return false, nil
+	}
+	index, fp, err := f.locate(item)
+	if err != nil {
+		return false, err
+	}
+	if f.buckets[index].insert(fp) || f.buckets[f.altIndex(index, fp)].insert(fp) {
+		f.count++
+		return true, nil
+	}
+	if f.nextRandom()&1 == 1 {
+		index = f.altIndex(index, fp)
+	}
+	for kick := 0; kick < maxKicks; kick++ {
+		slot := f.nextRandom() % bucketSize
+		fp, f.buckets[index][slot] = f.buckets[index][slot], fp
+		index = f.altIndex(index, fp)
+		if f.buckets[index].insert(fp) {
+			f.count++
+			return true, nil
+		}
+	}
+	// fp is a fingerprint that was already in the filter, possibly item's own,
+	// so it can't be dropped
+	f.victim, f.victimIndex = fp, index
+	f.count++
+	return true, nil
+}
+
+// MayContain is false when item was definitely never added, or was deleted.
+// True means it probably was added.
+func (f *Filter[T]) MayContain(item T) bool {
+	contained, err := f.TryMayContain(item)
+	if err != nil {
+		panic(err)
+	}
+	return contained
+}
+
+func (f *Filter[T]) TryMayContain(item T) (bool, error) {
+	index, fp, err := f.locate(item)
+	if err != nil {
+		return false, err
+	}
+	alt := f.altIndex(index, fp)
+	if f.victim == fp && (f.victimIndex == index || f.victimIndex == alt) {
+		return true, nil
+	}
+	return f.buckets[index].contains(fp) || f.buckets[alt].contains(fp), nil
+}
+
+// Delete removes one copy of item, it returns false when item wasn't found.
+// Only items that were added may be deleted: deleting anything else can
+// remove the fingerprint of a different item that happens to collide with it.
+func (f *Filter[T]) Delete(item T) bool {
+	deleted, err := f.TryDelete(item)
+	if err != nil {
+		panic(err)
+	}
+	return deleted
+}
+
+func (f *Filter[T]) TryDelete(item T) (bool, error) {
+	index, fp, err := f.locate(item)
+	if err != nil {
+		return false, err
+	}
+	alt := f.altIndex(index, fp)
+	switch {
+	case f.victim == fp && (f.victimIndex == index || f.victimIndex == alt):
+		f.victim = 0
+	case f.buckets[index].remove(fp) || f.buckets[alt].remove(fp):
+		f.reinsertVictim()
+	default:
+		return false, nil
+	}
+	f.count--
+	return true, nil
+}
+
+// Len returns the number of items added and not deleted
+func (f *Filter[T]) Len() int {
+	return f.count
+}
+
+// LoadFactor is the share of slots in use
+func (f *Filter[T]) LoadFactor() float64 {
+	return float64(f.count) / float64(len(f.buckets)*bucketSize)
+}
+
+// locate returns item's first bucket and its fingerprint
+func (f *Filter[T]) locate(item T) (uint64, fingerprint, error) {
+	hashedItem, err := hashing.Hash(f.seed, item)
+	if err != nil {
+		return 0, 0, err
+	}
+	fp := fingerprint(hashedItem >> 48) // the index comes from the low bits
+	if fp == 0 {
+		fp = 1
+	}
+	return hashedItem & f.mask, fp, nil
+}
+
+// altIndex maps each of a fingerprint's buckets to the other one
+func (f *Filter[T]) altIndex(index uint64, fp fingerprint) uint64 {
+	return (index ^ hashing.Mix(uint64(fp))) & f.mask
+}
+
+// reinsertVictim gives the victim a slot once a delete freed one up
+func (f *Filter[T]) reinsertVictim() {
+	if f.victim == 0 {
+		return
+	}
+	if f.buckets[f.victimIndex].insert(f.victim) || f.buckets[f.altIndex(f.victimIndex, f.victim)].insert(f.victim) {
+		f.victim = 0
+	}
+}
+
+// nextRandom is xorshift64, the kicks don't need a better source
+func (f *Filter[T]) nextRandom() uint64 {
+	f.random ^= f.random << 13
+	f.random ^= f.random >> 7
+	f.random ^= f.random << 17
+	return f.random
+}
+
+func (b *bucket) insert(fp fingerprint) bool {
+	for i := range b {
+		if b[i] == 0 {
+			b[i] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) contains(fp fingerprint) bool {
+	for i := range b {
+		if b[i] == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) remove(fp fingerprint) bool {
+	for i := range b {
+		if b[i] == fp {
+			b[i] = 0
+			return true
+		}
+	}
+	return false
+}
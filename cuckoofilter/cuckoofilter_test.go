@@ -0,0 +1,70 @@
+package cuckoofilter
+
+import "testing"
+
+// The false positive rate of a cuckoo filter is about 2*bucketSize/2^16 at
+// full load with 16 bit fingerprints, 0.012%. Every fill level has to stay
+// below 0.05%, so random variation doesn't make the test flaky.
+func TestFalsePositiveRate(t *testing.T) {
+	const (
+		capacity = 100_000
+		queries  = 200_000
+		maxRate  = 0.0005
+	)
+	for _, fill := range []float64{0.25, 0.5, 0.75, 0.95} {
+		f, err := MakeFilter[int](capacity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := int(fill * float64(len(f.buckets)*bucketSize)) // the table is rounded up, fill its slots
+		for i := 0; i < n; i++ {
+			if !f.Add(i) {
+				t.Fatalf("fill %v: Add(%d) failed", fill, i)
+			}
+		}
+		for i := 0; i < n; i++ {
+			if !f.MayContain(i) {
+				t.Fatalf("fill %v: false negative for %d", fill, i)
+			}
+		}
+		positives := 0
+		for i := n; i < n+queries; i++ { // never added
+			if f.MayContain(i) {
+				positives++
+			}
+		}
+		rate := float64(positives) / queries
+		t.Logf("fill %v: false positive rate %.4f%%", fill, 100*rate)
+		if rate > maxRate {
+			t.Errorf("fill %v: false positive rate %.4f%%, want below %.4f%%", fill, 100*rate, 100*maxRate)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	f, err := MakeFilter[string](100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Add("a")
+	f.Add("a")
+	f.Add("b")
+	if !f.Delete("a") || !f.MayContain("a") {
+		t.Fatal("an item added twice must still be there after one Delete")
+	}
+	if !f.Delete("a") || f.MayContain("a") {
+		t.Fatal("an item deleted as often as it was added must be gone")
+	}
+	if f.Delete("a") {
+		t.Fatal("Delete of a missing item succeeded")
+	}
+	if !f.MayContain("b") || f.Len() != 1 {
+		t.Fatalf("MayContain(%q) = %v, Len() = %d, want true, 1", "b", f.MayContain("b"), f.Len())
+	}
+}
+
+func TestInvalidCapacity(t *testing.T) {
+	if _, err := MakeFilter[int](0); err != ErrInvalidCapacity {
+		t.Fatalf("MakeFilter(0) = %v, want ErrInvalidCapacity", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Package dedupe answers "have I seen this recently?" in bounded memory,
+// e.g. to suppress replayed webhooks or redelivered stream messages.
+package dedupe
+
+import (
+	"iter"
+	"sync"
+	"time"
+)
+
+type entry[T comparable] struct {
+	item       T
+	expiresAt  int64
+	prev, next *entry[T]
+}
+
+// DedupeSet remembers items for ttl after they were first seen, but never more
+// than maxSize of them: when full, the oldest item is forgotten early.
+// All items share the same ttl, so the insertion order list is also the
+// expiration order and both expiry and eviction happen at its head in O(1).
+// It is safe for concurrent use.
+type DedupeSet[T comparable] struct {
+	mu      sync.Mutex
+	entries map[T]*entry[T]
+	oldest  *entry[T]
+	newest  *entry[T]
+	ttl     time.Duration
+	maxSize int
+	evicted int
+	now     func() time.Time
+}
+
+func MakeDedupeSet[T comparable](ttl time.Duration, maxSize int) *DedupeSet[T] {
+	return MakeDedupeSetWithClock[T](ttl, maxSize, time.Now)
+}
+
+func MakeDedupeSetWithClock[T comparable](ttl time.Duration, maxSize int, now func() time.Time) *DedupeSet[T] {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	return &DedupeSet[T]{
+		entries: make(map[T]*entry[T]),
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     now,
+	}
+}
+
+// Seen reports whether item was seen within the ttl, and remembers it if it wasn't.
+// Seeing an item again does not extend its ttl.
+func (s *DedupeSet[T]) Seen(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now().UnixNano()
+	s.expire(now)
+	if _, ok := s.entries[item]; ok {
+		return true
+	}
+	s.add(item, now)
+	return false
+}
+
+// Contains is Seen without remembering the item
+func (s *DedupeSet[T]) Contains(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(s.now().UnixNano())
+	_, ok := s.entries[item]
+	return ok
+}
+
+// Forget removes item, so it is considered new next time. It returns
+// false for items whose ttl already ran out, like Contains.
+func (s *DedupeSet[T]) Forget(item T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(s.now().UnixNano())
+	e, ok := s.entries[item]
+	if ok {
+		s.unlink(e)
+	}
+	return ok
+}
+
+func (s *DedupeSet[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(s.now().UnixNano())
+	return len(s.entries)
+}
+
+// Evicted counts items forgotten before their ttl because the set was full,
+// a growing number means maxSize is too small for the traffic
+func (s *DedupeSet[T]) Evicted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evicted
+}
+
+// All yields the remembered items from the oldest to the newest. It holds
+// the lock for the whole loop, so the loop body must not call methods of s.
+func (s *DedupeSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.expire(s.now().UnixNano())
+		for e := s.oldest; e != nil; e = e.next {
+			if !yield(e.item) {
+				return
+			}
+		}
+	}
+}
+
+func (s *DedupeSet[T]) add(item T, now int64) {
+	if len(s.entries) >= s.maxSize {
+		s.unlink(s.oldest)
+		s.evicted++
+	}
+	e := &entry[T]{item: item, expiresAt: now + int64(s.ttl), prev: s.newest}
+	if s.newest != nil {
+		s.newest.next = e
+	} else {
+		s.oldest = e
+	}
+	s.newest = e
+	s.entries[item] = e
+}
+
+func (s *DedupeSet[T]) expire(now int64) {
+	for s.oldest != nil && s.oldest.expiresAt <= now {
+		s.unlink(s.oldest)
+	}
+}
+
+func (s *DedupeSet[T]) unlink(e *entry[T]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		s.oldest = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		s.newest = e.prev
+	}
+	e.prev, e.next = nil, nil
+	delete(s.entries, e.item)
+}
@@ -0,0 +1,119 @@
+package dedupe
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type seen struct {
+	item      int
+	expiresAt time.Time
+}
+
+// The set must remember what a list of items in the order they were first
+// seen remembers, dropping expired ones and the oldest one when full
+func TestDedupeSetAgainstModel(t *testing.T) {
+	const ttl, maxSize = 10 * time.Second, 20
+	r := rand.New(rand.NewPCG(1, 2))
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	s := MakeDedupeSetWithClock[int](ttl, maxSize, clock.Now)
+	var model []seen // oldest first
+	evicted := 0
+	for step := 0; step < 5000; step++ {
+		if r.IntN(4) == 0 {
+			clock.now = clock.now.Add(time.Duration(r.IntN(int(time.Second))))
+		}
+		model = slices.DeleteFunc(model, func(e seen) bool { return !e.expiresAt.After(clock.now) })
+		item := r.IntN(60)
+		found := slices.ContainsFunc(model, func(e seen) bool { return e.item == item })
+		switch r.IntN(5) {
+		case 0, 1, 2:
+			if s.Seen(item) != found {
+				t.Fatalf("step %d: Seen(%d) = %v", step, item, !found)
+			}
+			if !found {
+				if len(model) == maxSize {
+					model = model[1:]
+					evicted++
+				}
+				model = append(model, seen{item: item, expiresAt: clock.now.Add(ttl)})
+			}
+		case 3:
+			if s.Contains(item) != found {
+				t.Fatalf("step %d: Contains(%d) = %v", step, item, !found)
+			}
+		case 4:
+			if s.Forget(item) != found {
+				t.Fatalf("step %d: Forget(%d) = %v", step, item, !found)
+			}
+			model = slices.DeleteFunc(model, func(e seen) bool { return e.item == item })
+		}
+		var want []int
+		for _, e := range model {
+			want = append(want, e.item)
+		}
+		if got := slices.Collect(s.All()); !slices.Equal(got, want) {
+			t.Fatalf("step %d: All() = %v, want %v", step, got, want)
+		}
+		if s.Len() != len(model) || s.Evicted() != evicted {
+			t.Fatalf("step %d: Len() = %d, Evicted() = %d, want %d and %d", step, s.Len(), s.Evicted(), len(model), evicted)
+		}
+	}
+	if evicted == 0 {
+		t.Fatal("the set never filled up, the test doesn't cover eviction")
+	}
+}
+
+func TestSeenAgainDoesNotExtendTheTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := MakeDedupeSetWithClock[string](time.Minute, 10, clock.Now)
+	s.Seen("a")
+	clock.now = clock.now.Add(59 * time.Second)
+	if !s.Seen("a") {
+		t.Fatal("a forgotten before its ttl")
+	}
+	clock.now = clock.now.Add(time.Second)
+	if s.Seen("a") {
+		t.Fatal("Seen(a) a minute after it was first seen = true, a repeat must not extend the ttl")
+	}
+	if tiny := MakeDedupeSetWithClock[string](time.Minute, 0, clock.Now); tiny.maxSize != 1 {
+		t.Fatalf("maxSize 0 became %d, want 1", tiny.maxSize)
+	}
+}
+
+// Of all goroutines seeing an item at once, exactly one must learn it is new
+func TestSeenConcurrent(t *testing.T) {
+	s := MakeDedupeSet[int](time.Hour, 1000)
+	var firsts [100]atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range firsts {
+				if !s.Seen(item) {
+					firsts[item].Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for item := range firsts {
+		if got := firsts[item].Load(); got != 1 {
+			t.Fatalf("%d goroutines saw %d as new, want 1", got, item)
+		}
+	}
+}
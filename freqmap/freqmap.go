@@ -0,0 +1,213 @@
+// Package freqmap provides a map that keeps its entries ordered by how often
+// they were accessed, so the hottest keys can be listed without sorting.
+package freqmap
+
+import "iter"
+
+type entry[K comparable, V any] struct {
+	key        K
+	value      V
+	bucket     *bucket[K, V]
+	prev, next *entry[K, V] // neighbours within the bucket
+}
+
+// bucket groups all entries accessed exactly count times
+type bucket[K comparable, V any] struct {
+	count          int
+	head           *entry[K, V]
+	hotter, colder *bucket[K, V]
+}
+
+// FrequencyMap counts every Get and Set of a key. Entries live in buckets
+// of equal count chained from the hottest to the coldest, so bumping a count
+// is O(1) and TopN is O(n) without any sorting.
+type FrequencyMap[K comparable, V any] struct {
+	entries map[K]*entry[K, V]
+	hottest *bucket[K, V]
+	coldest *bucket[K, V]
+}
+
+func MakeFrequencyMap[K comparable, V any]() *FrequencyMap[K, V] {
+	return &FrequencyMap[K, V]{entries: make(map[K]*entry[K, V])}
+}
+
+func (m *FrequencyMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+// Get returns the value and counts it as an access
+func (m *FrequencyMap[K, V]) Get(key K) (V, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.touch(e)
+	return e.value, true
+}
+
+// Peek returns the value without counting an access
+func (m *FrequencyMap[K, V]) Peek(key K) (V, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set counts as an access as well, a new key starts with count 1
+func (m *FrequencyMap[K, V]) Set(key K, value V) {
+	if e, ok := m.entries[key]; ok {
+		e.value = value
+		m.touch(e)
+		return
+	}
+	e := &entry[K, V]{key: key, value: value}
+	m.entries[key] = e
+	target := m.coldest
+	if target == nil || target.count != 1 {
+		target = &bucket[K, V]{count: 1}
+		m.linkBucket(target, m.coldest, nil)
+	}
+	target.add(e)
+}
+
+func (m *FrequencyMap[K, V]) Delete(key K) bool {
+	e, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	delete(m.entries, key)
+	m.detach(e)
+	return true
+}
+
+// Count returns how many times key was accessed, 0 for missing keys
+func (m *FrequencyMap[K, V]) Count(key K) int {
+	if e, ok := m.entries[key]; ok {
+		return e.bucket.count
+	}
+	return 0
+}
+
+// TopN returns up to n keys starting from the most accessed one.
+// Keys with equal counts are ordered by the time they reached that count, latest first.
+func (m *FrequencyMap[K, V]) TopN(n int) []K {
+	if n > len(m.entries) {
+		n = len(m.entries)
+	}
+	if n <= 0 {
+		return nil
+	}
+	top := make([]K, 0, n)
+	for b := m.hottest; b != nil; b = b.colder {
+		for e := b.head; e != nil; e = e.next {
+			top = append(top, e.key)
+			if len(top) == n {
+				return top
+			}
+		}
+	}
+	return top
+}
+
+// Range visits entries from the hottest to the coldest until fn returns false
+func (m *FrequencyMap[K, V]) Range(fn func(key K, value V, count int) bool) {
+	for b := m.hottest; b != nil; b = b.colder {
+		for e := b.head; e != nil; e = e.next {
+			if !fn(e.key, e.value, b.count) {
+				return
+			}
+		}
+	}
+}
+
+// All visits entries from the hottest to the coldest, like Range without the counts
+func (m *FrequencyMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.Range(func(key K, value V, _ int) bool {
+			return yield(key, value)
+		})
+	}
+}
+
+// Reset forgets all counts but keeps the entries, e.g. to start a new sampling window
+func (m *FrequencyMap[K, V]) Reset() {
+	m.hottest, m.coldest = nil, nil
+	if len(m.entries) == 0 {
+		return
+	}
+	fresh := &bucket[K, V]{count: 1}
+	m.linkBucket(fresh, nil, nil)
+	for _, e := range m.entries {
+		e.prev, e.next = nil, nil
+		fresh.add(e)
+	}
+}
+
+func (m *FrequencyMap[K, V]) touch(e *entry[K, V]) {
+	current := e.bucket
+	target := current.hotter
+	if target == nil || target.count != current.count+1 {
+		target = &bucket[K, V]{count: current.count + 1}
+		m.linkBucket(target, current.hotter, current)
+	}
+	m.detach(e)
+	target.add(e)
+}
+
+// detach takes e out of its bucket, dropping the bucket when it becomes empty
+func (m *FrequencyMap[K, V]) detach(e *entry[K, V]) {
+	b := e.bucket
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		b.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	}
+	e.prev, e.next, e.bucket = nil, nil, nil
+	if b.head == nil {
+		m.unlinkBucket(b)
+	}
+}
+
+// linkBucket puts b between hotter and colder, either of them may be nil
+func (m *FrequencyMap[K, V]) linkBucket(b, hotter, colder *bucket[K, V]) {
+	b.hotter, b.colder = hotter, colder
+	if hotter != nil {
+		hotter.colder = b
+	} else {
+		m.hottest = b
+	}
+	if colder != nil {
+		colder.hotter = b
+	} else {
+		m.coldest = b
+	}
+}
+
+func (m *FrequencyMap[K, V]) unlinkBucket(b *bucket[K, V]) {
+	if b.hotter != nil {
+		b.hotter.colder = b.colder
+	} else {
+		m.hottest = b.colder
+	}
+	if b.colder != nil {
+		b.colder.hotter = b.hotter
+	} else {
+		m.coldest = b.hotter
+	}
+}
+
+func (b *bucket[K, V]) add(e *entry[K, V]) {
+	e.bucket = b
+	e.prev = nil
+	e.next = b.head
+	if b.head != nil {
+		b.head.prev = e
+	}
+	b.head = e
+}
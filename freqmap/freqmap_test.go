@@ -0,0 +1,108 @@
+package freqmap
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// modelEntry is what the map should know about a key: its value, its
+// count, and when it reached that count, 0 for all keys after Reset
+type modelEntry struct {
+	value, count, reached int
+}
+
+// The map must agree with a plain map of counts after any mix of calls, and
+// Range must go from the highest count down, latest to reach a count first
+func TestFrequencyMapAgainstModel(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	m := MakeFrequencyMap[int, int]()
+	model := map[int]*modelEntry{}
+	for step := 1; step <= 5000; step++ {
+		key := r.IntN(30)
+		e := model[key]
+		switch op := r.IntN(20); {
+		case op < 8:
+			value, ok := m.Get(key)
+			if ok != (e != nil) || ok && value != e.value {
+				t.Fatalf("step %d: Get(%d) = %d, %v", step, key, value, ok)
+			}
+			if ok {
+				e.count, e.reached = e.count+1, step
+			}
+		case op < 10:
+			value, ok := m.Peek(key)
+			if ok != (e != nil) || ok && value != e.value {
+				t.Fatalf("step %d: Peek(%d) = %d, %v", step, key, value, ok)
+			}
+		case op < 17:
+			m.Set(key, step)
+			if e == nil {
+				model[key] = &modelEntry{value: step, count: 1, reached: step}
+			} else {
+				e.value, e.count, e.reached = step, e.count+1, step
+			}
+		case op < 19:
+			if m.Delete(key) != (e != nil) {
+				t.Fatalf("step %d: Delete(%d) = %v", step, key, e == nil)
+			}
+			delete(model, key)
+		default:
+			m.Reset()
+			for _, e := range model {
+				e.count, e.reached = 1, 0
+			}
+		}
+		if m.Len() != len(model) {
+			t.Fatalf("step %d: Len() = %d, want %d", step, m.Len(), len(model))
+		}
+		want := 0
+		if e := model[key]; e != nil {
+			want = e.count
+		}
+		if m.Count(key) != want {
+			t.Fatalf("step %d: Count(%d) = %d, want %d", step, key, m.Count(key), want)
+		}
+		var ranged []int
+		var previous *modelEntry
+		m.Range(func(key, value, count int) bool {
+			e := model[key]
+			if e == nil || e.value != value || e.count != count {
+				t.Fatalf("step %d: Range passed %d=%d with count %d, want %+v", step, key, value, count, e)
+			}
+			if previous != nil && (previous.count < e.count || previous.count == e.count && previous.reached < e.reached) {
+				t.Fatalf("step %d: Range passed %+v after %+v", step, e, previous)
+			}
+			previous = e
+			ranged = append(ranged, key)
+			return true
+		})
+		if len(ranged) != len(model) {
+			t.Fatalf("step %d: Range visited %d of %d entries", step, len(ranged), len(model))
+		}
+		if n := r.IntN(len(model) + 3); !slices.Equal(m.TopN(n), ranged[:min(n, len(ranged))]) {
+			t.Fatalf("step %d: TopN(%d) = %v, want the start of %v", step, n, m.TopN(n), ranged)
+		}
+	}
+}
+
+func TestTopNEdges(t *testing.T) {
+	m := MakeFrequencyMap[string, int]()
+	if top := m.TopN(3); top != nil {
+		t.Fatalf("TopN(3) of an empty map = %v", top)
+	}
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Get("a")
+	if top := m.TopN(-1); top != nil {
+		t.Fatalf("TopN(-1) = %v", top)
+	}
+	if top := m.TopN(10); !slices.Equal(top, []string{"a", "b"}) {
+		t.Fatalf("TopN(10) = %v, want [a b]", top)
+	}
+	m.Delete("a")
+	m.Delete("b")
+	if m.hottest != nil || m.coldest != nil {
+		t.Fatal("deleting every key left buckets behind")
+	}
+}
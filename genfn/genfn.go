@@ -0,0 +1,76 @@
+// Package genfn has the usual functional helpers over slices. They combine
+// with the collections through their iterators, e.g.
+//
+//	long := genfn.Filter(slices.Collect(m.Keys()), func(key string) bool { return len(key) > 8 })
+//
+// Every function returns a new slice and leaves its input alone.
+package genfn
+
+// Map applies f to every element
+func Map[T, U any](s []T, f func(T) U) []U {
+	mapped := make([]U, len(s))
+	for i, item := range s {
+		mapped[i] = f(item)
+	}
+	return mapped
+}
+
+// Filter keeps the elements keep returns true for, in their order
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var kept []T
+	for _, item := range s {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// Reduce folds the elements into initial from left to right
+func Reduce[T, A any](s []T, initial A, f func(acc A, item T) A) A {
+	acc := initial
+	for _, item := range s {
+		acc = f(acc, item)
+	}
+	return acc
+}
+
+// Any is false for an empty slice
+func Any[T any](s []T, pred func(T) bool) bool {
+	for _, item := range s {
+		if pred(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// All is true for an empty slice
+func All[T any](s []T, pred func(T) bool) bool {
+	for _, item := range s {
+		if !pred(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the first element pred returns true for
+func Find[T any](s []T, pred func(T) bool) (T, bool) {
+	for _, item := range s {
+		if pred(item) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func Contains[T comparable](s []T, value T) bool {
+	for _, item := range s {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
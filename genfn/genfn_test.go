@@ -0,0 +1,109 @@
+package genfn
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func isEven(n int) bool { return n%2 == 0 }
+
+func TestMap(t *testing.T) {
+	in := []int{1, 2, 3}
+	got := Map(in, strconv.Itoa)
+	if !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Fatalf("Map = %q", got)
+	}
+	if got := Map([]int(nil), strconv.Itoa); len(got) != 0 {
+		t.Fatalf("Map(nil) = %q, want empty", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5, 6}
+	got := Filter(in, isEven)
+	if !slices.Equal(got, []int{2, 4, 6}) {
+		t.Fatalf("Filter = %v, want [2 4 6]", got)
+	}
+	got[0] = 100
+	if !slices.Equal(in, []int{1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("Filter shares memory with its input, input is now %v", in)
+	}
+	if got := Filter(in, func(int) bool { return false }); len(got) != 0 {
+		t.Fatalf("Filter of nothing = %v", got)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	// left to right: ((">" + "a") + "b") + "c"
+	got := Reduce([]string{"a", "b", "c"}, ">", func(acc string, item string) string { return acc + item })
+	if got != ">abc" {
+		t.Fatalf("Reduce = %q, want %q", got, ">abc")
+	}
+	if got := Reduce(nil, 42, func(acc, item int) int { return acc + item }); got != 42 {
+		t.Fatalf("Reduce of an empty slice = %d, want the initial value", got)
+	}
+	sum := Reduce([]int{1, 2, 3, 4}, 0, func(acc, item int) int { return acc + item })
+	if sum != 10 {
+		t.Fatalf("sum = %d, want 10", sum)
+	}
+}
+
+func TestAnyAll(t *testing.T) {
+	cases := []struct {
+		in       []int
+		any, all bool
+	}{
+		{nil, false, true},
+		{[]int{1, 3}, false, false},
+		{[]int{1, 2}, true, false},
+		{[]int{2, 4}, true, true},
+	}
+	for _, c := range cases {
+		if got := Any(c.in, isEven); got != c.any {
+			t.Errorf("Any(%v) = %v, want %v", c.in, got, c.any)
+		}
+		if got := All(c.in, isEven); got != c.all {
+			t.Errorf("All(%v) = %v, want %v", c.in, got, c.all)
+		}
+	}
+}
+
+func TestShortCircuit(t *testing.T) {
+	in := []int{1, 2, 3, 4}
+	calls := 0
+	counting := func(pred func(int) bool) func(int) bool {
+		calls = 0
+		return func(n int) bool {
+			calls++
+			return pred(n)
+		}
+	}
+	Any(in, counting(isEven))
+	if calls != 2 {
+		t.Errorf("Any called pred %d times, want 2", calls)
+	}
+	All(in, counting(func(n int) bool { return n < 2 }))
+	if calls != 2 {
+		t.Errorf("All called pred %d times, want 2", calls)
+	}
+	Find(in, counting(isEven))
+	if calls != 2 {
+		t.Errorf("Find called pred %d times, want 2", calls)
+	}
+}
+
+func TestFind(t *testing.T) {
+	if got, ok := Find([]int{1, 4, 6}, isEven); !ok || got != 4 {
+		t.Fatalf("Find = %d, %v, want the first match 4, true", got, ok)
+	}
+	if got, ok := Find([]int{1, 3}, isEven); ok || got != 0 {
+		t.Fatalf("Find without a match = %d, %v, want 0, false", got, ok)
+	}
+}
+
+func TestContains(t *testing.T) {
+	if !Contains([]string{"a", "b"}, "b") || Contains([]string{"a", "b"}, "c") || Contains(nil, "a") {
+		t.Fatal("Contains is wrong")
+	}
+}
@@ -0,0 +1,72 @@
+package genfn
+
+import (
+	"fmt"
+
+	"hashmaps/hashset"
+)
+
+// Chunk splits s into slices of n elements, the last one may be shorter.
+// The chunks share memory with s but are capped, so appending to one
+// doesn't overwrite the next. n < 1 panics.
+func Chunk[T any](s []T, n int) [][]T {
+	if n < 1 {
+		panic(fmt.Sprintf("genfn: chunk size %d", n))
+	}
+	chunks := make([][]T, 0, (len(s)+n-1)/n)
+	for start := 0; start < len(s); start += n {
+		end := min(start+n, len(s))
+		chunks = append(chunks, s[start:end:end])
+	}
+	return chunks
+}
+
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip pairs a[i] with b[i], stopping at the end of the shorter slice
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	pairs := make([]Pair[A, B], min(len(a), len(b)))
+	for i := range pairs {
+		pairs[i] = Pair[A, B]{First: a[i], Second: b[i]}
+	}
+	return pairs
+}
+
+// Flatten concatenates the slices of s
+func Flatten[T any](s [][]T) []T {
+	n := 0
+	for _, inner := range s {
+		n += len(inner)
+	}
+	flat := make([]T, 0, n)
+	for _, inner := range s {
+		flat = append(flat, inner...)
+	}
+	return flat
+}
+
+// Unique drops repeated elements, keeping the first of each in order.
+// Elements are compared the way hashset.Set does, so NaNs are one value.
+func Unique[T comparable](s []T) []T {
+	seen := hashset.MakeSet[T]()
+	var unique []T
+	for _, item := range s {
+		if seen.Add(item) {
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
+
+// Reverse returns the elements of s last to first, unlike slices.Reverse
+// it leaves s alone
+func Reverse[T any](s []T) []T {
+	reversed := make([]T, len(s))
+	for i, item := range s {
+		reversed[len(s)-1-i] = item
+	}
+	return reversed
+}
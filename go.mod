@@ -1,3 +1,3 @@
 module hashmaps
 
-go 1.19
+go 1.23
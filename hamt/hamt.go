@@ -0,0 +1,368 @@
+// Package hamt is an immutable map: Set and Delete return a new map and
+// leave the old one as it was, so a map is a snapshot that can be kept
+// around or shared between goroutines without locks.
+//
+// It is a hash array mapped trie. Every level of the trie picks one of 32
+// children with the next 5 bits of the key hash, and a node only stores
+// the children that exist, found through a bitmap. An update copies the
+// nodes on the path to the key, O(log32 n) of them, and shares the rest
+// of the trie with the old map.
+package hamt
+
+import (
+	"iter"
+	"math/bits"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+const (
+	bitsPerLevel = 5
+	hashBits     = 64
+)
+
+var ErrKeyEncoding = hashing.ErrKeyEncoding
+
+type leaf[K comparable, V any] struct {
+	hash  uint64
+	key   K
+	value V
+}
+
+// entry is either a leaf or a child node
+type entry[K comparable, V any] struct {
+	leaf  *leaf[K, V]
+	child *node[K, V]
+}
+
+// node holds the entries whose bits are set in bitmap, in bit order.
+// Once the hash is used up, keys with equal hashes go to collisions
+// instead, in a node without a bitmap.
+type node[K comparable, V any] struct {
+	bitmap     uint32
+	entries    []entry[K, V]
+	collisions []*leaf[K, V]
+}
+
+// PersistentMap is never modified after it is made, the zero value isn't
+// usable, start from MakePersistentMap
+type PersistentMap[K comparable, V any] struct {
+	root   *node[K, V] // nil when empty
+	length int
+	seed   hashing.Seed // shared by every map made from the same MakePersistentMap, so they can share nodes
+	// every NaN is the same key and -0 the same key as 0, unlike in the
+	// built-in map where a NaN key can never be found again
+	norm keynorm.Normalizer[K]
+}
+
+func MakePersistentMap[K comparable, V any]() *PersistentMap[K, V] {
+	norm, _ := keynorm.MakeNormalizer[K](keynorm.CanonicalizeNaN) // always valid
+	return &PersistentMap[K, V]{seed: hashing.MakeSeed[K](), norm: norm}
+}
+
+// Get, Set and Delete panic when the key can't be hashed, e.g. a gob encoding failure.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *PersistentMap[K, V]) Get(key K) (V, bool) {
+	value, ok, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+func (m *PersistentMap[K, V]) TryGet(key K) (V, bool, error) {
+	key, _ = m.norm.Normalize(key) // never fails under CanonicalizeNaN
+	hash, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+	value, ok := m.lookup(hash, key)
+	return value, ok, nil
+}
+
+// lookup, with and without take the hash of key instead of computing it
+func (m *PersistentMap[K, V]) lookup(hash uint64, key K) (V, bool) {
+	var zero V
+	n := m.root
+	for shift := 0; n != nil && shift < hashBits; shift += bitsPerLevel {
+		b := bit(hash, shift)
+		if n.bitmap&b == 0 {
+			return zero, false
+		}
+		e := n.entries[n.index(b)]
+		if e.leaf != nil {
+			if e.leaf.hash == hash && m.norm.Equal(e.leaf.key, key) {
+				return e.leaf.value, true
+			}
+			return zero, false
+		}
+		n = e.child
+	}
+	if n != nil { // the hash is used up
+		for _, l := range n.collisions {
+			if m.norm.Equal(l.key, key) {
+				return l.value, true
+			}
+		}
+	}
+	return zero, false
+}
+
+// Set returns a map with key set to value
+func (m *PersistentMap[K, V]) Set(key K, value V) *PersistentMap[K, V] {
+	updated, err := m.TrySet(key, value)
+	if err != nil {
+		panic(err)
+	}
+	return updated
+}
+
+func (m *PersistentMap[K, V]) TrySet(key K, value V) (*PersistentMap[K, V], error) {
+	key, _ = m.norm.Normalize(key) // never fails under CanonicalizeNaN
+	hash, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return nil, err
+	}
+	return m.with(hash, key, value), nil
+}
+
+func (m *PersistentMap[K, V]) with(hash uint64, key K, value V) *PersistentMap[K, V] {
+	root, added := m.set(m.root, &leaf[K, V]{hash: hash, key: key, value: value}, 0)
+	updated := *m
+	updated.root = root
+	if added {
+		updated.length++
+	}
+	return &updated
+}
+
+// Delete returns a map without key, m itself when key isn't there
+func (m *PersistentMap[K, V]) Delete(key K) *PersistentMap[K, V] {
+	updated, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return updated
+}
+
+func (m *PersistentMap[K, V]) TryDelete(key K) (*PersistentMap[K, V], error) {
+	key, _ = m.norm.Normalize(key) // never fails under CanonicalizeNaN
+	hash, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return nil, err
+	}
+	return m.without(hash, key), nil
+}
+
+func (m *PersistentMap[K, V]) without(hash uint64, key K) *PersistentMap[K, V] {
+	root, removed := m.delete(m.root, hash, key, 0)
+	if !removed {
+		return m
+	}
+	updated := *m
+	updated.root = root
+	updated.length--
+	return &updated
+}
+
+// Len returns the number of entries, in O(1)
+func (m *PersistentMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, in an order
+// that depends on the hashes
+func (m *PersistentMap[K, V]) Range(fn func(key K, value V) bool) {
+	if m.root != nil {
+		m.root.walk(fn)
+	}
+}
+
+// All is the range-over-func form of Range. Unlike the mutable maps,
+// Set and Delete may be called during the loop, they don't affect m.
+func (m *PersistentMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *PersistentMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *PersistentMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// bit is the bitmap bit of hash at the level starting at shift
+func bit(hash uint64, shift int) uint32 {
+	return 1 << ((hash >> shift) & (1<<bitsPerLevel - 1))
+}
+
+// index is the position in entries of the entry for b
+func (n *node[K, V]) index(b uint32) int {
+	return bits.OnesCount32(n.bitmap & (b - 1))
+}
+
+// set returns a copy of n with l in it, n itself is left alone
+func (m *PersistentMap[K, V]) set(n *node[K, V], l *leaf[K, V], shift int) (*node[K, V], bool) {
+	if n == nil {
+		return leafNode(l, shift), true
+	}
+	if shift >= hashBits {
+		for i, existing := range n.collisions {
+			if m.norm.Equal(existing.key, l.key) {
+				return &node[K, V]{collisions: replaced(n.collisions, i, l)}, false
+			}
+		}
+		return &node[K, V]{collisions: append(n.collisions[:len(n.collisions):len(n.collisions)], l)}, true
+	}
+	b := bit(l.hash, shift)
+	i := n.index(b)
+	if n.bitmap&b == 0 {
+		return &node[K, V]{bitmap: n.bitmap | b, entries: inserted(n.entries, i, entry[K, V]{leaf: l})}, true
+	}
+	switch e := n.entries[i]; {
+	case e.child != nil:
+		child, added := m.set(e.child, l, shift+bitsPerLevel)
+		return &node[K, V]{bitmap: n.bitmap, entries: replaced(n.entries, i, entry[K, V]{child: child})}, added
+	case e.leaf.hash == l.hash && m.norm.Equal(e.leaf.key, l.key):
+		return &node[K, V]{bitmap: n.bitmap, entries: replaced(n.entries, i, entry[K, V]{leaf: l})}, false
+	default:
+		child := mergeLeaves(e.leaf, l, shift+bitsPerLevel)
+		return &node[K, V]{bitmap: n.bitmap, entries: replaced(n.entries, i, entry[K, V]{child: child})}, true
+	}
+}
+
+func leafNode[K comparable, V any](l *leaf[K, V], shift int) *node[K, V] {
+	if shift >= hashBits {
+		return &node[K, V]{collisions: []*leaf[K, V]{l}}
+	}
+	return &node[K, V]{bitmap: bit(l.hash, shift), entries: []entry[K, V]{{leaf: l}}}
+}
+
+// mergeLeaves makes the node holding two leaves that shared a slot one level up
+func mergeLeaves[K comparable, V any](a, b *leaf[K, V], shift int) *node[K, V] {
+	if shift >= hashBits {
+		return &node[K, V]{collisions: []*leaf[K, V]{a, b}}
+	}
+	bitA, bitB := bit(a.hash, shift), bit(b.hash, shift)
+	switch {
+	case bitA == bitB:
+		return &node[K, V]{bitmap: bitA, entries: []entry[K, V]{{child: mergeLeaves(a, b, shift+bitsPerLevel)}}}
+	case bitA < bitB:
+		return &node[K, V]{bitmap: bitA | bitB, entries: []entry[K, V]{{leaf: a}, {leaf: b}}}
+	default:
+		return &node[K, V]{bitmap: bitA | bitB, entries: []entry[K, V]{{leaf: b}, {leaf: a}}}
+	}
+}
+
+// delete returns a copy of n without key, nil when nothing is left.
+// A node left with a single leaf is replaced by the leaf one level up,
+// so the trie doesn't keep chains of nodes that were needed for keys
+// which are gone.
+func (m *PersistentMap[K, V]) delete(n *node[K, V], hash uint64, key K, shift int) (*node[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	if shift >= hashBits {
+		for i, l := range n.collisions {
+			if m.norm.Equal(l.key, key) {
+				if len(n.collisions) == 1 {
+					return nil, true
+				}
+				return &node[K, V]{collisions: removed(n.collisions, i)}, true
+			}
+		}
+		return n, false
+	}
+	b := bit(hash, shift)
+	if n.bitmap&b == 0 {
+		return n, false
+	}
+	i := n.index(b)
+	e := n.entries[i]
+	if e.leaf != nil {
+		if e.leaf.hash != hash || !m.norm.Equal(e.leaf.key, key) {
+			return n, false
+		}
+		if len(n.entries) == 1 {
+			return nil, true
+		}
+		return &node[K, V]{bitmap: n.bitmap &^ b, entries: removed(n.entries, i)}, true
+	}
+	child, ok := m.delete(e.child, hash, key, shift+bitsPerLevel)
+	switch {
+	case !ok:
+		return n, false
+	case child == nil && len(n.entries) == 1:
+		return nil, true
+	case child == nil:
+		return &node[K, V]{bitmap: n.bitmap &^ b, entries: removed(n.entries, i)}, true
+	case child.single() != nil:
+		return &node[K, V]{bitmap: n.bitmap, entries: replaced(n.entries, i, entry[K, V]{leaf: child.single()})}, true
+	default:
+		return &node[K, V]{bitmap: n.bitmap, entries: replaced(n.entries, i, entry[K, V]{child: child})}, true
+	}
+}
+
+// single returns the only leaf of n, nil when n holds more or a child node
+func (n *node[K, V]) single() *leaf[K, V] {
+	switch {
+	case len(n.collisions) == 1:
+		return n.collisions[0]
+	case len(n.entries) == 1:
+		return n.entries[0].leaf
+	}
+	return nil
+}
+
+func (n *node[K, V]) walk(fn func(key K, value V) bool) bool {
+	for _, l := range n.collisions {
+		if !fn(l.key, l.value) {
+			return false
+		}
+	}
+	for _, e := range n.entries {
+		if e.leaf != nil {
+			if !fn(e.leaf.key, e.leaf.value) {
+				return false
+			}
+		} else if !e.child.walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// inserted, replaced and removed copy s, nodes are never modified in place
+
+func inserted[T any](s []T, i int, item T) []T {
+	copied := make([]T, len(s)+1)
+	copy(copied, s[:i])
+	copied[i] = item
+	copy(copied[i+1:], s[i:])
+	return copied
+}
+
+func replaced[T any](s []T, i int, item T) []T {
+	copied := make([]T, len(s))
+	copy(copied, s)
+	copied[i] = item
+	return copied
+}
+
+func removed[T any](s []T, i int) []T {
+	copied := make([]T, 0, len(s)-1)
+	copied = append(copied, s[:i]...)
+	return append(copied, s[i+1:]...)
+}
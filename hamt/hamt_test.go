@@ -0,0 +1,146 @@
+package hamt
+
+import (
+	"maps"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// collidingHash gives keys with the same remainder mod 4 the same full
+// hash, so they end up in collision nodes below the last level
+func collidingHash(key int) uint64 {
+	return uint64(key%4) * 0x9e3779b97f4a7c15
+}
+
+// checkVersion fails when m doesn't hold exactly the entries of want
+func checkVersion(t *testing.T, version int, m *PersistentMap[int, int], want map[int]int, get func(m *PersistentMap[int, int], key int) (int, bool)) {
+	t.Helper()
+	if m.Len() != len(want) {
+		t.Fatalf("version %d: Len() = %d, want %d", version, m.Len(), len(want))
+	}
+	for key, value := range want {
+		if got, ok := get(m, key); !ok || got != value {
+			t.Fatalf("version %d: Get(%d) = %d, %v, want %d", version, key, got, ok, value)
+		}
+	}
+	if got := maps.Collect(m.All()); !maps.Equal(got, want) {
+		t.Fatalf("version %d: All() = %v, want %v", version, got, want)
+	}
+}
+
+// Every version must keep its entries, whatever Set and Delete did to the
+// versions made from it later. Each step branches off a random earlier
+// version, so versions share nodes in every combination.
+func TestOldVersionsAreUnaffected(t *testing.T) {
+	for name, hashes := range map[string]struct {
+		set    func(m *PersistentMap[int, int], key, value int) *PersistentMap[int, int]
+		delete func(m *PersistentMap[int, int], key int) *PersistentMap[int, int]
+		get    func(m *PersistentMap[int, int], key int) (int, bool)
+	}{
+		"built-in hash": {
+			set:    (*PersistentMap[int, int]).Set,
+			delete: (*PersistentMap[int, int]).Delete,
+			get:    (*PersistentMap[int, int]).Get,
+		},
+		"colliding hash": {
+			set: func(m *PersistentMap[int, int], key, value int) *PersistentMap[int, int] {
+				return m.with(collidingHash(key), key, value)
+			},
+			delete: func(m *PersistentMap[int, int], key int) *PersistentMap[int, int] {
+				return m.without(collidingHash(key), key)
+			},
+			get: func(m *PersistentMap[int, int], key int) (int, bool) {
+				return m.lookup(collidingHash(key), key)
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(rand.NewPCG(1, 2))
+			versions := []*PersistentMap[int, int]{MakePersistentMap[int, int]()}
+			models := []map[int]int{{}}
+			for i := 1; i < 2000; i++ {
+				from := r.IntN(len(versions))
+				if r.IntN(8) != 0 { // mostly build on a recent version, so maps grow
+					from = max(0, len(versions)-1-r.IntN(5))
+				}
+				m, model := versions[from], maps.Clone(models[from])
+				key := r.IntN(200)
+				if r.IntN(3) == 0 {
+					m = hashes.delete(m, key)
+					delete(model, key)
+				} else {
+					m = hashes.set(m, key, i)
+					model[key] = i
+				}
+				versions = append(versions, m)
+				models = append(models, model)
+			}
+			for i := range versions {
+				checkVersion(t, i, versions[i], models[i], hashes.get)
+			}
+		})
+	}
+}
+
+// collisions returns the most leaves found in one collision node
+func collisions[K comparable, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	most := len(n.collisions)
+	for _, e := range n.entries {
+		if e.child != nil {
+			most = max(most, collisions(e.child))
+		}
+	}
+	return most
+}
+
+// Keys with equal hashes share a collision node, which is copied on every
+// change like any other node
+func TestCollisionNodePersistence(t *testing.T) {
+	get := func(m *PersistentMap[int, int], key int) (int, bool) { return m.lookup(collidingHash(key), key) }
+	m0 := MakePersistentMap[int, int]()
+	m1 := m0.with(collidingHash(1), 1, 10).with(collidingHash(5), 5, 50).with(collidingHash(9), 9, 90)
+	m1 = m1.with(collidingHash(2), 2, 20) // a different hash next to the collision node
+	m2 := m1.without(collidingHash(5), 5)
+	m3 := m2.with(collidingHash(1), 1, 11)
+	m4 := m3.without(collidingHash(9), 9) // a single leaf left, it moves up the trie
+	m5 := m4.without(collidingHash(1), 1).without(collidingHash(2), 2)
+	if collisions(m1.root) != 3 || collisions(m2.root) != 2 {
+		t.Fatalf("collision nodes of %d and %d leaves, want 3 and 2", collisions(m1.root), collisions(m2.root))
+	}
+	if m1.without(collidingHash(13), 13) != m1 {
+		t.Fatal("Delete of a missing key with a colliding hash made a new map")
+	}
+	for i, tc := range []struct {
+		m    *PersistentMap[int, int]
+		want map[int]int
+	}{
+		{m0, map[int]int{}},
+		{m1, map[int]int{1: 10, 5: 50, 9: 90, 2: 20}},
+		{m2, map[int]int{1: 10, 9: 90, 2: 20}},
+		{m3, map[int]int{1: 11, 9: 90, 2: 20}},
+		{m4, map[int]int{1: 11, 2: 20}},
+		{m5, map[int]int{}},
+	} {
+		checkVersion(t, i, tc.m, tc.want, get)
+	}
+	if m5.root != nil {
+		t.Fatal("deleting every key left nodes behind")
+	}
+}
+
+func TestNaNAndZeroKeys(t *testing.T) {
+	m := MakePersistentMap[float64, string]().Set(math.NaN(), "nan").Set(0, "zero")
+	if value, ok := m.Get(math.NaN()); !ok || value != "nan" {
+		t.Fatalf("Get(NaN) = %q, %v, want every NaN to be the same key", value, ok)
+	}
+	if value, ok := m.Set(math.Copysign(0, -1), "negative zero").Get(0); !ok || value != "negative zero" || m.Len() != 2 {
+		t.Fatalf("Get(0) = %q, %v after setting -0, want -0 to be the same key as 0", value, ok)
+	}
+	if value, _ := m.Get(0); value != "zero" {
+		t.Fatalf("Get(0) = %q on the old map, want zero", value)
+	}
+}
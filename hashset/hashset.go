@@ -0,0 +1,147 @@
+// Package hashset contains sets built on chainedmap.HashMap, so they take
+// the same keys as the maps do, floats with NaN included.
+package hashset
+
+import (
+	"iter"
+
+	"hashmaps/chainedmap"
+)
+
+// Set is a set of distinct items, in no particular order.
+// The set algebra methods return new sets and leave their operands alone.
+type Set[T comparable] struct {
+	items *chainedmap.HashMap[T, struct{}]
+}
+
+func MakeSet[T comparable]() *Set[T] {
+	return &Set[T]{items: chainedmap.MakeHashMap[T, struct{}]()}
+}
+
+// MakeSetOf returns a set holding items, duplicates are dropped
+func MakeSetOf[T comparable](items ...T) *Set[T] {
+	s := MakeSet[T]()
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// Add returns false when item was already in the set
+func (s *Set[T]) Add(item T) bool {
+	added := false
+	s.items.GetOrCompute(item, func() struct{} {
+		added = true
+		return struct{}{}
+	})
+	return added
+}
+
+// Remove returns false when item wasn't in the set
+func (s *Set[T]) Remove(item T) bool {
+	_, ok := s.items.Delete(item)
+	return ok
+}
+
+func (s *Set[T]) Contains(item T) bool {
+	return s.items.Get(item) != nil
+}
+
+// Len returns the number of items, in O(1)
+func (s *Set[T]) Len() int {
+	return s.items.Len()
+}
+
+func (s *Set[T]) Clone() *Set[T] {
+	return &Set[T]{items: s.items.Clone()}
+}
+
+// Range calls fn for every item until fn returns false.
+// The set must not be modified by fn.
+func (s *Set[T]) Range(fn func(item T) bool) {
+	s.items.Range(func(item T, _ struct{}) bool {
+		return fn(item)
+	})
+}
+
+// All is the range-over-func form of Range
+func (s *Set[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
+// Union holds the items that are in s, other or both
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	larger, smaller := s, other
+	if smaller.Len() > larger.Len() {
+		larger, smaller = smaller, larger
+	}
+	union := larger.Clone()
+	smaller.Range(func(item T) bool {
+		union.Add(item)
+		return true
+	})
+	return union
+}
+
+// Intersection holds the items that are in both s and other
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	larger, smaller := s, other
+	if smaller.Len() > larger.Len() {
+		larger, smaller = smaller, larger
+	}
+	intersection := MakeSet[T]()
+	smaller.Range(func(item T) bool {
+		if larger.Contains(item) {
+			intersection.Add(item)
+		}
+		return true
+	})
+	return intersection
+}
+
+// Difference holds the items of s that aren't in other
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	difference := MakeSet[T]()
+	s.Range(func(item T) bool {
+		if !other.Contains(item) {
+			difference.Add(item)
+		}
+		return true
+	})
+	return difference
+}
+
+// SymmetricDifference holds the items that are in exactly one of s and other
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	difference := s.Difference(other)
+	other.Range(func(item T) bool {
+		if !s.Contains(item) {
+			difference.Add(item)
+		}
+		return true
+	})
+	return difference
+}
+
+// IsSubset reports whether every item of s is also in other
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	subset := true
+	s.Range(func(item T) bool {
+		subset = other.Contains(item)
+		return subset
+	})
+	return subset
+}
+
+// IsSuperset reports whether every item of other is also in s
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Equal reports whether s and other hold the same items
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
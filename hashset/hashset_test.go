@@ -0,0 +1,93 @@
+package hashset
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func items(t *testing.T, s *Set[int]) []int {
+	t.Helper()
+	sorted := slices.Sorted(s.All())
+	if len(sorted) != s.Len() {
+		t.Fatalf("Len() = %d, Range visited %d items", s.Len(), len(sorted))
+	}
+	return sorted
+}
+
+// filter returns the items of 0..99 for which keep is true
+func filter(keep func(item int) bool) []int {
+	var want []int
+	for item := 0; item < 100; item++ {
+		if keep(item) {
+			want = append(want, item)
+		}
+	}
+	return want
+}
+
+func randomSet(t *testing.T, r *rand.Rand) (*Set[int], map[int]bool) {
+	t.Helper()
+	s, model := MakeSet[int](), map[int]bool{}
+	for n := r.IntN(60); n > 0; n-- { // empty sometimes
+		item := r.IntN(100)
+		if s.Add(item) == model[item] {
+			t.Fatalf("Add(%d) = %v, want %v", item, model[item], !model[item])
+		}
+		model[item] = true
+	}
+	return s, model
+}
+
+func TestAlgebra(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 200; i++ {
+		a, inA := randomSet(t, r)
+		b, inB := randomSet(t, r)
+		beforeA, beforeB := items(t, a), items(t, b)
+		for _, tc := range []struct {
+			name string
+			got  *Set[int]
+			keep func(item int) bool
+		}{
+			{"Union", a.Union(b), func(item int) bool { return inA[item] || inB[item] }},
+			{"Intersection", a.Intersection(b), func(item int) bool { return inA[item] && inB[item] }},
+			{"Difference", a.Difference(b), func(item int) bool { return inA[item] && !inB[item] }},
+			{"SymmetricDifference", a.SymmetricDifference(b), func(item int) bool { return inA[item] != inB[item] }},
+		} {
+			if got, want := items(t, tc.got), filter(tc.keep); !slices.Equal(got, want) {
+				t.Fatalf("%s(%v, %v) = %v, want %v", tc.name, beforeA, beforeB, got, want)
+			}
+		}
+		if !slices.Equal(items(t, a), beforeA) || !slices.Equal(items(t, b), beforeB) {
+			t.Fatalf("set algebra modified its operands")
+		}
+		subset := len(filter(func(item int) bool { return inA[item] && !inB[item] })) == 0
+		if a.IsSubset(b) != subset || b.IsSuperset(a) != subset {
+			t.Fatalf("IsSubset(%v, %v) = %v, want %v", beforeA, beforeB, a.IsSubset(b), subset)
+		}
+		if a.Equal(b) != slices.Equal(beforeA, beforeB) || !a.Equal(a.Clone()) {
+			t.Fatalf("Equal(%v, %v) = %v", beforeA, beforeB, a.Equal(b))
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	s := MakeSetOf(1, 2, 2, 3)
+	if s.Len() != 3 || !s.Contains(2) {
+		t.Fatalf("MakeSetOf(1, 2, 2, 3) = %v", items(t, s))
+	}
+	clone := s.Clone()
+	if !s.Remove(2) || s.Remove(2) || s.Contains(2) || s.Len() != 2 {
+		t.Fatalf("Remove(2) twice must be true, then false, left %v", items(t, s))
+	}
+	if !clone.Contains(2) {
+		t.Fatalf("Remove on a set changed its clone")
+	}
+
+	floats := MakeSetOf(math.NaN(), math.NaN(), math.Copysign(0, -1), 0)
+	if floats.Len() != 2 || !floats.Contains(math.NaN()) || !floats.Contains(0) {
+		t.Fatalf("MakeSetOf(NaN, NaN, -0, 0) has %d items, want NaN and 0", floats.Len())
+	}
+}
@@ -0,0 +1,160 @@
+package hashset
+
+import (
+	"iter"
+
+	"hashmaps/chainedmap"
+)
+
+// Multiset is a bag of items that remembers how many times each was added,
+// kept as a count per distinct item. Unlike ostree.Multiset it needs no
+// ordering, and lookups are O(1) instead of O(log n).
+type Multiset[T comparable] struct {
+	counts *chainedmap.HashMap[T, int] // only positive counts are stored
+	length int                         // sum of all counts
+}
+
+func MakeMultiset[T comparable]() *Multiset[T] {
+	return &Multiset[T]{counts: chainedmap.MakeHashMap[T, int]()}
+}
+
+// MakeMultisetOf counts every occurrence of the items
+func MakeMultisetOf[T comparable](items ...T) *Multiset[T] {
+	s := MakeMultiset[T]()
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// Len counts every occurrence, duplicates included
+func (s *Multiset[T]) Len() int {
+	return s.length
+}
+
+// DistinctLen counts the distinct items
+func (s *Multiset[T]) DistinctLen() int {
+	return s.counts.Len()
+}
+
+func (s *Multiset[T]) Add(item T) {
+	s.AddN(item, 1)
+}
+
+func (s *Multiset[T]) AddN(item T, n int) {
+	if n <= 0 {
+		return
+	}
+	if count := s.counts.Get(item); count != nil {
+		*count += n
+	} else {
+		s.counts.Set(item, n)
+	}
+	s.length += n
+}
+
+// Remove takes away a single occurrence of item
+func (s *Multiset[T]) Remove(item T) bool {
+	return s.RemoveN(item, 1) == 1
+}
+
+// RemoveN takes away up to n occurrences of item and returns how many were removed
+func (s *Multiset[T]) RemoveN(item T, n int) int {
+	count := s.counts.Get(item)
+	if n <= 0 || count == nil {
+		return 0
+	}
+	if n >= *count {
+		n = *count
+		s.counts.Remove(item)
+	} else {
+		*count -= n
+	}
+	s.length -= n
+	return n
+}
+
+func (s *Multiset[T]) Count(item T) int {
+	if count := s.counts.Get(item); count != nil {
+		return *count
+	}
+	return 0
+}
+
+func (s *Multiset[T]) Contains(item T) bool {
+	return s.counts.Get(item) != nil
+}
+
+func (s *Multiset[T]) Clone() *Multiset[T] {
+	return &Multiset[T]{counts: s.counts.Clone(), length: s.length}
+}
+
+// RangeDistinct visits every distinct item once, together with its count
+func (s *Multiset[T]) RangeDistinct(fn func(item T, count int) bool) {
+	s.counts.Range(fn)
+}
+
+// Distinct is RangeDistinct as an iter.Seq2 of items and counts
+func (s *Multiset[T]) Distinct() iter.Seq2[T, int] {
+	return s.counts.Range
+}
+
+// All yields every occurrence, so an item with count 3 comes 3 times
+func (s *Multiset[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.counts.Range(func(item T, count int) bool {
+			for i := 0; i < count; i++ {
+				if !yield(item) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// Union keeps the larger count of every item
+func (s *Multiset[T]) Union(other *Multiset[T]) *Multiset[T] {
+	union := s.Clone()
+	other.RangeDistinct(func(item T, count int) bool {
+		if extra := count - union.Count(item); extra > 0 {
+			union.AddN(item, extra)
+		}
+		return true
+	})
+	return union
+}
+
+// Sum adds up the counts of every item
+func (s *Multiset[T]) Sum(other *Multiset[T]) *Multiset[T] {
+	sum := s.Clone()
+	other.RangeDistinct(func(item T, count int) bool {
+		sum.AddN(item, count)
+		return true
+	})
+	return sum
+}
+
+// Intersection keeps the smaller count of every item
+func (s *Multiset[T]) Intersection(other *Multiset[T]) *Multiset[T] {
+	larger, smaller := s, other
+	if smaller.DistinctLen() > larger.DistinctLen() {
+		larger, smaller = smaller, larger
+	}
+	intersection := MakeMultiset[T]()
+	smaller.RangeDistinct(func(item T, count int) bool {
+		intersection.AddN(item, min(count, larger.Count(item)))
+		return true
+	})
+	return intersection
+}
+
+// Difference takes the counts of other away from the counts of s
+func (s *Multiset[T]) Difference(other *Multiset[T]) *Multiset[T] {
+	difference := MakeMultiset[T]()
+	s.RangeDistinct(func(item T, count int) bool {
+		difference.AddN(item, count-other.Count(item))
+		return true
+	})
+	return difference
+}
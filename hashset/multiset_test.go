@@ -0,0 +1,88 @@
+package hashset
+
+import (
+	"maps"
+	"math/rand/v2"
+	"testing"
+)
+
+func checkCounts(t *testing.T, name string, s *Multiset[int], want map[int]int) {
+	t.Helper()
+	length := 0
+	for item, count := range want {
+		if count <= 0 {
+			delete(want, item)
+		}
+		length += max(count, 0)
+	}
+	got := maps.Collect(s.Distinct())
+	if !maps.Equal(got, want) || s.Len() != length || s.DistinctLen() != len(want) {
+		t.Fatalf("%s = %v with Len() %d, DistinctLen() %d, want %v", name, got, s.Len(), s.DistinctLen(), want)
+	}
+	occurrences := map[int]int{}
+	for item := range s.All() {
+		occurrences[item]++
+	}
+	if !maps.Equal(occurrences, want) {
+		t.Fatalf("%s.All() yields %v, want %v", name, occurrences, want)
+	}
+}
+
+func randomMultiset(r *rand.Rand) (*Multiset[int], map[int]int) {
+	s, model := MakeMultiset[int](), map[int]int{}
+	for n := r.IntN(30); n > 0; n-- {
+		item, count := r.IntN(20), r.IntN(4)
+		s.AddN(item, count)
+		model[item] += count
+	}
+	return s, model
+}
+
+func TestMultisetCounts(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s, want := MakeMultiset[int](), map[int]int{}
+	for i := 0; i < 2000; i++ {
+		item, n := r.IntN(20), r.IntN(5)-1 // n <= 0 must do nothing
+		if r.IntN(2) == 0 {
+			s.AddN(item, n)
+			want[item] += max(n, 0)
+			continue
+		}
+		removed := min(max(n, 0), want[item])
+		if got := s.RemoveN(item, n); got != removed {
+			t.Fatalf("RemoveN(%d, %d) = %d with count %d, want %d", item, n, got, want[item], removed)
+		}
+		want[item] -= removed
+		if s.Count(item) != want[item] || s.Contains(item) != (want[item] > 0) {
+			t.Fatalf("Count(%d) = %d, Contains = %v, want %d", item, s.Count(item), s.Contains(item), want[item])
+		}
+	}
+	checkCounts(t, "Multiset", s, want)
+	if s.Remove(-1) || s.Count(-1) != 0 {
+		t.Fatalf("Remove(-1) found an item never added")
+	}
+	if s := MakeMultisetOf(1, 2, 1); s.Count(1) != 2 || s.Len() != 3 {
+		t.Fatalf("MakeMultisetOf(1, 2, 1) counts %d ones in %d", s.Count(1), s.Len())
+	}
+}
+
+func TestMultisetAlgebra(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 200; i++ {
+		a, inA := randomMultiset(r)
+		b, inB := randomMultiset(r)
+		union, sum, intersection, difference := map[int]int{}, map[int]int{}, map[int]int{}, map[int]int{}
+		for item := 0; item < 20; item++ {
+			union[item] = max(inA[item], inB[item])
+			sum[item] = inA[item] + inB[item]
+			intersection[item] = min(inA[item], inB[item])
+			difference[item] = inA[item] - inB[item]
+		}
+		checkCounts(t, "Union", a.Union(b), union)
+		checkCounts(t, "Sum", a.Sum(b), sum)
+		checkCounts(t, "Intersection", a.Intersection(b), intersection)
+		checkCounts(t, "Difference", a.Difference(b), difference)
+		checkCounts(t, "the first operand", a, inA)
+		checkCounts(t, "the second operand", b, inB)
+	}
+}
@@ -0,0 +1,237 @@
+package heap
+
+import "iter"
+
+// Heap is a plain binary min-heap ordered by less, the generic counterpart
+// of container/heap
+type Heap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func MakeHeap[T any](less func(a, b T) bool) *Heap[T] {
+	return &Heap[T]{less: less}
+}
+
+func (h *Heap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *Heap[T]) Push(item T) {
+	h.items = append(h.items, item)
+	h.up(len(h.items) - 1)
+}
+
+func (h *Heap[T]) Peek() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[0], true
+}
+
+func (h *Heap[T]) Pop() (T, bool) {
+	var zero T
+	if len(h.items) == 0 {
+		return zero, false
+	}
+	top := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items[last] = zero
+	h.items = h.items[:last]
+	h.down(0)
+	return top, true
+}
+
+// Fix restores the order after the i-th item of All was changed in place,
+// e.g. through a pointer. To find items again without scanning use IndexedHeap.
+func (h *Heap[T]) Fix(i int) {
+	if !h.up(i) {
+		h.down(i)
+	}
+}
+
+// All yields the items in heap order, which is not sorted.
+// The heap must not be modified during the loop.
+func (h *Heap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range h.items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// up returns true if the item moved
+func (h *Heap[T]) up(i int) bool {
+	start := i
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.items[i], h.items[parent]) {
+			break
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+	return i != start
+}
+
+func (h *Heap[T]) down(i int) {
+	for {
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(h.items) && h.less(h.items[child], h.items[smallest]) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+}
+
+// Handle points at an item pushed to an IndexedHeap, it stays valid
+// while the item moves around the heap
+type Handle[T any] struct {
+	item  T
+	index int // position in the heap, -1 once popped or removed
+}
+
+func (h *Handle[T]) Value() T {
+	return h.item
+}
+
+// IndexedHeap is Heap where Push returns a Handle, so an item can be
+// changed or removed in O(log n) later, e.g. the distances in Dijkstra.
+// The items don't have to be comparable or distinct. IndexedPriorityQueue
+// is built on it for items that are looked up by themselves instead.
+type IndexedHeap[T any] struct {
+	handles []*Handle[T]
+	less    func(a, b T) bool
+}
+
+func MakeIndexedHeap[T any](less func(a, b T) bool) *IndexedHeap[T] {
+	return &IndexedHeap[T]{less: less}
+}
+
+func (h *IndexedHeap[T]) Len() int {
+	return len(h.handles)
+}
+
+func (h *IndexedHeap[T]) Push(item T) *Handle[T] {
+	handle := &Handle[T]{item: item, index: len(h.handles)}
+	h.handles = append(h.handles, handle)
+	h.up(handle.index)
+	return handle
+}
+
+func (h *IndexedHeap[T]) Peek() (T, bool) {
+	if len(h.handles) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.handles[0].item, true
+}
+
+func (h *IndexedHeap[T]) Pop() (T, bool) {
+	if len(h.handles) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.removeAt(0).item, true
+}
+
+// Update replaces the item of handle in either direction of the order,
+// it returns false when the item was already popped or removed
+func (h *IndexedHeap[T]) Update(handle *Handle[T], item T) bool {
+	if !h.holds(handle) {
+		return false
+	}
+	handle.item = item
+	h.fix(handle.index)
+	return true
+}
+
+func (h *IndexedHeap[T]) Remove(handle *Handle[T]) bool {
+	if !h.holds(handle) {
+		return false
+	}
+	h.removeAt(handle.index)
+	return true
+}
+
+// All yields the items in heap order, which is not sorted.
+// The heap must not be modified during the loop.
+func (h *IndexedHeap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, handle := range h.handles {
+			if !yield(handle.item) {
+				return
+			}
+		}
+	}
+}
+
+// holds is false for handles of other heaps too
+func (h *IndexedHeap[T]) holds(handle *Handle[T]) bool {
+	return handle.index >= 0 && handle.index < len(h.handles) && h.handles[handle.index] == handle
+}
+
+func (h *IndexedHeap[T]) removeAt(i int) *Handle[T] {
+	removed := h.handles[i]
+	last := len(h.handles) - 1
+	h.swap(i, last)
+	h.handles[last] = nil
+	h.handles = h.handles[:last]
+	removed.index = -1
+	if i < last {
+		h.fix(i)
+	}
+	return removed
+}
+
+func (h *IndexedHeap[T]) swap(i, j int) {
+	h.handles[i], h.handles[j] = h.handles[j], h.handles[i]
+	h.handles[i].index = i
+	h.handles[j].index = j
+}
+
+func (h *IndexedHeap[T]) fix(i int) {
+	if !h.up(i) {
+		h.down(i)
+	}
+}
+
+// up returns true if the item moved
+func (h *IndexedHeap[T]) up(i int) bool {
+	start := i
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.handles[i].item, h.handles[parent].item) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+	return i != start
+}
+
+func (h *IndexedHeap[T]) down(i int) {
+	for {
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(h.handles) && h.less(h.handles[child].item, h.handles[smallest].item) {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return
+		}
+		h.swap(i, smallest)
+		i = smallest
+	}
+}
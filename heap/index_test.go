@@ -0,0 +1,30 @@
+package heap_test
+
+import (
+	"slices"
+	"testing"
+
+	"hashmaps/chainedmap"
+	"hashmaps/heap"
+)
+
+// the queue with a chainedmap index behaves like the one with a built-in map
+func TestIndexedPriorityQueueWithChainedIndex(t *testing.T) {
+	index := chainedmap.MakeHashMap[string, *heap.Handle[heap.Prioritized[string, int]]]()
+	q := heap.MakeIndexedPriorityQueueWithIndex[string, int](func(a, b int) bool { return a < b }, index)
+	q.Push("a", 5)
+	q.Push("b", 3)
+	q.Push("c", 8)
+	q.Push("a", 1)
+	if !q.Remove("b") || index.Len() != 2 {
+		t.Fatalf("Remove(b) left %d items in the index, want 2", index.Len())
+	}
+	var popped []string
+	for q.Len() > 0 {
+		item, _, _ := q.Pop()
+		popped = append(popped, item)
+	}
+	if !slices.Equal(popped, []string{"a", "c"}) || index.Len() != 0 {
+		t.Fatalf("popped %q with %d items left in the index, want [a c] and 0", popped, index.Len())
+	}
+}
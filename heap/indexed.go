@@ -0,0 +1,138 @@
+package heap
+
+import "iter"
+
+// Prioritized is an item of an IndexedPriorityQueue together with its priority
+type Prioritized[T comparable, P any] struct {
+	Item     T
+	Priority P
+}
+
+// Index is where an IndexedPriorityQueue looks up the Handle of a queued
+// item, Get returns nil for items that aren't queued. It is the part of
+// the repo's HashMaps the queue needs, so one of them can be passed to
+// MakeIndexedPriorityQueueWithIndex without heap depending on them, e.g.
+//
+//	chainedmap.MakeHashMap[K, *heap.Handle[heap.Prioritized[K, P]]]()
+type Index[T comparable, H any] interface {
+	Get(item T) *H
+	Set(item T, handle H)
+	Delete(item T) (H, bool)
+}
+
+// mapIndex is the default Index
+type mapIndex[T comparable, H any] map[T]H
+
+func (m mapIndex[T, H]) Get(item T) *H {
+	handle, ok := m[item]
+	if !ok {
+		return nil
+	}
+	return &handle
+}
+
+func (m mapIndex[T, H]) Set(item T, handle H) {
+	m[item] = handle
+}
+
+func (m mapIndex[T, H]) Delete(item T) (H, bool) {
+	handle, ok := m[item]
+	delete(m, item)
+	return handle, ok
+}
+
+// IndexedPriorityQueue is a min-heap of distinct items ordered by a separate
+// priority, where items are looked up by themselves: the priority of an
+// item already queued can be changed or the item removed in O(log n)
+// without keeping anything from Push. Every item can be queued only once.
+//
+// It is an IndexedHeap plus an Index from each item to its Handle. Use
+// IndexedHeap directly when the items aren't comparable or distinct, or
+// when the caller can keep the Handles itself.
+type IndexedPriorityQueue[T comparable, P any] struct {
+	heap    *IndexedHeap[Prioritized[T, P]]
+	handles Index[T, *Handle[Prioritized[T, P]]]
+}
+
+// MakeIndexedPriorityQueue indexes the items with a built-in map
+func MakeIndexedPriorityQueue[T comparable, P any](less func(a, b P) bool) *IndexedPriorityQueue[T, P] {
+	return MakeIndexedPriorityQueueWithIndex[T, P](less, mapIndex[T, *Handle[Prioritized[T, P]]]{})
+}
+
+// MakeIndexedPriorityQueueWithIndex indexes the items with index, which has to be empty
+func MakeIndexedPriorityQueueWithIndex[T comparable, P any](less func(a, b P) bool, index Index[T, *Handle[Prioritized[T, P]]]) *IndexedPriorityQueue[T, P] {
+	return &IndexedPriorityQueue[T, P]{
+		heap: MakeIndexedHeap(func(a, b Prioritized[T, P]) bool {
+			return less(a.Priority, b.Priority)
+		}),
+		handles: index,
+	}
+}
+
+func (q *IndexedPriorityQueue[T, P]) Len() int {
+	return q.heap.Len()
+}
+
+func (q *IndexedPriorityQueue[T, P]) Contains(item T) bool {
+	return q.handles.Get(item) != nil
+}
+
+func (q *IndexedPriorityQueue[T, P]) Priority(item T) (P, bool) {
+	handle := q.handles.Get(item)
+	if handle == nil {
+		var zero P
+		return zero, false
+	}
+	return (*handle).Value().Priority, true
+}
+
+// Push queues the item, or changes its priority when it is already queued
+func (q *IndexedPriorityQueue[T, P]) Push(item T, priority P) {
+	if q.Update(item, priority) {
+		return
+	}
+	q.handles.Set(item, q.heap.Push(Prioritized[T, P]{Item: item, Priority: priority}))
+}
+
+// Update changes the priority of a queued item in either direction,
+// it returns false when the item is not queued
+func (q *IndexedPriorityQueue[T, P]) Update(item T, priority P) bool {
+	handle := q.handles.Get(item)
+	if handle == nil {
+		return false
+	}
+	return q.heap.Update(*handle, Prioritized[T, P]{Item: item, Priority: priority})
+}
+
+func (q *IndexedPriorityQueue[T, P]) Peek() (T, P, bool) {
+	top, ok := q.heap.Peek()
+	return top.Item, top.Priority, ok
+}
+
+func (q *IndexedPriorityQueue[T, P]) Pop() (T, P, bool) {
+	popped, ok := q.heap.Pop()
+	if ok {
+		q.handles.Delete(popped.Item)
+	}
+	return popped.Item, popped.Priority, ok
+}
+
+func (q *IndexedPriorityQueue[T, P]) Remove(item T) bool {
+	handle, ok := q.handles.Delete(item)
+	if !ok {
+		return false
+	}
+	return q.heap.Remove(handle)
+}
+
+// All yields the queued items with their priorities in heap order, which
+// is not sorted. The queue must not be modified during the loop.
+func (q *IndexedPriorityQueue[T, P]) All() iter.Seq2[T, P] {
+	return func(yield func(T, P) bool) {
+		for entry := range q.heap.All() {
+			if !yield(entry.Item, entry.Priority) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package heap
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func lessInt(a, b int) bool { return a < b }
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	q := MakeIndexedPriorityQueue[string, int](lessInt)
+	q.Push("a", 5)
+	q.Push("b", 3)
+	q.Push("c", 8)
+	q.Push("a", 1) // already queued, changes the priority
+	if q.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", q.Len())
+	}
+	if priority, ok := q.Priority("a"); !ok || priority != 1 {
+		t.Fatalf("Priority(a) = %d, %v, want 1, true", priority, ok)
+	}
+	if !q.Update("c", 0) || q.Update("missing", 0) {
+		t.Fatal("Update found the wrong items")
+	}
+	if !q.Remove("b") || q.Remove("b") || q.Contains("b") {
+		t.Fatal("Remove(b) didn't remove it exactly once")
+	}
+	var popped []string
+	for q.Len() > 0 {
+		item, _, _ := q.Pop()
+		popped = append(popped, item)
+	}
+	if !slices.Equal(popped, []string{"c", "a"}) {
+		t.Fatalf("popped %q, want [c a]", popped)
+	}
+	if _, _, ok := q.Pop(); ok || q.Contains("a") {
+		t.Fatal("empty queue still holds items")
+	}
+}
+
+func TestIndexedPriorityQueueAgainstSort(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	q := MakeIndexedPriorityQueue[int, int](lessInt)
+	priorities := map[int]int{}
+	for i := 0; i < 10_000; i++ {
+		item := random.IntN(500)
+		switch random.IntN(3) {
+		case 0, 1:
+			priority := random.IntN(1000)
+			q.Push(item, priority)
+			priorities[item] = priority
+		case 2:
+			_, queued := priorities[item]
+			if q.Remove(item) != queued {
+				t.Fatalf("Remove(%d) disagrees with the model", item)
+			}
+			delete(priorities, item)
+		}
+	}
+	var want []int
+	for _, priority := range priorities {
+		want = append(want, priority)
+	}
+	slices.Sort(want)
+	var got []int
+	for q.Len() > 0 {
+		item, priority, _ := q.Pop()
+		if priorities[item] != priority {
+			t.Fatalf("popped %d with priority %d, want %d", item, priority, priorities[item])
+		}
+		got = append(got, priority)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatal("priorities didn't come out sorted")
+	}
+}
@@ -0,0 +1,181 @@
+// Package heap contains priority queues parametrized with a less function,
+// so no interface{} juggling like with container/heap is needed.
+package heap
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// MinMaxHeap keeps both the smallest and the largest element reachable in O(1).
+// Elements on even levels are smaller than all their descendants,
+// elements on odd levels are larger than all their descendants.
+type MinMaxHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func MakeMinMaxHeap[T any](less func(a, b T) bool) *MinMaxHeap[T] {
+	return &MinMaxHeap[T]{less: less}
+}
+
+func (h *MinMaxHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *MinMaxHeap[T]) Push(item T) {
+	h.items = append(h.items, item)
+	h.bubbleUp(len(h.items) - 1)
+}
+
+func (h *MinMaxHeap[T]) PeekMin() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[0], true
+}
+
+func (h *MinMaxHeap[T]) PeekMax() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[h.maxIndex()], true
+}
+
+func (h *MinMaxHeap[T]) PopMin() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.removeAt(0), true
+}
+
+func (h *MinMaxHeap[T]) PopMax() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.removeAt(h.maxIndex()), true
+}
+
+// All yields the items in heap order, which is neither sorted nor
+// insertion order. The heap must not be modified during the loop.
+func (h *MinMaxHeap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range h.items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// maxIndex is the root when it is alone, otherwise the bigger of its children
+func (h *MinMaxHeap[T]) maxIndex() int {
+	switch len(h.items) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.less(h.items[1], h.items[2]) {
+		return 2
+	}
+	return 1
+}
+
+func (h *MinMaxHeap[T]) removeAt(i int) T {
+	removed := h.items[i]
+	last := len(h.items) - 1
+	h.items[i] = h.items[last]
+	var zero T
+	h.items[last] = zero // don't keep a reference to the popped item
+	h.items = h.items[:last]
+	if i < last {
+		h.trickleDown(i)
+	}
+	return removed
+}
+
+func isMinLevel(i int) bool {
+	return (bits.Len(uint(i+1))-1)%2 == 0
+}
+
+func (h *MinMaxHeap[T]) swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *MinMaxHeap[T]) bubbleUp(i int) {
+	if i == 0 {
+		return
+	}
+	parent := (i - 1) / 2
+	if isMinLevel(i) {
+		if h.less(h.items[parent], h.items[i]) {
+			h.swap(i, parent)
+			h.bubbleUpGrandparents(parent, true)
+		} else {
+			h.bubbleUpGrandparents(i, false)
+		}
+	} else {
+		if h.less(h.items[i], h.items[parent]) {
+			h.swap(i, parent)
+			h.bubbleUpGrandparents(parent, false)
+		} else {
+			h.bubbleUpGrandparents(i, true)
+		}
+	}
+}
+
+// bubbleUpGrandparents moves i up within its own kind of levels,
+// towards the max (isMax) or towards the min
+func (h *MinMaxHeap[T]) bubbleUpGrandparents(i int, isMax bool) {
+	for i > 2 {
+		grandparent := ((i-1)/2 - 1) / 2
+		if h.outranks(i, grandparent, isMax) {
+			h.swap(i, grandparent)
+			i = grandparent
+		} else {
+			return
+		}
+	}
+}
+
+// outranks tells if items[i] should be closer to the root than items[j]
+func (h *MinMaxHeap[T]) outranks(i, j int, isMax bool) bool {
+	if isMax {
+		return h.less(h.items[j], h.items[i])
+	}
+	return h.less(h.items[i], h.items[j])
+}
+
+func (h *MinMaxHeap[T]) trickleDown(i int) {
+	isMax := !isMinLevel(i)
+	for {
+		// most extreme among children and grandchildren
+		best := -1
+		firstChild := 2*i + 1
+		for _, candidate := range []int{firstChild, firstChild + 1, 2*firstChild + 1, 2*firstChild + 2, 2*firstChild + 3, 2*firstChild + 4} {
+			if candidate >= len(h.items) {
+				break
+			}
+			if best == -1 || h.outranks(candidate, best, isMax) {
+				best = candidate
+			}
+		}
+		if best == -1 || !h.outranks(best, i, isMax) {
+			return
+		}
+		h.swap(best, i)
+		if best <= firstChild+1 { // a child, it has no descendants of our kind
+			return
+		}
+		parent := (best - 1) / 2
+		if h.outranks(parent, best, isMax) {
+			h.swap(parent, best)
+		}
+		i = best
+	}
+}
@@ -0,0 +1,82 @@
+package heap
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// The heap must pop what a sorted slice holding the same values has at
+// either end, through every mix of pushes and pops from both ends
+func TestMinMaxHeapAgainstSortedSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	h := MakeMinMaxHeap(lessInt)
+	var model []int // sorted
+	for i := 0; i < 5000; i++ {
+		switch r.IntN(5) {
+		case 0:
+			got, ok := h.PopMin()
+			if ok != (len(model) > 0) || ok && got != model[0] {
+				t.Fatalf("step %d: PopMin() = %d, %v, model %v", i, got, ok, model)
+			}
+			if ok {
+				model = model[1:]
+			}
+		case 1:
+			got, ok := h.PopMax()
+			if ok != (len(model) > 0) || ok && got != model[len(model)-1] {
+				t.Fatalf("step %d: PopMax() = %d, %v, model %v", i, got, ok, model)
+			}
+			if ok {
+				model = model[:len(model)-1]
+			}
+		default:
+			value := r.IntN(50) // plenty of duplicates
+			h.Push(value)
+			position, _ := slices.BinarySearch(model, value)
+			model = slices.Insert(model, position, value)
+		}
+		if h.Len() != len(model) {
+			t.Fatalf("step %d: Len() = %d, want %d", i, h.Len(), len(model))
+		}
+		if len(model) == 0 {
+			continue
+		}
+		if low, _ := h.PeekMin(); low != model[0] {
+			t.Fatalf("step %d: PeekMin() = %d, want %d", i, low, model[0])
+		}
+		if high, _ := h.PeekMax(); high != model[len(model)-1] {
+			t.Fatalf("step %d: PeekMax() = %d, want %d", i, high, model[len(model)-1])
+		}
+	}
+	all := slices.Sorted(h.All())
+	if !slices.Equal(all, model) {
+		t.Fatalf("All() = %v, want %v", all, model)
+	}
+}
+
+func TestMinMaxHeapSmall(t *testing.T) {
+	h := MakeMinMaxHeap(lessInt)
+	if _, ok := h.PeekMax(); ok {
+		t.Fatal("PeekMax of an empty heap found a value")
+	}
+	// one and two elements are the cases where the max isn't on level one
+	h.Push(7)
+	if low, _ := h.PeekMin(); low != 7 {
+		t.Fatalf("PeekMin() = %d, want 7", low)
+	}
+	if high, _ := h.PopMax(); high != 7 || h.Len() != 0 {
+		t.Fatalf("PopMax() = %d with Len() %d, want 7 and 0", high, h.Len())
+	}
+	h.Push(3)
+	h.Push(9)
+	if high, _ := h.PopMax(); high != 9 {
+		t.Fatalf("PopMax() = %d, want 9", high)
+	}
+	if low, _ := h.PopMin(); low != 3 {
+		t.Fatalf("PopMin() = %d, want 3", low)
+	}
+	if _, ok := h.PopMin(); ok {
+		t.Fatal("PopMin of an empty heap found a value")
+	}
+}
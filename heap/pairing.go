@@ -0,0 +1,173 @@
+package heap
+
+import "iter"
+
+// PairingNode is the handle Insert returns for DecreaseKey
+type PairingNode[T any] struct {
+	value   T
+	child   *PairingNode[T] // leftmost child
+	sibling *PairingNode[T] // next sibling to the right
+	prev    *PairingNode[T] // left sibling, or the parent of a leftmost child
+	popped  bool
+}
+
+func (n *PairingNode[T]) Value() T {
+	return n.value
+}
+
+// PairingHeap is a min-heap kept as a multiway tree. Push, Meld and
+// DecreaseKey are O(1), Pop is O(log n) amortized, which makes it a good fit
+// when queues are often combined together, or for Dijkstra and Prim.
+type PairingHeap[T any] struct {
+	root *PairingNode[T]
+	size int
+	less func(a, b T) bool
+}
+
+func MakePairingHeap[T any](less func(a, b T) bool) *PairingHeap[T] {
+	return &PairingHeap[T]{less: less}
+}
+
+func (h *PairingHeap[T]) Len() int {
+	return h.size
+}
+
+func (h *PairingHeap[T]) Push(value T) {
+	h.Insert(value)
+}
+
+// Insert is Push returning the node of value, for DecreaseKey
+func (h *PairingHeap[T]) Insert(value T) *PairingNode[T] {
+	n := &PairingNode[T]{value: value}
+	h.root = h.link(h.root, n)
+	h.size++
+	return n
+}
+
+func (h *PairingHeap[T]) Peek() (T, bool) {
+	if h.root == nil {
+		var zero T
+		return zero, false
+	}
+	return h.root.value, true
+}
+
+func (h *PairingHeap[T]) Pop() (T, bool) {
+	if h.root == nil {
+		var zero T
+		return zero, false
+	}
+	popped := h.root
+	h.root = h.mergePairs(popped.child)
+	popped.child = nil
+	popped.popped = true
+	h.size--
+	return popped.value, true
+}
+
+// DeleteMin is Pop by its textbook name
+func (h *PairingHeap[T]) DeleteMin() (T, bool) {
+	return h.Pop()
+}
+
+// DecreaseKey lowers the value of a node of h, or of a heap melded into h.
+// It returns false, changing nothing, when the node was already popped or
+// value would sort after the current one.
+func (h *PairingHeap[T]) DecreaseKey(n *PairingNode[T], value T) bool {
+	if n.popped || h.less(n.value, value) {
+		return false
+	}
+	n.value = value
+	if n == h.root {
+		return true
+	}
+	// cut n with its subtree out of its sibling list and link it to the root
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+	h.root = h.link(h.root, n)
+	return true
+}
+
+// Meld moves all elements of other into h, leaving other empty.
+// Both heaps are expected to order elements the same way.
+func (h *PairingHeap[T]) Meld(other *PairingHeap[T]) {
+	if other == h {
+		return
+	}
+	h.root = h.link(h.root, other.root)
+	h.size += other.size
+	other.root = nil
+	other.size = 0
+}
+
+// All yields the values in no particular order, each parent before its
+// children. The heap must not be modified during the loop.
+func (h *PairingHeap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var stack []*PairingNode[T]
+		if h.root != nil {
+			stack = append(stack, h.root)
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(n.value) {
+				return
+			}
+			for child := n.child; child != nil; child = child.sibling {
+				stack = append(stack, child)
+			}
+		}
+	}
+}
+
+// link makes the bigger root the leftmost child of the smaller one
+func (h *PairingHeap[T]) link(a, b *PairingNode[T]) *PairingNode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.less(b.value, a.value) {
+		a, b = b, a
+	}
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	b.prev = a
+	a.child = b
+	return a
+}
+
+// mergePairs is the standard two-pass merge, done without recursion
+// so a long list of children can't blow up the stack
+func (h *PairingHeap[T]) mergePairs(first *PairingNode[T]) *PairingNode[T] {
+	var pairs []*PairingNode[T]
+	for first != nil {
+		a := first
+		b := a.sibling
+		if b == nil {
+			a.sibling, a.prev = nil, nil
+			pairs = append(pairs, a)
+			break
+		}
+		first = b.sibling
+		a.sibling, a.prev = nil, nil
+		b.sibling, b.prev = nil, nil
+		pairs = append(pairs, h.link(a, b))
+	}
+	var merged *PairingNode[T]
+	for i := len(pairs) - 1; i >= 0; i-- {
+		merged = h.link(merged, pairs[i])
+	}
+	return merged
+}
@@ -0,0 +1,97 @@
+package heap
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// minLive returns the smallest value of the nodes that weren't popped
+func minLive(nodes []*PairingNode[int]) (int, bool) {
+	var values []int
+	for _, n := range nodes {
+		if !n.popped {
+			values = append(values, n.Value())
+		}
+	}
+	if len(values) == 0 {
+		return 0, false
+	}
+	return slices.Min(values), true
+}
+
+// The model is the list of handles Insert returned: the heap must always
+// pop the smallest value of the ones not popped yet, whatever DecreaseKey
+// and Meld did to them
+func TestPairingHeapAgainstModel(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	h := MakePairingHeap(lessInt)
+	var nodes []*PairingNode[int]
+	live := 0
+	for i := 0; i < 5000; i++ {
+		switch r.IntN(6) {
+		case 0, 1:
+			nodes = append(nodes, h.Insert(r.IntN(1000)))
+			live++
+		case 2:
+			want, ok := minLive(nodes)
+			got, popped := h.Pop()
+			if popped != ok || got != want {
+				t.Fatalf("step %d: Pop() = %d, %v, want %d, %v", i, got, popped, want, ok)
+			}
+			if popped {
+				live--
+			}
+		case 3:
+			if len(nodes) == 0 {
+				continue
+			}
+			n := nodes[r.IntN(len(nodes))]
+			old, value := n.Value(), r.IntN(1000)
+			want := !n.popped && value <= old
+			if got := h.DecreaseKey(n, value); got != want {
+				t.Fatalf("step %d: DecreaseKey(%d to %d, popped %v) = %v", i, old, value, n.popped, got)
+			}
+			if !want && n.Value() != old {
+				t.Fatalf("step %d: a refused DecreaseKey changed the value", i)
+			}
+		case 4:
+			other := MakePairingHeap(lessInt)
+			for j := r.IntN(20); j > 0; j-- {
+				nodes = append(nodes, other.Insert(r.IntN(1000)))
+				live++
+			}
+			h.Meld(other)
+			if other.Len() != 0 {
+				t.Fatalf("step %d: Meld left %d elements in its argument", i, other.Len())
+			}
+		case 5:
+			h.Meld(h) // melding a heap into itself changes nothing
+		}
+		if h.Len() != live {
+			t.Fatalf("step %d: Len() = %d, want %d", i, h.Len(), live)
+		}
+		want, ok := minLive(nodes)
+		if got, found := h.Peek(); found != ok || got != want {
+			t.Fatalf("step %d: Peek() = %d, %v, want %d, %v", i, got, found, want, ok)
+		}
+	}
+	var want []int
+	for _, n := range nodes {
+		if !n.popped {
+			want = append(want, n.Value())
+		}
+	}
+	slices.Sort(want)
+	if all := slices.Sorted(h.All()); !slices.Equal(all, want) {
+		t.Fatalf("All() = %v, want %v", all, want)
+	}
+	var popped []int
+	for h.Len() > 0 {
+		value, _ := h.DeleteMin()
+		popped = append(popped, value)
+	}
+	if !slices.Equal(popped, want) {
+		t.Fatalf("popping everything gave %v, want %v", popped, want)
+	}
+}
@@ -0,0 +1,160 @@
+package histogram
+
+import (
+	"math"
+	"math/bits"
+)
+
+// HDR is a high dynamic range histogram of non negative int64 values, e.g.
+// latencies in nanoseconds. Values below 2^precision get a bucket each,
+// above that every power of two range is split into 2^(precision-1) buckets,
+// so any recorded value is known with a relative error below 2^-(precision-1).
+// Buckets are allocated lazily up to the largest value seen.
+type HDR struct {
+	precision uint
+	counts    []uint64
+	total     uint64
+	sum       float64
+	min       int64
+	max       int64
+}
+
+// MakeHDR takes the precision in bits between 1 and 16, 7 keeps the error under 2%
+func MakeHDR(precision uint) *HDR {
+	if precision < 1 {
+		precision = 1
+	}
+	if precision > 16 {
+		precision = 16
+	}
+	return &HDR{precision: precision, min: math.MaxInt64, max: -1}
+}
+
+func (h *HDR) bucketOf(value uint64) int {
+	if value < 1<<h.precision {
+		return int(value)
+	}
+	shift := uint(bits.Len64(value)) - h.precision
+	mantissa := value >> shift // in [2^(precision-1), 2^precision)
+	half := uint64(1) << (h.precision - 1)
+	return int(1<<h.precision + uint64(shift-1)*half + (mantissa - half))
+}
+
+// bucketRange returns the lowest and highest value counted in the bucket
+func (h *HDR) bucketRange(bucket int) (uint64, uint64) {
+	if bucket < 1<<h.precision {
+		return uint64(bucket), uint64(bucket)
+	}
+	half := uint64(1) << (h.precision - 1)
+	rest := uint64(bucket) - 1<<h.precision
+	shift := rest/half + 1
+	mantissa := rest%half + half
+	return mantissa << shift, (mantissa+1)<<shift - 1
+}
+
+// Record ignores negative values
+func (h *HDR) Record(value int64) {
+	h.RecordN(value, 1)
+}
+
+func (h *HDR) RecordN(value int64, n uint64) {
+	if value < 0 || n == 0 {
+		return
+	}
+	bucket := h.bucketOf(uint64(value))
+	if bucket >= len(h.counts) {
+		grown := make([]uint64, bucket+1)
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	h.counts[bucket] += n
+	h.total += n
+	h.sum += float64(value) * float64(n)
+	if value < h.min {
+		h.min = value
+	}
+	if value > h.max {
+		h.max = value
+	}
+}
+
+func (h *HDR) Count() uint64 {
+	return h.total
+}
+
+func (h *HDR) Mean() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / float64(h.total)
+}
+
+func (h *HDR) Min() int64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.min
+}
+
+func (h *HDR) Max() int64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.max
+}
+
+// Quantile returns the middle of the bucket holding the sample at quantile q (0..1),
+// clamped to the extremes actually recorded
+func (h *HDR) Quantile(q float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := quantileRank(q, h.total)
+	var seen uint64
+	for bucket, count := range h.counts {
+		seen += count
+		if seen < rank {
+			continue
+		}
+		low, high := h.bucketRange(bucket)
+		middle := int64(low + (high-low)/2)
+		if middle < h.min {
+			return h.min
+		}
+		if middle > h.max {
+			return h.max
+		}
+		return middle
+	}
+	return h.max
+}
+
+// Merge adds the samples of other, which must have the same precision
+func (h *HDR) Merge(other *HDR) error {
+	if h.precision != other.precision {
+		return ErrIncompatible
+	}
+	if len(other.counts) > len(h.counts) {
+		grown := make([]uint64, len(other.counts))
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	h.total += other.total
+	h.sum += other.sum
+	if other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	return nil
+}
+
+func (h *HDR) Reset() {
+	h.counts = nil
+	h.total, h.sum = 0, 0
+	h.min, h.max = math.MaxInt64, -1
+}
@@ -0,0 +1,180 @@
+// Package histogram contains streaming histograms: values are counted into
+// buckets as they come, so quantiles of any number of samples take fixed memory.
+// Fixed uses user defined bucket bounds, HDR covers the whole int64 range
+// with a bounded relative error and suits latencies.
+package histogram
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var ErrIncompatible = errors.New("histogram: merging histograms with different buckets")
+
+// Fixed counts float64 values into buckets with the given upper bounds,
+// plus an overflow bucket for values above the last bound.
+// Quantiles are interpolated linearly inside a bucket.
+type Fixed struct {
+	bounds []float64 // ascending upper bounds, inclusive
+	counts []uint64  // len(bounds)+1, the last one is the overflow bucket
+	total  uint64
+	sum    float64
+	min    float64
+	max    float64
+}
+
+func MakeFixed(bounds []float64) *Fixed {
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	return &Fixed{
+		bounds: sorted,
+		counts: make([]uint64, len(sorted)+1),
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+	}
+}
+
+// MakeLinearFixed has count buckets of equal width starting at start
+func MakeLinearFixed(start, width float64, count int) *Fixed {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + width*float64(i+1)
+	}
+	return MakeFixed(bounds)
+}
+
+// MakeExponentialFixed has count buckets, each factor times wider than the previous one
+func MakeExponentialFixed(start, factor float64, count int) *Fixed {
+	bounds := make([]float64, count)
+	bound := start
+	for i := range bounds {
+		bounds[i] = bound
+		bound *= factor
+	}
+	return MakeFixed(bounds)
+}
+
+func (h *Fixed) Record(value float64) {
+	h.RecordN(value, 1)
+}
+
+func (h *Fixed) RecordN(value float64, n uint64) {
+	if n == 0 || math.IsNaN(value) {
+		return
+	}
+	h.counts[sort.SearchFloat64s(h.bounds, value)] += n
+	h.total += n
+	h.sum += value * float64(n)
+	h.min = math.Min(h.min, value)
+	h.max = math.Max(h.max, value)
+}
+
+func (h *Fixed) Count() uint64 {
+	return h.total
+}
+
+func (h *Fixed) Mean() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / float64(h.total)
+}
+
+func (h *Fixed) Min() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.min
+}
+
+func (h *Fixed) Max() float64 {
+	if h.total == 0 {
+		return 0
+	}
+	return h.max
+}
+
+// Quantile returns an estimate of the value below which q (0..1) of the samples are
+func (h *Fixed) Quantile(q float64) float64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := quantileRank(q, h.total)
+	var seen uint64
+	for i, count := range h.counts {
+		if seen+count < rank {
+			seen += count
+			continue
+		}
+		// interpolate within the bucket, clamped by the extremes actually seen
+		low, high := h.min, h.max
+		if i > 0 && h.bounds[i-1] > low {
+			low = h.bounds[i-1]
+		}
+		if i < len(h.bounds) && h.bounds[i] < high {
+			high = h.bounds[i]
+		}
+		if rank == seen+count { // the last sample of the bucket, without rounding past high
+			return high
+		}
+		return low + (high-low)*float64(rank-seen)/float64(count)
+	}
+	return h.max
+}
+
+// Buckets visits every bucket upper bound with its count, the overflow bucket has +Inf
+func (h *Fixed) Buckets(fn func(upperBound float64, count uint64) bool) {
+	for i, count := range h.counts {
+		bound := math.Inf(1)
+		if i < len(h.bounds) {
+			bound = h.bounds[i]
+		}
+		if !fn(bound, count) {
+			return
+		}
+	}
+}
+
+// Merge adds the samples of other, which must have the same bounds
+func (h *Fixed) Merge(other *Fixed) error {
+	if len(h.bounds) != len(other.bounds) {
+		return ErrIncompatible
+	}
+	for i := range h.bounds {
+		if h.bounds[i] != other.bounds[i] {
+			return ErrIncompatible
+		}
+	}
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	h.total += other.total
+	h.sum += other.sum
+	h.min = math.Min(h.min, other.min)
+	h.max = math.Max(h.max, other.max)
+	return nil
+}
+
+func (h *Fixed) Reset() {
+	for i := range h.counts {
+		h.counts[i] = 0
+	}
+	h.total, h.sum = 0, 0
+	h.min, h.max = math.Inf(1), math.Inf(-1)
+}
+
+// quantileRank is the 1 based rank of the sample at quantile q
+func quantileRank(q float64, total uint64) uint64 {
+	if q <= 0 {
+		return 1
+	}
+	if q >= 1 {
+		return total
+	}
+	rank := uint64(math.Ceil(q * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+	return rank
+}
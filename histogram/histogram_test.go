@@ -0,0 +1,154 @@
+package histogram
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"testing"
+)
+
+var quantiles = []float64{0, 0.001, 0.1, 0.25, 0.5, 0.9, 0.99, 0.999, 1}
+
+// exactQuantile is the sample of rank quantileRank in sorted
+func exactQuantile[T any](sorted []T, q float64) T {
+	return sorted[quantileRank(q, uint64(len(sorted)))-1]
+}
+
+// A quantile of Fixed must fall into the bucket of the exact quantile,
+// that is all the buckets know
+func TestFixedQuantileStaysInTheBucket(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	h := MakeExponentialFixed(1, 2, 12)
+	var samples []float64
+	for i := 0; i < 10000; i++ {
+		value := math.Exp(r.NormFloat64()*2 + 3) // lognormal, some land in the overflow bucket
+		samples = append(samples, value)
+		h.Record(value)
+	}
+	h.Record(math.NaN()) // ignored
+	slices.Sort(samples)
+	for _, q := range quantiles {
+		exact := exactQuantile(samples, q)
+		i := sort.SearchFloat64s(h.bounds, exact)
+		low, high := samples[0], samples[len(samples)-1]
+		if i > 0 {
+			low = max(low, h.bounds[i-1])
+		}
+		if i < len(h.bounds) {
+			high = min(high, h.bounds[i])
+		}
+		if got := h.Quantile(q); got < low || got > high {
+			t.Fatalf("Quantile(%v) = %v, the exact %v is in the bucket [%v, %v]", q, got, exact, low, high)
+		}
+	}
+	if h.Count() != 10000 || h.Min() != samples[0] || h.Max() != samples[len(samples)-1] {
+		t.Fatalf("Count() = %d, Min() = %v, Max() = %v", h.Count(), h.Min(), h.Max())
+	}
+	total := uint64(0)
+	last := 0.0
+	h.Buckets(func(bound float64, count uint64) bool {
+		total += count
+		last = bound
+		return true
+	})
+	if total != h.Count() || !math.IsInf(last, 1) {
+		t.Fatalf("Buckets counted %d samples up to %v, want %d up to +Inf", total, last, h.Count())
+	}
+}
+
+func TestFixedMergeAndReset(t *testing.T) {
+	a, b, all := MakeLinearFixed(0, 10, 10), MakeLinearFixed(0, 10, 10), MakeLinearFixed(0, 10, 10)
+	for i := 0; i < 200; i++ {
+		value := float64(i) * 0.7
+		all.Record(value)
+		if i%3 == 0 {
+			a.Record(value)
+		} else {
+			b.RecordN(value, 1)
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(a.counts, all.counts) || a.Count() != all.Count() || a.Min() != all.Min() || a.Max() != all.Max() || math.Abs(a.Mean()-all.Mean()) > 1e-9 {
+		t.Fatalf("merged %+v, want %+v", a, all)
+	}
+	if err := a.Merge(MakeLinearFixed(0, 10, 9)); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("Merge with other bounds = %v, want ErrIncompatible", err)
+	}
+	if err := a.Merge(MakeLinearFixed(1, 10, 10)); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("Merge with shifted bounds = %v, want ErrIncompatible", err)
+	}
+	a.Reset()
+	if a.Count() != 0 || a.Quantile(0.5) != 0 || a.Min() != 0 || a.Mean() != 0 {
+		t.Fatal("Reset left samples behind")
+	}
+}
+
+// Every value must land in a bucket whose range holds it and is narrow
+// enough for the promised relative error, up to MaxInt64
+func TestHDRBuckets(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for precision := uint(1); precision <= 16; precision++ {
+		h := MakeHDR(precision)
+		values := []uint64{0, 1, 1<<precision - 1, 1 << precision, 1<<precision + 1, math.MaxInt64}
+		for i := 0; i < 1000; i++ {
+			values = append(values, r.Uint64N(math.MaxInt64)>>r.IntN(63))
+		}
+		for _, value := range values {
+			bucket := h.bucketOf(value)
+			low, high := h.bucketRange(bucket)
+			if value < low || value > high {
+				t.Fatalf("precision %d: %d in bucket %d of [%d, %d]", precision, value, bucket, low, high)
+			}
+			if float64(high-low) > float64(low)/math.Exp2(float64(precision-1)) {
+				t.Fatalf("precision %d: bucket [%d, %d] is wider than the relative error allows", precision, low, high)
+			}
+			if bucket > 0 {
+				if _, previousHigh := h.bucketRange(bucket - 1); previousHigh != low-1 {
+					t.Fatalf("precision %d: bucket %d starts at %d, the one before ends at %d", precision, bucket, low, previousHigh)
+				}
+			}
+		}
+	}
+}
+
+func TestHDRQuantileError(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	const precision = 7
+	a, b := MakeHDR(precision), MakeHDR(precision)
+	var samples []int64
+	for i := 0; i < 20000; i++ {
+		value := int64(math.Exp(r.NormFloat64()*3 + 10))
+		samples = append(samples, value)
+		if i%2 == 0 {
+			a.Record(value)
+		} else {
+			b.Record(value)
+		}
+	}
+	a.Record(-1) // ignored
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(samples)
+	for _, q := range quantiles {
+		exact := exactQuantile(samples, q)
+		got := a.Quantile(q)
+		if math.Abs(float64(got-exact)) > float64(exact)/math.Exp2(precision-1) {
+			t.Fatalf("Quantile(%v) = %d, exact %d, beyond the relative error", q, got, exact)
+		}
+	}
+	if a.Count() != uint64(len(samples)) || a.Min() != samples[0] || a.Max() != samples[len(samples)-1] {
+		t.Fatalf("Count() = %d, Min() = %d, Max() = %d", a.Count(), a.Min(), a.Max())
+	}
+	if err := a.Merge(MakeHDR(precision + 1)); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("Merge with another precision = %v, want ErrIncompatible", err)
+	}
+	a.Reset()
+	if a.Count() != 0 || a.Quantile(0.5) != 0 || a.Max() != 0 {
+		t.Fatal("Reset left samples behind")
+	}
+}
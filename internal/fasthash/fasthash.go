@@ -0,0 +1,83 @@
+// Package fasthash hashes keys of string, integer, float and bool kinds
+// with hash/maphash, straight from their memory: no interface conversion,
+// no encoding and no allocation. It works on defined types too, e.g.
+// type UserID int64, which a type switch on int64 misses. Keys that are
+// constraints.Hashable are hashed by their own Hash method instead.
+// The maps decide the Kind once per map and fall back to their generic
+// encoding for Generic.
+//
+// Float keys must be normalized before they get here, -0 and +0 or two
+// NaNs differ in their bits.
+package fasthash
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"reflect"
+	"unsafe"
+
+	"hashmaps/constraints"
+)
+
+type Kind uint8
+
+const (
+	Generic Kind = iota // not handled here
+	String
+	Fixed1 // 1 byte of raw memory: int8, uint8, bool
+	Fixed2
+	Fixed4 // int32, uint32, float32, ...
+	Fixed8 // int, int64, uint64, float64, uintptr, ...
+	Hashable
+)
+
+func KindOf[K any]() Kind {
+	t := reflect.TypeFor[K]()
+	if t.Implements(reflect.TypeFor[constraints.Hashable]()) {
+		return Hashable
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return String
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return Fixed1
+	case reflect.Int16, reflect.Uint16:
+		return Fixed2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return Fixed4
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return Fixed8
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		if unsafe.Sizeof(uintptr(0)) == 4 {
+			return Fixed4
+		}
+		return Fixed8
+	}
+	return Generic
+}
+
+// Hash returns false for Generic, kind has to be KindOf[K]
+func Hash[K any](seed maphash.Seed, kind Kind, key K) (uint64, bool) {
+	p := unsafe.Pointer(&key)
+	switch kind {
+	case String:
+		return maphash.String(seed, *(*string)(p)), true
+	case Fixed1:
+		return Uint64(seed, uint64(*(*uint8)(p))), true
+	case Fixed2:
+		return Uint64(seed, uint64(*(*uint16)(p))), true
+	case Fixed4:
+		return Uint64(seed, uint64(*(*uint32)(p))), true
+	case Fixed8:
+		return Uint64(seed, *(*uint64)(p)), true
+	case Hashable:
+		return Uint64(seed, any(key).(constraints.Hashable).Hash()), true
+	}
+	return 0, false
+}
+
+func Uint64(seed maphash.Seed, value uint64) uint64 {
+	var buffer [8]byte
+	binary.LittleEndian.PutUint64(buffer[:], value)
+	return maphash.Bytes(seed, buffer[:])
+}
@@ -0,0 +1,26 @@
+// Package hashing is the built-in key hash of the maps, hamt and
+// cuckoofilter. The default build hashes with hash/maphash: the key kinds
+// internal/fasthash knows straight from memory, any other key gob encoded.
+// Builds with the tinygo or lighthash tag, where gob is too heavy, use
+// lighthash instead, see hashing_light.go.
+//
+// Every Seed hashes differently, so a map's bucket positions can't be
+// predicted from the outside, and a rehash with a new Seed moves every key.
+// Seeds are random per process, nothing is stable across runs.
+// Float keys must be normalized before they get here, see internal/keynorm.
+package hashing
+
+import "errors"
+
+var ErrKeyEncoding = errors.New("key can't be encoded for hashing")
+
+// Mix is the splitmix64 finalizer, for deriving a second hash from a
+// first one and for spreading the weak low bits of lighthash
+func Mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
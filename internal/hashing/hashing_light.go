@@ -0,0 +1,38 @@
+//go:build tinygo || lighthash
+
+package hashing
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"hashmaps/lighthash"
+)
+
+// Seed is a salt, lighthash itself isn't seeded. Keys land in different
+// buckets than in the default build.
+type Seed struct {
+	salt uint64
+}
+
+var lastSalt atomic.Uint64
+
+func MakeSeed[K any]() Seed {
+	return Seed{salt: lastSalt.Add(0x9e3779b97f4a7c15)}
+}
+
+func For[K any](s Seed) Seed {
+	return s
+}
+
+func Hash[K any](s Seed, key K) (uint64, error) {
+	hashedKey, err := lighthash.Hash(key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrKeyEncoding, err)
+	}
+	return Mix(hashedKey ^ s.salt), nil
+}
+
+func (s Seed) Bytes(b []byte) uint64 {
+	return Mix(lighthash.Bytes(b) ^ s.salt)
+}
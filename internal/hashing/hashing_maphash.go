@@ -0,0 +1,45 @@
+//go:build !tinygo && !lighthash
+
+package hashing
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"hash/maphash"
+
+	"hashmaps/internal/fasthash"
+)
+
+type Seed struct {
+	seed maphash.Seed
+	kind fasthash.Kind // decided once for the key type, see internal/fasthash
+}
+
+func MakeSeed[K any]() Seed {
+	return Seed{seed: maphash.MakeSeed(), kind: fasthash.KindOf[K]()}
+}
+
+// For is s with the fast path for K, for seeds made without knowing K
+func For[K any](s Seed) Seed {
+	s.kind = fasthash.KindOf[K]()
+	return s
+}
+
+// Hash hashes strings, integers, floats and bools, defined types included,
+// without allocating. Any other key type is gob encoded first, which is a
+// lot slower but works for every gob encodable key.
+func Hash[K any](s Seed, key K) (uint64, error) {
+	if hashedKey, ok := fasthash.Hash(s.seed, s.kind, key); ok {
+		return hashedKey, nil
+	}
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(key); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrKeyEncoding, err)
+	}
+	return maphash.Bytes(s.seed, buffer.Bytes()), nil
+}
+
+func (s Seed) Bytes(b []byte) uint64 {
+	return maphash.Bytes(s.seed, b)
+}
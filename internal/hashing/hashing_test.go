@@ -0,0 +1,77 @@
+package hashing
+
+import (
+	"errors"
+	"testing"
+)
+
+type structKey struct {
+	ID   int
+	Name string
+}
+
+func TestHashIsStablePerSeed(t *testing.T) {
+	s := MakeSeed[structKey]()
+	key := structKey{ID: 1, Name: "a"}
+	first, err := Hash(s, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := Hash(s, structKey{ID: 1, Name: "a"})
+	if first != second {
+		t.Fatalf("Hash = %x then %x for equal keys", first, second)
+	}
+	if s.Bytes([]byte("abc")) != s.Bytes([]byte("abc")) {
+		t.Fatal("Bytes isn't stable for one seed")
+	}
+}
+
+func TestSeedsDiffer(t *testing.T) {
+	a, b := MakeSeed[string](), MakeSeed[string]()
+	same := 0
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		hashA, _ := Hash(a, key)
+		hashB, _ := Hash(b, key)
+		if hashA == hashB {
+			same++
+		}
+	}
+	if same > 0 {
+		t.Fatalf("%d of 8 keys hash the same under two seeds", same)
+	}
+}
+
+func TestForKeepsTheSeed(t *testing.T) {
+	s := MakeSeed[any]()
+	hashed, _ := Hash(For[string](s), "key")
+	again, _ := Hash(For[string](s), "key")
+	if hashed != again {
+		t.Fatal("For changed the hash of a key")
+	}
+}
+
+func TestUnencodableKey(t *testing.T) {
+	s := MakeSeed[func()]()
+	if _, err := Hash(s, func() {}); !errors.Is(err, ErrKeyEncoding) {
+		t.Fatalf("Hash(func) error = %v, want ErrKeyEncoding", err)
+	}
+}
+
+// cachedKey is constraints.Hashable, Hash leaves Hits out
+type cachedKey struct {
+	ID   int
+	Hits int
+}
+
+func (k cachedKey) Hash() uint64 {
+	return uint64(k.ID)
+}
+
+func TestHashableKeysHashThemselves(t *testing.T) {
+	s := MakeSeed[cachedKey]()
+	a, _ := Hash(s, cachedKey{ID: 7, Hits: 1})
+	b, _ := Hash(s, cachedKey{ID: 7, Hits: 2})
+	if a != b {
+		t.Fatal("the fields Hash leaves out changed the hash")
+	}
+}
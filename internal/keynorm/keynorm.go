@@ -0,0 +1,80 @@
+// Package keynorm makes float keys behave in the maps: the built-in map
+// accepts NaN keys that can never be found again, since NaN != NaN, and
+// hashes -0 and +0, which are ==, from different bits. Every map package
+// normalizes keys with a Normalizer and re-exports NaNPolicy, so a policy
+// means the same thing, and is the same type, in all of them.
+package keynorm
+
+import (
+	"errors"
+	"math"
+	"reflect"
+)
+
+// NaNPolicy decides what happens to float keys that are NaN.
+// Either all NaNs are one key or they are refused.
+// Independently of the policy -0 is always stored as +0, as they are ==.
+type NaNPolicy int
+
+const (
+	CanonicalizeNaN NaNPolicy = iota // every NaN is the same key, Get finds it
+	RejectNaN                        // Set of a NaN key fails with ErrNaNKey
+)
+
+var (
+	ErrNaNKey           = errors.New("NaN is not allowed as a key")
+	ErrInvalidNaNPolicy = errors.New("unknown NaNPolicy")
+)
+
+func (p NaNPolicy) Valid() bool {
+	return p == CanonicalizeNaN || p == RejectNaN
+}
+
+// Normalizer is decided once per map, so non-float keys never pay for
+// reflection. Floats nested in structs or arrays are not looked at.
+type Normalizer[K comparable] struct {
+	floatKeys bool      // K is float32/float64 and keys have to be normalized
+	nanPolicy NaNPolicy // only relevant when floatKeys is set
+}
+
+func MakeNormalizer[K comparable](nanPolicy NaNPolicy) (Normalizer[K], error) {
+	if !nanPolicy.Valid() {
+		return Normalizer[K]{}, ErrInvalidNaNPolicy
+	}
+	kind := reflect.TypeFor[K]().Kind()
+	return Normalizer[K]{floatKeys: kind == reflect.Float32 || kind == reflect.Float64, nanPolicy: nanPolicy}, nil
+}
+
+func (n Normalizer[K]) NaNPolicy() NaNPolicy {
+	return n.nanPolicy
+}
+
+// Normalize returns the key to hash and store: every NaN as the same NaN
+// and -0 as +0. It fails with ErrNaNKey for a NaN under RejectNaN, such a
+// key is never stored, so a lookup can treat it as missing.
+func (n Normalizer[K]) Normalize(key K) (K, error) {
+	if !n.floatKeys {
+		return key, nil
+	}
+	value := reflect.ValueOf(&key).Elem()
+	f := value.Float()
+	switch {
+	case math.IsNaN(f):
+		if n.nanPolicy == RejectNaN {
+			return key, ErrNaNKey
+		}
+		value.SetFloat(math.NaN())
+	case f == 0:
+		value.SetFloat(0)
+	}
+	return key, nil
+}
+
+// Equal compares normalized keys
+func (n Normalizer[K]) Equal(a, b K) bool {
+	if a == b {
+		return true
+	}
+	// after normalization the only == violation left is NaN
+	return n.floatKeys && a != a && b != b
+}
@@ -0,0 +1,51 @@
+package keynorm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeFloats(t *testing.T) {
+	n, err := MakeNormalizer[float64](CanonicalizeNaN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	negativeZero, _ := n.Normalize(math.Copysign(0, -1))
+	if math.Signbit(negativeZero) {
+		t.Fatal("-0 wasn't normalized to +0")
+	}
+	nan1, _ := n.Normalize(math.NaN())
+	nan2, _ := n.Normalize(math.Float64frombits(0x7ff8000000000001))
+	if math.Float64bits(nan1) != math.Float64bits(nan2) || !n.Equal(nan1, nan2) {
+		t.Fatal("NaNs weren't normalized to one key")
+	}
+	if n.Equal(nan1, 1) || !n.Equal(1, 1) {
+		t.Fatal("Equal is wrong for ordinary floats")
+	}
+}
+
+func TestRejectNaN(t *testing.T) {
+	n, _ := MakeNormalizer[float32](RejectNaN)
+	if _, err := n.Normalize(float32(math.NaN())); err != ErrNaNKey {
+		t.Fatalf("Normalize(NaN) = %v, want ErrNaNKey", err)
+	}
+	if key, err := n.Normalize(1.5); key != 1.5 || err != nil {
+		t.Fatalf("Normalize(1.5) = %v, %v", key, err)
+	}
+}
+
+// non-float keys, even ones with floats inside, are compared with ==
+func TestOtherKeys(t *testing.T) {
+	type point struct{ X, Y float64 }
+	n, _ := MakeNormalizer[point](RejectNaN)
+	key := point{math.NaN(), 0}
+	if normalized, err := n.Normalize(key); err != nil || n.Equal(normalized, key) {
+		t.Fatalf("struct key was normalized: %v, %v", normalized, err)
+	}
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	if _, err := MakeNormalizer[int](NaNPolicy(2)); err != ErrInvalidNaNPolicy {
+		t.Fatalf("MakeNormalizer(2) = %v, want ErrInvalidNaNPolicy", err)
+	}
+}
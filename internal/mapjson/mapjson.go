@@ -0,0 +1,75 @@
+// Package mapjson holds the JSON encoding shared by the hashmap packages.
+// Maps with string keys become plain JSON objects, like built-in maps do.
+// Any other key type becomes an array of {"key": ..., "value": ...}
+// objects, since JSON object keys can only be strings. Decoding adds to
+// the entries already in a map, as encoding/json does for built-in maps.
+package mapjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+type pair[K, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// stringKeys is true for string and every type defined on it
+func stringKeys[K any]() bool {
+	return reflect.TypeOf((*K)(nil)).Elem().Kind() == reflect.String
+}
+
+// Marshal encodes the entries visited by each. Object keys are sorted like
+// encoding/json sorts built-in maps, pairs come in the order each visits them.
+func Marshal[K, V any](each func(fn func(key K, value V) bool), length int) ([]byte, error) {
+	if stringKeys[K]() {
+		object := make(map[string]V, length)
+		each(func(key K, value V) bool {
+			object[reflect.ValueOf(key).String()] = value
+			return true
+		})
+		return json.Marshal(object)
+	}
+	pairs := make([]pair[K, V], 0, length)
+	each(func(key K, value V) bool {
+		pairs = append(pairs, pair[K, V]{Key: key, Value: value})
+		return true
+	})
+	return json.Marshal(pairs)
+}
+
+// Unmarshal decodes what Marshal encoded and passes every entry to set,
+// stopping at the first error. Entries of an object come in key order.
+func Unmarshal[K, V any](data []byte, set func(key K, value V) error) error {
+	if stringKeys[K]() {
+		var object map[string]V
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			var key K
+			reflect.ValueOf(&key).Elem().SetString(name)
+			if err := set(key, object[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var pairs []pair[K, V]
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return err
+	}
+	for _, p := range pairs {
+		if err := set(p.Key, p.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package mapjson
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type entry[K, V any] struct {
+	key   K
+	value V
+}
+
+func rangeOver[K, V any](entries []entry[K, V]) func(fn func(key K, value V) bool) {
+	return func(fn func(key K, value V) bool) {
+		for _, e := range entries {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func decode[K, V any](t *testing.T, data []byte) []entry[K, V] {
+	t.Helper()
+	var decoded []entry[K, V]
+	err := Unmarshal(data, func(key K, value V) error {
+		decoded = append(decoded, entry[K, V]{key, value})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", data, err)
+	}
+	return decoded
+}
+
+type name string
+
+func TestStringKeys(t *testing.T) {
+	entries := []entry[name, int]{{"b", 2}, {"a", 1}, {"", 0}}
+	data, err := Marshal(rangeOver(entries), len(entries))
+	if err != nil || string(data) != `{"":0,"a":1,"b":2}` {
+		t.Fatalf("Marshal = %s, %v, want a sorted object", data, err)
+	}
+	want := []entry[name, int]{{"", 0}, {"a", 1}, {"b", 2}}
+	if got := decode[name, int](t, data); !slices.Equal(got, want) {
+		t.Fatalf("Unmarshal = %v, want %v in key order", got, want)
+	}
+}
+
+func TestOtherKeys(t *testing.T) {
+	type point struct{ X, Y int }
+	entries := []entry[point, string]{{point{2, 1}, "b"}, {point{1, 2}, "a"}}
+	data, err := Marshal(rangeOver(entries), len(entries))
+	if err != nil || string(data) != `[{"key":{"X":2,"Y":1},"value":"b"},{"key":{"X":1,"Y":2},"value":"a"}]` {
+		t.Fatalf("Marshal = %s, %v, want pairs in Range order", data, err)
+	}
+	if got := decode[point, string](t, data); !slices.Equal(got, entries) {
+		t.Fatalf("Unmarshal = %v, want %v", got, entries)
+	}
+	if data, _ := Marshal(rangeOver[int, int](nil), 0); string(data) != "[]" {
+		t.Fatalf("Marshal of no entries = %s, want []", data)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := Unmarshal([]byte(`[{"key":1,"value":1},{"key":2,"value":2}]`), func(key, value int) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("Unmarshal = %v after %d calls, want the error of the first set", err, calls)
+	}
+	for _, data := range []string{`{"a":1}`, `[1]`, `[{"key":"a","value":1}]`} {
+		if err := Unmarshal([]byte(data), func(key, value int) error { return nil }); err == nil {
+			t.Fatalf("Unmarshal(%s) into int keys succeeded", data)
+		}
+	}
+	if err := Unmarshal([]byte(`[{"key":"a","value":1}]`), func(key string, value int) error { return nil }); err == nil {
+		t.Fatalf("Unmarshal of an array into string keys succeeded")
+	}
+}
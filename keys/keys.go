@@ -0,0 +1,109 @@
+// Package keys provides comparable wrappers for key types that Go does not
+// allow as map keys, such as slices. The wrappers compare and hash by content,
+// so they work with the built-in map as well as with the hashmaps in this repo.
+package keys
+
+import (
+	bytes2 "bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var ErrSliceEncoding = errors.New("slice can't be gob encoded")
+
+// BytesKey holds a private copy of a byte slice, so mutating the original
+// slice after the key was built does not change the key.
+type BytesKey struct {
+	content string
+}
+
+func MakeBytesKey(b []byte) BytesKey {
+	return BytesKey{content: string(b)}
+}
+
+// Bytes returns a fresh copy of the wrapped bytes.
+func (k BytesKey) Bytes() []byte {
+	return []byte(k.content)
+}
+
+func (k BytesKey) Len() int {
+	return len(k.content)
+}
+
+func (k BytesKey) String() string {
+	return k.content
+}
+
+// GobEncode lets hashers built on encoding/gob see the content even though
+// the field is unexported.
+func (k BytesKey) GobEncode() ([]byte, error) {
+	return []byte(k.content), nil
+}
+
+func (k *BytesKey) GobDecode(data []byte) error {
+	k.content = string(data)
+	return nil
+}
+
+// SliceKey holds the gob encoding of a slice. Two SliceKeys are == when
+// the encodings are, for most element types that is when the slices have
+// equal length and elements. Where gob and == disagree the encoding wins:
+// -0.0 and +0.0 are different keys, a NaN is the same key as a NaN with
+// the same bits, pointers are compared by what they point to and
+// unexported struct fields are left out.
+type SliceKey[T comparable] struct {
+	content string
+}
+
+// MakeSliceKey panics when s can't be gob encoded, e.g. elements of a
+// struct type without exported fields. TryMakeSliceKey returns the error.
+func MakeSliceKey[T comparable](s []T) SliceKey[T] {
+	k, err := TryMakeSliceKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+func TryMakeSliceKey[T comparable](s []T) (SliceKey[T], error) {
+	var buffer bytes2.Buffer
+	encoder := gob.NewEncoder(&buffer)
+	// nil and empty slices are the same key
+	if s == nil {
+		s = []T{}
+	}
+	if err := encoder.Encode(s); err != nil {
+		return SliceKey[T]{}, fmt.Errorf("%w: %v", ErrSliceEncoding, err)
+	}
+	return SliceKey[T]{content: buffer.String()}, nil
+}
+
+// Slice decodes a fresh copy of the wrapped slice. It panics when the key
+// holds something else than an encoded []T, which only a GobDecode of
+// foreign data can cause, TrySlice returns the error.
+func (k SliceKey[T]) Slice() []T {
+	s, err := k.TrySlice()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (k SliceKey[T]) TrySlice() ([]T, error) {
+	var s []T
+	decoder := gob.NewDecoder(bytes2.NewBufferString(k.content))
+	if err := decoder.Decode(&s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (k SliceKey[T]) GobEncode() ([]byte, error) {
+	return []byte(k.content), nil
+}
+
+func (k *SliceKey[T]) GobDecode(data []byte) error {
+	k.content = string(data)
+	return nil
+}
@@ -0,0 +1,60 @@
+package keys
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSliceKeyEquality(t *testing.T) {
+	if MakeSliceKey([]int{1, 2}) != MakeSliceKey([]int{1, 2}) {
+		t.Fatal("equal slices made different keys")
+	}
+	if MakeSliceKey([]int{1, 2}) == MakeSliceKey([]int{2, 1}) {
+		t.Fatal("different slices made the same key")
+	}
+	if MakeSliceKey([]int(nil)) != MakeSliceKey([]int{}) {
+		t.Fatal("nil and empty made different keys")
+	}
+	// the documented differences from ==
+	if MakeSliceKey([]float64{0}) == MakeSliceKey([]float64{math.Copysign(0, -1)}) {
+		t.Fatal("-0.0 and +0.0 made the same key")
+	}
+	if MakeSliceKey([]float64{math.NaN()}) != MakeSliceKey([]float64{math.NaN()}) {
+		t.Fatal("NaNs with the same bits made different keys")
+	}
+}
+
+func TestSliceRoundTrip(t *testing.T) {
+	s := []string{"a", "b"}
+	k := MakeSliceKey(s)
+	s[0] = "changed"
+	if got := k.Slice(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("Slice = %q, want [a b]", got)
+	}
+}
+
+type unexported struct{ n int }
+
+func TestTryMakeSliceKey(t *testing.T) {
+	if _, err := TryMakeSliceKey([]unexported{{1}}); !errors.Is(err, ErrSliceEncoding) {
+		t.Fatalf("TryMakeSliceKey error = %v, want ErrSliceEncoding", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MakeSliceKey didn't panic")
+		}
+	}()
+	MakeSliceKey([]unexported{{1}})
+}
+
+func TestTrySliceOfForeignData(t *testing.T) {
+	var k SliceKey[int]
+	if err := k.GobDecode([]byte("not gob")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.TrySlice(); err == nil {
+		t.Fatal("TrySlice decoded garbage")
+	}
+}
@@ -0,0 +1,139 @@
+// Package lighthash hashes arbitrary comparable keys without encoding/gob,
+// crypto/sha256 or math/big, which bloat or don't build on TinyGo and WASM.
+// The maps use it instead of their usual hashing when built with the
+// tinygo or lighthash build tag.
+//
+// Keys are fed field by field into 64-bit FNV-1a. Pointers and channels are
+// hashed by address, the same thing == compares. Keys with a Hash() uint64
+// method are hashed by it.
+package lighthash
+
+import (
+	"errors"
+	"math"
+	"reflect"
+
+	"hashmaps/constraints"
+)
+
+var ErrUnhashable = errors.New("lighthash: key type can't be hashed")
+
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+type hasher uint64
+
+func (h *hasher) byte(b byte) {
+	*h = (*h ^ hasher(b)) * prime64
+}
+
+func (h *hasher) uint64(v uint64) {
+	for i := 0; i < 8; i++ {
+		h.byte(byte(v >> (8 * i)))
+	}
+}
+
+func (h *hasher) string(s string) {
+	h.uint64(uint64(len(s))) // length prefix, so ("ab", "c") and ("a", "bc") differ
+	for i := 0; i < len(s); i++ {
+		h.byte(s[i])
+	}
+}
+
+func (h *hasher) float(f float64) {
+	if f == 0 { // -0 == +0
+		f = 0
+	}
+	h.uint64(math.Float64bits(f))
+}
+
+// Hash returns the hash of key, equal keys always have equal hashes
+func Hash(key any) (uint64, error) {
+	h := hasher(offset64)
+	// fast paths for the usual key types, no reflection
+	switch k := key.(type) {
+	case string:
+		h.string(k)
+	case int:
+		h.uint64(uint64(k))
+	case int64:
+		h.uint64(uint64(k))
+	case int32:
+		h.uint64(uint64(k))
+	case uint:
+		h.uint64(uint64(k))
+	case uint64:
+		h.uint64(k)
+	case uint32:
+		h.uint64(uint64(k))
+	case constraints.Hashable:
+		h.uint64(k.Hash())
+	default:
+		if err := h.value(reflect.ValueOf(key)); err != nil {
+			return 0, err
+		}
+	}
+	return uint64(h), nil
+}
+
+// Bytes is Hash of a string with the content of b, without the conversion
+func Bytes(b []byte) uint64 {
+	h := hasher(offset64)
+	h.uint64(uint64(len(b)))
+	for _, c := range b {
+		h.byte(c)
+	}
+	return uint64(h)
+}
+
+func (h *hasher) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Invalid: // nil interface
+		h.byte(0)
+	case reflect.Bool:
+		if v.Bool() {
+			h.byte(1)
+		} else {
+			h.byte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.uint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.uint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.float(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		h.float(real(c))
+		h.float(imag(c))
+	case reflect.String:
+		h.string(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		h.uint64(uint64(v.Pointer()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := h.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := h.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			h.byte(0)
+			return nil
+		}
+		// values of different dynamic types are never ==, mixing in the type keeps them apart
+		h.string(v.Elem().Type().String())
+		return h.value(v.Elem())
+	default: // slices, maps and funcs can't be keys
+		return ErrUnhashable
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package list is a doubly linked list, container/list with typed values
+package list
+
+import "iter"
+
+// Element is a handle to a value in a List, it stays valid until the
+// value is removed
+type Element[T any] struct {
+	Value      T
+	prev, next *Element[T]
+	list       *List[T] // nil once removed
+}
+
+// Next returns nil after the back of the list
+func (e *Element[T]) Next() *Element[T] {
+	if next := e.next; e.list != nil && next != &e.list.root {
+		return next
+	}
+	return nil
+}
+
+// Prev returns nil before the front of the list
+func (e *Element[T]) Prev() *Element[T] {
+	if prev := e.prev; e.list != nil && prev != &e.list.root {
+		return prev
+	}
+	return nil
+}
+
+// List is a ring around a sentinel element, so inserting and unlinking
+// never check for the ends. The methods taking an element do nothing when
+// it belongs to another list or was removed.
+type List[T any] struct {
+	root   Element[T] // sentinel, root.next is the front and root.prev the back
+	length int
+}
+
+func MakeList[T any]() *List[T] {
+	l := &List[T]{}
+	l.root.prev, l.root.next = &l.root, &l.root
+	return l
+}
+
+// Len returns the number of elements, in O(1)
+func (l *List[T]) Len() int {
+	return l.length
+}
+
+// Front returns nil for an empty list
+func (l *List[T]) Front() *Element[T] {
+	if l.length == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back returns nil for an empty list
+func (l *List[T]) Back() *Element[T] {
+	if l.length == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+func (l *List[T]) PushFront(value T) *Element[T] {
+	return l.insert(&Element[T]{Value: value}, &l.root)
+}
+
+func (l *List[T]) PushBack(value T) *Element[T] {
+	return l.insert(&Element[T]{Value: value}, l.root.prev)
+}
+
+// InsertBefore returns nil when mark isn't in l
+func (l *List[T]) InsertBefore(value T, mark *Element[T]) *Element[T] {
+	if mark.list != l {
+		return nil
+	}
+	return l.insert(&Element[T]{Value: value}, mark.prev)
+}
+
+// InsertAfter returns nil when mark isn't in l
+func (l *List[T]) InsertAfter(value T, mark *Element[T]) *Element[T] {
+	if mark.list != l {
+		return nil
+	}
+	return l.insert(&Element[T]{Value: value}, mark)
+}
+
+// Remove unlinks e and returns its value
+func (l *List[T]) Remove(e *Element[T]) T {
+	if e.list == l {
+		l.unlink(e)
+	}
+	return e.Value
+}
+
+func (l *List[T]) MoveToFront(e *Element[T]) {
+	if e.list != l || l.root.next == e {
+		return
+	}
+	l.unlink(e)
+	l.insert(e, &l.root)
+}
+
+func (l *List[T]) MoveToBack(e *Element[T]) {
+	if e.list != l || l.root.prev == e {
+		return
+	}
+	l.unlink(e)
+	l.insert(e, l.root.prev)
+}
+
+// All yields the values from the front to the back.
+// The list must not be modified during the loop.
+func (l *List[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.root.next; e != &l.root; e = e.next {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward walks from the back to the front
+func (l *List[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.root.prev; e != &l.root; e = e.prev {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// insert links e after at
+func (l *List[T]) insert(e, at *Element[T]) *Element[T] {
+	e.prev, e.next = at, at.next
+	at.next.prev = e
+	at.next = e
+	e.list = l
+	l.length++
+	return e
+}
+
+func (l *List[T]) unlink(e *Element[T]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next, e.list = nil, nil, nil
+	l.length--
+}
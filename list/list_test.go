@@ -0,0 +1,108 @@
+package list
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkAgainst walks l both ways, by element and by iterator
+func checkAgainst(t *testing.T, l *List[int], want []*Element[int]) {
+	t.Helper()
+	var forward, backward []*Element[int]
+	for e := l.Front(); e != nil; e = e.Next() {
+		forward = append(forward, e)
+	}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		backward = append(backward, e)
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(forward, want) || !slices.Equal(backward, want) || l.Len() != len(want) {
+		t.Fatalf("%d elements forward and %d backward with Len() %d, want %d", len(forward), len(backward), l.Len(), len(want))
+	}
+	values := make([]int, len(want))
+	for i, e := range want {
+		values[i] = e.Value
+	}
+	if got := slices.Collect(l.All()); !slices.Equal(got, values) {
+		t.Fatalf("All() = %v, want %v", got, values)
+	}
+	slices.Reverse(values)
+	if got := slices.Collect(l.Backward()); !slices.Equal(got, values) {
+		t.Fatalf("Backward() = %v, want %v", got, values)
+	}
+}
+
+func TestAgainstSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	l := MakeList[int]()
+	var want []*Element[int]
+	for i := 0; i < 2000; i++ {
+		if len(want) == 0 {
+			want = append(want, l.PushBack(i))
+			continue
+		}
+		at := r.IntN(len(want))
+		mark := want[at]
+		switch r.IntN(7) {
+		case 0:
+			want = slices.Insert(want, 0, l.PushFront(i))
+		case 1:
+			want = append(want, l.PushBack(i))
+		case 2:
+			want = slices.Insert(want, at, l.InsertBefore(i, mark))
+		case 3:
+			want = slices.Insert(want, at+1, l.InsertAfter(i, mark))
+		case 4:
+			if value := l.Remove(mark); value != mark.Value {
+				t.Fatalf("Remove() = %d, want %d", value, mark.Value)
+			}
+			want = slices.Delete(want, at, at+1)
+			if mark.Next() != nil || mark.Prev() != nil {
+				t.Fatalf("a removed element still has neighbours")
+			}
+		case 5:
+			l.MoveToFront(mark)
+			want = slices.Insert(slices.Delete(want, at, at+1), 0, mark)
+		case 6:
+			l.MoveToBack(mark)
+			want = append(slices.Delete(want, at, at+1), mark)
+		}
+		if i%100 == 0 {
+			checkAgainst(t, l, want)
+		}
+	}
+	checkAgainst(t, l, want)
+}
+
+// Elements of another list and removed ones must leave the list alone
+func TestForeignElements(t *testing.T) {
+	l, other := MakeList[int](), MakeList[int]()
+	l.PushBack(1)
+	l.PushBack(2)
+	foreign := other.PushBack(3)
+	removed := l.PushFront(0)
+	l.Remove(removed)
+	want := []*Element[int]{l.Front(), l.Back()}
+	for _, e := range []*Element[int]{foreign, removed} {
+		if l.InsertBefore(9, e) != nil || l.InsertAfter(9, e) != nil {
+			t.Fatalf("insert next to an element not in the list succeeded")
+		}
+		l.MoveToFront(e)
+		l.MoveToBack(e)
+		l.Remove(e)
+		checkAgainst(t, l, want)
+	}
+	if other.Len() != 1 || other.Front() != foreign {
+		t.Fatalf("the other list changed")
+	}
+	if l.Remove(removed) != 0 {
+		t.Fatalf("Remove of a removed element must still return its value")
+	}
+
+	empty := MakeList[int]()
+	if empty.Front() != nil || empty.Back() != nil || empty.Len() != 0 {
+		t.Fatalf("an empty list has elements")
+	}
+	checkAgainst(t, empty, nil)
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package offheap
+
+// Without mmap the chunks come from the Go heap. The map still works,
+// but the GC benefits are gone.
+
+func mapChunk(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+func unmapChunk(chunk []byte) error {
+	return nil
+}
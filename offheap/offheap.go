@@ -0,0 +1,423 @@
+// Package offheap provides a map whose entries live outside of the Go heap,
+// in memory mapped regions managed by the package. The garbage collector
+// never scans them, so huge datasets don't make GC pauses or heap growth worse.
+// The price is that keys and values are gob encoded on the way in and
+// decoded on the way out, and the memory has to be released with Free.
+package offheap
+
+import (
+	bytes2 "bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"hash/maphash"
+	"iter"
+	"reflect"
+
+	"hashmaps/internal/keynorm"
+)
+
+var (
+	ErrFreed      = errors.New("offheap: map used after Free")
+	ErrNilPointer = errors.New("offheap: a nil pointer can't be gob encoded")
+)
+
+const (
+	defaultChunkSize = 64 << 20
+	headerSize       = 16 // next location, key length, value length
+	offsetBits       = 40
+	noLocation       = ^uint64(0)
+)
+
+// Map stores every entry as a record [next][key length][value length][key][value]
+// appended to the current chunk. The heap side is just an index from the hash
+// of the encoded key to the first record with that hash; records with equal
+// hashes are chained through next. The index holds no pointers, so the GC
+// does not scan it either.
+// Overwritten and deleted records are not reused until the map is freed,
+// GarbageBytes tells how much space they take.
+//
+// Keys are equal when their gob encodings are. For most keys that is ==,
+// but gob encodes what a pointer points to: two pointers to equal values
+// are the same key, a nil pointer can't be a key (ErrNilPointer) and Range
+// hands out new pointers. Float keys are normalized like in the other maps,
+// every NaN is the same key and -0 is 0. Floats inside a struct or array
+// key are not, a NaN there is found by a NaN with the same bits, and -0
+// is 0 in a struct field, which gob leaves out like 0, but not in an array.
+type Map[K comparable, V any] struct {
+	index     map[uint64]uint64
+	chunks    [][]byte
+	used      int // bytes used in the last chunk
+	chunkSize int
+	length    int
+	live      int
+	garbage   int
+	seed      maphash.Seed
+	norm      keynorm.Normalizer[K]
+	freed     bool
+}
+
+func MakeMap[K comparable, V any]() *Map[K, V] {
+	return MakeMapWithChunkSize[K, V](defaultChunkSize)
+}
+
+// MakeMapWithChunkSize sets how much memory is mapped at a time,
+// records bigger than that get a chunk of their own
+func MakeMapWithChunkSize[K comparable, V any](chunkSize int) *Map[K, V] {
+	if chunkSize < headerSize {
+		chunkSize = defaultChunkSize
+	}
+	norm, _ := keynorm.MakeNormalizer[K](keynorm.CanonicalizeNaN) // always valid
+	return &Map[K, V]{
+		index:     make(map[uint64]uint64),
+		chunkSize: chunkSize,
+		seed:      maphash.MakeSeed(),
+		norm:      norm,
+	}
+}
+
+func (m *Map[K, V]) Len() int {
+	return m.length
+}
+
+// LiveBytes is the size of records reachable from the index
+func (m *Map[K, V]) LiveBytes() int {
+	return m.live
+}
+
+// GarbageBytes is the size of overwritten and deleted records
+func (m *Map[K, V]) GarbageBytes() int {
+	return m.garbage
+}
+
+// MappedBytes is the memory held outside of the Go heap
+func (m *Map[K, V]) MappedBytes() int {
+	mapped := 0
+	for _, chunk := range m.chunks {
+		mapped += len(chunk)
+	}
+	return mapped
+}
+
+// Set, Get, Delete and Range panic when a key or value can't be gob encoded
+// or decoded, or when no memory can be mapped. TrySet, TryGet, TryDelete and
+// TryRange return these errors instead.
+
+func (m *Map[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	value, ok, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+func (m *Map[K, V]) Delete(key K) bool {
+	deleted, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return deleted
+}
+
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	if err := m.TryRange(fn); err != nil {
+		panic(err)
+	}
+}
+
+// All is the range-over-func form of Range
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *Map[K, V]) TrySet(key K, value V) error {
+	m.checkNotFreed()
+	keyBytes, err := m.encodeKey(key)
+	if err != nil {
+		return err
+	}
+	valueBytes, err := encode(value)
+	if err != nil {
+		return err
+	}
+	hash := maphash.Bytes(m.seed, keyBytes)
+
+	location, err := m.appendRecord(keyBytes, valueBytes)
+	if err != nil {
+		return err
+	}
+	previous, existing := m.find(hash, keyBytes)
+	if existing == noLocation {
+		head, ok := m.index[hash]
+		if !ok {
+			head = noLocation
+		}
+		m.setNext(location, head)
+		m.index[hash] = location
+		m.length++
+	} else {
+		// the new record takes the place of the old one in the chain
+		m.setNext(location, m.next(existing))
+		m.relink(hash, previous, location)
+		m.dropRecord(existing)
+	}
+	return nil
+}
+
+func (m *Map[K, V]) TryGet(key K) (V, bool, error) {
+	m.checkNotFreed()
+	var value V
+	keyBytes, err := m.encodeKey(key)
+	if err != nil {
+		return value, false, err
+	}
+	_, location := m.find(maphash.Bytes(m.seed, keyBytes), keyBytes)
+	if location == noLocation {
+		return value, false, nil
+	}
+	decoder := gob.NewDecoder(bytes2.NewReader(m.valueBytes(location)))
+	if err := decoder.Decode(&value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+func (m *Map[K, V]) TryDelete(key K) (bool, error) {
+	m.checkNotFreed()
+	keyBytes, err := m.encodeKey(key)
+	if err != nil {
+		return false, err
+	}
+	hash := maphash.Bytes(m.seed, keyBytes)
+	previous, location := m.find(hash, keyBytes)
+	if location == noLocation {
+		return false, nil
+	}
+	next := m.next(location)
+	if next == noLocation && previous == noLocation {
+		delete(m.index, hash)
+	} else {
+		m.relink(hash, previous, next)
+	}
+	m.dropRecord(location)
+	m.length--
+	return true, nil
+}
+
+// TryRange decodes every entry and calls fn with it until fn returns false,
+// in no particular order. The map must not be modified by fn.
+func (m *Map[K, V]) TryRange(fn func(key K, value V) bool) error {
+	m.checkNotFreed()
+	for _, head := range m.index {
+		for location := head; location != noLocation; location = m.next(location) {
+			var key K
+			if err := gob.NewDecoder(bytes2.NewReader(m.keyBytes(location))).Decode(&key); err != nil {
+				return err
+			}
+			var value V
+			if err := gob.NewDecoder(bytes2.NewReader(m.valueBytes(location))).Decode(&value); err != nil {
+				return err
+			}
+			if !fn(key, value) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Free returns all mapped memory to the OS. The map must not be used afterwards,
+// doing so panics with ErrFreed.
+func (m *Map[K, V]) Free() error {
+	if m.freed {
+		return nil
+	}
+	m.freed = true
+	m.index = nil
+	err := unmapChunks(m.chunks)
+	m.chunks = nil
+	return err
+}
+
+// Clear removes all entries and unmaps every chunk right away instead of
+// keeping them for reuse. It returns the number of bytes given back to the OS.
+func (m *Map[K, V]) Clear() (int, error) {
+	m.checkNotFreed()
+	released := m.MappedBytes()
+	err := unmapChunks(m.chunks)
+	m.chunks = nil
+	m.used = 0
+	m.index = make(map[uint64]uint64)
+	m.length, m.live, m.garbage = 0, 0, 0
+	return released, err
+}
+
+// Compact copies live records into fresh chunks and unmaps the old ones,
+// dropping the space taken by overwritten and deleted records.
+// It returns the number of bytes given back to the OS. For a while both
+// the old and the new chunks are mapped, so it needs up to LiveBytes extra.
+// When that memory can't be mapped the map is left as it was.
+func (m *Map[K, V]) Compact() (int, error) {
+	m.checkNotFreed()
+	oldChunks, oldUsed, oldLive, oldGarbage := m.chunks, m.used, m.live, m.garbage
+	oldMapped := m.MappedBytes()
+	m.chunks = nil
+	m.used = 0
+	m.live, m.garbage = 0, 0
+	index := make(map[uint64]uint64, len(m.index))
+	for hash, head := range m.index {
+		previous := noLocation
+		for location := head; location != noLocation; location = binary.LittleEndian.Uint64(recordIn(oldChunks, location)) {
+			record := recordIn(oldChunks, location)
+			keyLength := int(binary.LittleEndian.Uint32(record[8:]))
+			moved, err := m.appendRecord(record[headerSize:headerSize+keyLength], record[headerSize+keyLength:])
+			if err != nil {
+				unmapChunks(m.chunks)
+				m.chunks, m.used, m.live, m.garbage = oldChunks, oldUsed, oldLive, oldGarbage
+				return 0, err
+			}
+			if previous == noLocation {
+				index[hash] = moved
+			} else {
+				m.setNext(previous, moved)
+			}
+			previous = moved
+		}
+	}
+	m.index = index
+	return oldMapped - m.MappedBytes(), unmapChunks(oldChunks)
+}
+
+func unmapChunks(chunks [][]byte) error {
+	var firstErr error
+	for _, chunk := range chunks {
+		if err := unmapChunk(chunk); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *Map[K, V]) checkNotFreed() {
+	if m.freed {
+		panic(ErrFreed)
+	}
+}
+
+// encodeKey normalizes key before encoding it, gob would tell -0 from 0
+// and one NaN from another
+func (m *Map[K, V]) encodeKey(key K) ([]byte, error) {
+	key, _ = m.norm.Normalize(key) // never fails under CanonicalizeNaN
+	return encode(key)
+}
+
+func encode(value any) ([]byte, error) {
+	// gob panics on these instead of returning an error
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, ErrNilPointer
+	}
+	var buffer bytes2.Buffer
+	encoder := gob.NewEncoder(&buffer)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// find walks the chain of hash and returns the location of the record
+// with keyBytes and of the record before it in the chain
+func (m *Map[K, V]) find(hash uint64, keyBytes []byte) (uint64, uint64) {
+	location, ok := m.index[hash]
+	if !ok {
+		return noLocation, noLocation
+	}
+	previous := noLocation
+	for location != noLocation {
+		if bytes2.Equal(m.keyBytes(location), keyBytes) {
+			return previous, location
+		}
+		previous, location = location, m.next(location)
+	}
+	return noLocation, noLocation
+}
+
+// relink makes whatever pointed at the record after previous point at location
+func (m *Map[K, V]) relink(hash uint64, previous, location uint64) {
+	if previous == noLocation {
+		m.index[hash] = location
+	} else {
+		m.setNext(previous, location)
+	}
+}
+
+func (m *Map[K, V]) appendRecord(keyBytes, valueBytes []byte) (uint64, error) {
+	size := headerSize + len(keyBytes) + len(valueBytes)
+	if len(m.chunks) == 0 || m.used+size > len(m.chunks[len(m.chunks)-1]) {
+		chunkSize := m.chunkSize
+		if size > chunkSize {
+			chunkSize = size
+		}
+		chunk, err := mapChunk(chunkSize)
+		if err != nil {
+			return noLocation, err
+		}
+		m.chunks = append(m.chunks, chunk)
+		m.used = 0
+	}
+	chunkIndex := len(m.chunks) - 1
+	record := m.chunks[chunkIndex][m.used : m.used+size]
+	binary.LittleEndian.PutUint64(record[0:8], noLocation)
+	binary.LittleEndian.PutUint32(record[8:12], uint32(len(keyBytes)))
+	binary.LittleEndian.PutUint32(record[12:16], uint32(len(valueBytes)))
+	copy(record[headerSize:], keyBytes)
+	copy(record[headerSize+len(keyBytes):], valueBytes)
+	location := uint64(chunkIndex)<<offsetBits | uint64(m.used)
+	m.used += size
+	m.live += size
+	return location, nil
+}
+
+func (m *Map[K, V]) dropRecord(location uint64) {
+	size := len(m.record(location))
+	m.live -= size
+	m.garbage += size
+}
+
+// record returns the whole record at location
+func (m *Map[K, V]) record(location uint64) []byte {
+	return recordIn(m.chunks, location)
+}
+
+func recordIn(chunks [][]byte, location uint64) []byte {
+	chunk := chunks[location>>offsetBits]
+	offset := int(location & (1<<offsetBits - 1))
+	keyLength := int(binary.LittleEndian.Uint32(chunk[offset+8:]))
+	valueLength := int(binary.LittleEndian.Uint32(chunk[offset+12:]))
+	return chunk[offset : offset+headerSize+keyLength+valueLength]
+}
+
+func (m *Map[K, V]) next(location uint64) uint64 {
+	return binary.LittleEndian.Uint64(m.record(location))
+}
+
+func (m *Map[K, V]) setNext(location, next uint64) {
+	binary.LittleEndian.PutUint64(m.record(location), next)
+}
+
+func (m *Map[K, V]) keyBytes(location uint64) []byte {
+	record := m.record(location)
+	keyLength := int(binary.LittleEndian.Uint32(record[8:]))
+	return record[headerSize : headerSize+keyLength]
+}
+
+func (m *Map[K, V]) valueBytes(location uint64) []byte {
+	record := m.record(location)
+	keyLength := int(binary.LittleEndian.Uint32(record[8:]))
+	return record[headerSize+keyLength:]
+}
@@ -0,0 +1,192 @@
+package offheap
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func checkAgainst(t *testing.T, m *Map[int, string], want map[int]string) {
+	t.Helper()
+	if m.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", m.Len(), len(want))
+	}
+	for key, value := range want {
+		if got, ok := m.Get(key); !ok || got != value {
+			t.Fatalf("Get(%d) = %.10q, %v, want %.10q", key, got, ok, value)
+		}
+	}
+	ranged := map[int]string{}
+	for key, value := range m.All() {
+		if _, seen := ranged[key]; seen {
+			t.Fatalf("Range visited %d twice", key)
+		}
+		ranged[key] = value
+	}
+	if len(ranged) != len(want) {
+		t.Fatalf("Range visited %d entries, want %d", len(ranged), len(want))
+	}
+	for key, value := range ranged {
+		if want[key] != value {
+			t.Fatalf("Range gave %d: %.10q, want %.10q", key, value, want[key])
+		}
+	}
+	if m.LiveBytes()+m.GarbageBytes() > m.MappedBytes() {
+		t.Fatalf("%d live and %d garbage bytes in %d mapped", m.LiveBytes(), m.GarbageBytes(), m.MappedBytes())
+	}
+}
+
+// Small chunks make the records spread over many of them, and the long
+// values get a chunk of their own
+func TestAgainstMap(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	m := MakeMapWithChunkSize[int, string](256)
+	defer m.Free()
+	want := map[int]string{}
+	for i := 0; i < 3000; i++ {
+		key := r.IntN(300)
+		if r.IntN(3) == 0 {
+			_, found := want[key]
+			if m.Delete(key) != found {
+				t.Fatalf("Delete(%d) = %v, want %v", key, !found, found)
+			}
+			delete(want, key)
+			continue
+		}
+		value := strings.Repeat(string(rune('a'+r.IntN(26))), r.IntN(400))
+		m.Set(key, value)
+		want[key] = value
+	}
+	checkAgainst(t, m, want)
+	if _, ok := m.Get(-1); ok {
+		t.Fatalf("Get(-1) found a key never set")
+	}
+	if m.Delete(-1) {
+		t.Fatalf("Delete(-1) = true for a key never set")
+	}
+}
+
+func TestCompactAndClear(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	m := MakeMapWithChunkSize[int, string](1024)
+	defer m.Free()
+	want := map[int]string{}
+	for i := 0; i < 2000; i++ {
+		key := r.IntN(100)
+		want[key] = strings.Repeat("x", r.IntN(100))
+		m.Set(key, want[key])
+	}
+	for key := 0; key < 50; key++ {
+		m.Delete(key)
+		delete(want, key)
+	}
+	live, mapped := m.LiveBytes(), m.MappedBytes()
+	if m.GarbageBytes() == 0 {
+		t.Fatalf("no garbage after overwriting and deleting")
+	}
+	released, err := m.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released <= 0 || released != mapped-m.MappedBytes() {
+		t.Fatalf("Compact() = %d, mapped went from %d to %d", released, mapped, m.MappedBytes())
+	}
+	if m.GarbageBytes() != 0 || m.LiveBytes() != live {
+		t.Fatalf("after Compact %d live and %d garbage bytes, want %d and 0", m.LiveBytes(), m.GarbageBytes(), live)
+	}
+	checkAgainst(t, m, want)
+
+	// the compacted map keeps working, chains included
+	m.Set(1000, "new")
+	m.Delete(99)
+	want[1000] = "new"
+	delete(want, 99)
+	checkAgainst(t, m, want)
+
+	mapped = m.MappedBytes()
+	released, err = m.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != mapped || m.MappedBytes() != 0 || m.LiveBytes() != 0 || m.GarbageBytes() != 0 {
+		t.Fatalf("Clear() = %d of %d mapped, left %d mapped, %d live, %d garbage",
+			released, mapped, m.MappedBytes(), m.LiveBytes(), m.GarbageBytes())
+	}
+	checkAgainst(t, m, map[int]string{})
+	m.Set(1, "again")
+	checkAgainst(t, m, map[int]string{1: "again"})
+}
+
+func TestFree(t *testing.T) {
+	m := MakeMap[string, int]()
+	m.Set("a", 1)
+	if err := m.Free(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Free(); err != nil {
+		t.Fatalf("second Free() = %v", err)
+	}
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrFreed) {
+			t.Fatalf("Get after Free panicked with %v, want ErrFreed", err)
+		}
+	}()
+	m.Get("a")
+}
+
+func TestFloatKeys(t *testing.T) {
+	m := MakeMap[float64, int]()
+	defer m.Free()
+	m.Set(math.Copysign(0, -1), 1)
+	if got, ok := m.Get(0); !ok || got != 1 {
+		t.Fatalf("Get(0) = %d, %v after Set(-0, 1)", got, ok)
+	}
+	m.Set(math.NaN(), 2)
+	m.Set(-math.NaN(), 3)
+	if got, ok := m.Get(math.NaN()); !ok || got != 3 || m.Len() != 2 {
+		t.Fatalf("Get(NaN) = %d, %v with Len() %d, want every NaN to be one key", got, ok, m.Len())
+	}
+
+	// floats inside a key are compared by their encoding
+	type point struct{ X, Y float64 }
+	points := MakeMap[point, int]()
+	defer points.Free()
+	points.Set(point{0, 0}, 1)
+	points.Set(point{math.NaN(), 0}, 2)
+	if _, ok := points.Get(point{math.Copysign(0, -1), 0}); !ok {
+		t.Fatalf("-0 in a struct field didn't find the key with 0")
+	}
+	if _, ok := points.Get(point{math.NaN(), 0}); !ok {
+		t.Fatalf("NaN in a struct field didn't find the key with the same NaN")
+	}
+	if _, ok := points.Get(point{-math.NaN(), 0}); ok {
+		t.Fatalf("NaN in a struct field found the key with another NaN")
+	}
+	arrays := MakeMap[[2]float64, int]()
+	defer arrays.Free()
+	arrays.Set([2]float64{0, 0}, 1)
+	if _, ok := arrays.Get([2]float64{math.Copysign(0, -1), 0}); ok {
+		t.Fatalf("-0 in an array found the key with 0")
+	}
+}
+
+func TestPointerKeys(t *testing.T) {
+	m := MakeMap[*int, string]()
+	defer m.Free()
+	a, b := new(int), new(int)
+	*a, *b = 7, 7
+	m.Set(a, "a")
+	if got, ok := m.Get(b); !ok || got != "a" {
+		t.Fatalf("Get(b) = %q, %v, want the entry of a, which points to the same value", got, ok)
+	}
+	for key := range m.All() {
+		if key == a || *key != 7 {
+			t.Fatalf("Range gave %p -> %d, want a new pointer to 7", key, *key)
+		}
+	}
+	if err := m.TrySet(nil, "nil"); !errors.Is(err, ErrNilPointer) {
+		t.Fatalf("TrySet(nil) = %v, want ErrNilPointer", err)
+	}
+}
@@ -0,0 +1,19 @@
+package openmap
+
+import "hashmaps/internal/mapjson"
+
+// MarshalJSON writes the map as internal/mapjson describes, the pairs of
+// non-string keys come in slot order
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mapjson.Marshal(m.Range, m.Len())
+}
+
+// UnmarshalJSON adds the decoded entries through TrySet, so they may land
+// on tombstones and the map keeps its load factor. A zero HashMap, e.g. one
+// that json.Unmarshal allocated, gets MakeHashMap's load factor first.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	if m.capacity == 0 {
+		*m = *MakeHashMap[K, V]()
+	}
+	return mapjson.Unmarshal(data, m.TrySet)
+}
@@ -0,0 +1,298 @@
+// Package openmap is a hashmap with open addressing: entries are stored
+// inline in one flat slice, collisions are resolved by linear probing and
+// deleted entries leave tombstones behind. Compared to chainedmap there are
+// no per-entry allocations and probing walks neighbouring memory.
+package openmap
+
+import (
+	"errors"
+	"iter"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+type KVPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type slotState uint8
+
+const (
+	empty     slotState = iota // never used since the last rehash, ends every probe
+	occupied                   // holds a live entry
+	tombstone                  // held an entry that was deleted, probes continue past it
+)
+
+type slot[K comparable, V any] struct {
+	state slotState
+	pair  KVPair[K, V]
+}
+
+// A key lives in the first free slot at or after its hash, wrapping around.
+// Deleting can't simply empty the slot, that would cut the probe sequence of
+// keys stored after it, so the slot becomes a tombstone. Tombstones are
+// reused by Set and dropped by the next rehash.
+type HashMap[K comparable, V any] struct {
+	capacity int64 // len(slots)
+	slots    []slot[K, V]
+
+	length        int          // occupied slots
+	tombstones    int          // tombstone slots
+	maxLoadFactor float64      // rehash once (length+tombstones)/capacity would go beyond this
+	seed          hashing.Seed // see internal/hashing
+
+	norm keynorm.Normalizer[K] // float keys and the NaNPolicy, see internal/keynorm
+}
+
+const (
+	defaultCapacity      = 8
+	defaultMaxLoadFactor = 0.7
+)
+
+var ErrInvalidLoadFactor = errors.New("max load factor must be between 0 and 1")
+
+func MakeHashMap[K comparable, V any]() *HashMap[K, V] {
+	m, _ := makeHashMap[K, V](CanonicalizeNaN, defaultMaxLoadFactor) // the defaults are always valid
+	return m
+}
+
+// NaNPolicy is what MakeHashMapWithNaNPolicy takes, it is internal/keynorm's,
+// so the other map packages take the same values. A NaN key rejected under
+// RejectNaN never takes a slot, not even a tombstone.
+type NaNPolicy = keynorm.NaNPolicy
+
+const (
+	CanonicalizeNaN = keynorm.CanonicalizeNaN
+	RejectNaN       = keynorm.RejectNaN
+)
+
+var (
+	ErrNaNKey           = keynorm.ErrNaNKey
+	ErrInvalidNaNPolicy = keynorm.ErrInvalidNaNPolicy
+)
+
+func MakeHashMapWithNaNPolicy[K comparable, V any](nanPolicy NaNPolicy) (*HashMap[K, V], error) {
+	return makeHashMap[K, V](nanPolicy, defaultMaxLoadFactor)
+}
+
+// MakeHashMapWithLoadFactor sets how full the slice may get, tombstones included.
+// Probe sequences get long quickly as it approaches 1, so it must stay below.
+func MakeHashMapWithLoadFactor[K comparable, V any](maxLoadFactor float64) (*HashMap[K, V], error) {
+	return makeHashMap[K, V](CanonicalizeNaN, maxLoadFactor)
+}
+
+func makeHashMap[K comparable, V any](nanPolicy NaNPolicy, maxLoadFactor float64) (*HashMap[K, V], error) {
+	norm, err := keynorm.MakeNormalizer[K](nanPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if !(maxLoadFactor > 0 && maxLoadFactor < 1) {
+		return nil, ErrInvalidLoadFactor
+	}
+	return &HashMap[K, V]{
+		capacity:      defaultCapacity,
+		slots:         make([]slot[K, V], defaultCapacity),
+		maxLoadFactor: maxLoadFactor,
+		seed:          hashing.MakeSeed[K](),
+		norm:          norm,
+	}, nil
+}
+
+var ErrKeyEncoding = hashing.ErrKeyEncoding
+
+func (m *HashMap[K, V]) tryHash(key K) (int, error) {
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return 0, err
+	}
+	return int(hashedKey % uint64(m.capacity)), nil
+}
+
+// Get, Set, Delete and Remove panic when the key can't be hashed, e.g. a gob encoding failure.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *HashMap[K, V]) Get(key K) *V {
+	value, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (m *HashMap[K, V]) TryGet(key K) (*V, error) {
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return nil, nil
+	}
+	index, found, err := m.find(key)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &m.slots[index].pair.Value, nil
+}
+
+// Set also panics when the key is rejected by the NaNPolicy
+func (m *HashMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *HashMap[K, V]) TrySet(key K, value V) error {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		return err
+	}
+	index, found, err := m.find(key)
+	if err != nil {
+		return err
+	}
+	if found {
+		m.slots[index].pair.Value = value
+		return nil
+	}
+	if m.slots[index].state == tombstone {
+		m.tombstones--
+	}
+	m.slots[index] = slot[K, V]{state: occupied, pair: KVPair[K, V]{Key: key, Value: value}}
+	m.length++
+	if float64(m.length+m.tombstones) > m.maxLoadFactor*float64(m.capacity) {
+		m.rehash()
+	}
+	return nil
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *HashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *HashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+// Pop is Delete, the popped slot stays a tombstone until a Set reuses it
+// or the next rehash
+func (m *HashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+func (m *HashMap[K, V]) TryDelete(key K) (V, bool, error) {
+	var value V
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return value, false, nil
+	}
+	index, found, err := m.find(key)
+	if err != nil || !found {
+		return value, false, err
+	}
+	value = m.slots[index].pair.Value
+	m.slots[index] = slot[K, V]{state: tombstone} // drops the references held by the pair
+	m.length--
+	m.tombstones++
+	return value, true, nil
+}
+
+// Len returns the number of entries, in O(1)
+func (m *HashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, like sync.Map.Range.
+// The map must not be modified by fn.
+func (m *HashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for i := range m.slots {
+		if m.slots[i].state == occupied && !fn(m.slots[i].pair.Key, m.slots[i].pair.Value) {
+			return
+		}
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// find probes for key. found tells whether index holds key; otherwise index
+// is where key should go: the first tombstone passed, or the empty slot
+// that ended the probe.
+func (m *HashMap[K, V]) find(key K) (int, bool, error) {
+	index, err := m.tryHash(key)
+	if err != nil {
+		return 0, false, err
+	}
+	firstTombstone := -1
+	for {
+		switch m.slots[index].state {
+		case empty:
+			if firstTombstone >= 0 {
+				return firstTombstone, false, nil
+			}
+			return index, false, nil
+		case tombstone:
+			if firstTombstone < 0 {
+				firstTombstone = index
+			}
+		case occupied:
+			if m.norm.Equal(m.slots[index].pair.Key, key) {
+				return index, true, nil
+			}
+		}
+		index++
+		if index == len(m.slots) {
+			index = 0
+		}
+	}
+}
+
+// rehash moves the live entries into a fresh slice without tombstones.
+// The slice doubles when the live entries alone use more than half of the
+// allowed load, otherwise it just gets cleaned up at the same size.
+// Either way at least half of the allowed load is free afterwards,
+// so rehashes stay amortized O(1) per Set.
+func (m *HashMap[K, V]) rehash() {
+	oldSlots := m.slots
+	if float64(m.length) > m.maxLoadFactor*float64(m.capacity)/2 {
+		m.capacity *= 2
+	}
+	m.slots = make([]slot[K, V], m.capacity)
+	m.tombstones = 0
+	for i := range oldSlots {
+		if oldSlots[i].state != occupied {
+			continue
+		}
+		// keys in the table were hashed before, and nothing needs to be compared
+		index, _ := m.tryHash(oldSlots[i].pair.Key)
+		for m.slots[index].state != empty {
+			index++
+			if index == len(m.slots) {
+				index = 0
+			}
+		}
+		m.slots[index] = oldSlots[i]
+	}
+}
@@ -0,0 +1,34 @@
+package openmap
+
+// ProbeStats describes how far entries sit from their home slot,
+// i.e. how many extra slots a lookup of each key has to look at
+type ProbeStats struct {
+	Max      int
+	Mean     float64
+	Variance float64
+}
+
+// ProbeStats is for comparing the map with other open addressing schemes
+func (m *HashMap[K, V]) ProbeStats() ProbeStats {
+	var stats ProbeStats
+	if m.length == 0 {
+		return stats
+	}
+	sum, sumOfSquares := 0.0, 0.0
+	for i := range m.slots {
+		if m.slots[i].state != occupied {
+			continue
+		}
+		home, _ := m.tryHash(m.slots[i].pair.Key) // stored keys were hashed before
+		distance := (i - home + len(m.slots)) % len(m.slots)
+		if distance > stats.Max {
+			stats.Max = distance
+		}
+		sum += float64(distance)
+		sumOfSquares += float64(distance) * float64(distance)
+	}
+	n := float64(m.length)
+	stats.Mean = sum / n
+	stats.Variance = sumOfSquares/n - stats.Mean*stats.Mean
+	return stats
+}
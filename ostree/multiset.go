@@ -0,0 +1,270 @@
+// Package ostree contains an order-statistics tree: a balanced search tree
+// where every node knows how many elements are in its subtree, so the
+// position of an element and the element at a position are found in O(log n).
+package ostree
+
+import "iter"
+
+type node[T any] struct {
+	value       T
+	count       int // occurrences of value
+	size        int // total occurrences in the subtree, duplicates included
+	priority    uint64
+	left, right *node[T]
+}
+
+func (n *node[T]) subtreeSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[T]) update() {
+	n.size = n.count + n.left.subtreeSize() + n.right.subtreeSize()
+}
+
+// Multiset is a sorted bag of values, duplicates are stored as a counter
+// on a single node. It is balanced as a treap (heap-ordered random priorities).
+type Multiset[T any] struct {
+	root *node[T]
+	less func(a, b T) bool
+	seed uint64
+}
+
+func MakeMultiset[T any](less func(a, b T) bool) *Multiset[T] {
+	return &Multiset[T]{less: less, seed: 0x9E3779B97F4A7C15}
+}
+
+// nextPriority is xorshift64, the tree shape does not need a crypto grade source
+func (s *Multiset[T]) nextPriority() uint64 {
+	s.seed ^= s.seed << 13
+	s.seed ^= s.seed >> 7
+	s.seed ^= s.seed << 17
+	return s.seed
+}
+
+// Len counts duplicates
+func (s *Multiset[T]) Len() int {
+	return s.root.subtreeSize()
+}
+
+func (s *Multiset[T]) Add(value T) {
+	s.AddN(value, 1)
+}
+
+func (s *Multiset[T]) AddN(value T, n int) {
+	if n <= 0 {
+		return
+	}
+	s.root = s.insert(s.root, value, n)
+}
+
+// Remove takes away a single occurrence of value
+func (s *Multiset[T]) Remove(value T) bool {
+	return s.RemoveN(value, 1) == 1
+}
+
+// RemoveN takes away up to n occurrences of value and returns how many were removed
+func (s *Multiset[T]) RemoveN(value T, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	var removed int
+	s.root, removed = s.delete(s.root, value, n)
+	return removed
+}
+
+func (s *Multiset[T]) Count(value T) int {
+	if found := s.find(value); found != nil {
+		return found.count
+	}
+	return 0
+}
+
+func (s *Multiset[T]) Contains(value T) bool {
+	return s.find(value) != nil
+}
+
+// Rank is the number of elements strictly smaller than value,
+// i.e. the index at which the first occurrence of value is (or would be)
+func (s *Multiset[T]) Rank(value T) int {
+	rank := 0
+	for current := s.root; current != nil; {
+		switch {
+		case s.less(value, current.value):
+			current = current.left
+		case s.less(current.value, value):
+			rank += current.left.subtreeSize() + current.count
+			current = current.right
+		default:
+			return rank + current.left.subtreeSize()
+		}
+	}
+	return rank
+}
+
+// Select returns the element at index k (0 based) of the sorted sequence, duplicates included
+func (s *Multiset[T]) Select(k int) (T, bool) {
+	if k < 0 || k >= s.Len() {
+		var zero T
+		return zero, false
+	}
+	current := s.root
+	for {
+		leftSize := current.left.subtreeSize()
+		switch {
+		case k < leftSize:
+			current = current.left
+		case k < leftSize+current.count:
+			return current.value, true
+		default:
+			k -= leftSize + current.count
+			current = current.right
+		}
+	}
+}
+
+func (s *Multiset[T]) Min() (T, bool) {
+	return s.Select(0)
+}
+
+func (s *Multiset[T]) Max() (T, bool) {
+	return s.Select(s.Len() - 1)
+}
+
+// Range visits elements in ascending order, every duplicate separately,
+// until fn returns false
+func (s *Multiset[T]) Range(fn func(value T) bool) {
+	s.RangeDistinct(func(value T, count int) bool {
+		for i := 0; i < count; i++ {
+			if !fn(value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// RangeDistinct visits every distinct value once in ascending order, together with its count
+func (s *Multiset[T]) RangeDistinct(fn func(value T, count int) bool) {
+	var stack []*node[T]
+	current := s.root
+	for current != nil || len(stack) > 0 {
+		for current != nil {
+			stack = append(stack, current)
+			current = current.left
+		}
+		current = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !fn(current.value, current.count) {
+			return
+		}
+		current = current.right
+	}
+}
+
+// All is Range as an iter.Seq
+func (s *Multiset[T]) All() iter.Seq[T] {
+	return s.Range
+}
+
+// Distinct is RangeDistinct as an iter.Seq2 of values and counts
+func (s *Multiset[T]) Distinct() iter.Seq2[T, int] {
+	return s.RangeDistinct
+}
+
+func (s *Multiset[T]) find(value T) *node[T] {
+	current := s.root
+	for current != nil {
+		switch {
+		case s.less(value, current.value):
+			current = current.left
+		case s.less(current.value, value):
+			current = current.right
+		default:
+			return current
+		}
+	}
+	return nil
+}
+
+func (s *Multiset[T]) insert(n *node[T], value T, count int) *node[T] {
+	if n == nil {
+		return &node[T]{value: value, count: count, size: count, priority: s.nextPriority()}
+	}
+	switch {
+	case s.less(value, n.value):
+		n.left = s.insert(n.left, value, count)
+		if n.left.priority > n.priority {
+			n = rotateRight(n)
+		}
+	case s.less(n.value, value):
+		n.right = s.insert(n.right, value, count)
+		if n.right.priority > n.priority {
+			n = rotateLeft(n)
+		}
+	default:
+		n.count += count
+	}
+	n.update()
+	return n
+}
+
+func (s *Multiset[T]) delete(n *node[T], value T, count int) (*node[T], int) {
+	if n == nil {
+		return nil, 0
+	}
+	var removed int
+	switch {
+	case s.less(value, n.value):
+		n.left, removed = s.delete(n.left, value, count)
+	case s.less(n.value, value):
+		n.right, removed = s.delete(n.right, value, count)
+	default:
+		if count < n.count {
+			n.count -= count
+			removed = count
+		} else {
+			return merge(n.left, n.right), n.count
+		}
+	}
+	n.update()
+	return n, removed
+}
+
+// merge joins two treaps where every value of a is smaller than every value of b
+func merge[T any](a, b *node[T]) *node[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = merge(a, b.left)
+	b.update()
+	return b
+}
+
+func rotateRight[T any](n *node[T]) *node[T] {
+	pivot := n.left
+	n.left = pivot.right
+	pivot.right = n
+	n.update()
+	pivot.update()
+	return pivot
+}
+
+func rotateLeft[T any](n *node[T]) *node[T] {
+	pivot := n.right
+	n.right = pivot.left
+	pivot.left = n
+	n.update()
+	pivot.update()
+	return pivot
+}
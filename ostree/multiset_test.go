@@ -0,0 +1,120 @@
+package ostree
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func less(a, b int) bool { return a < b }
+
+// checkTreap checks order, heap priorities and subtree sizes, and returns the size
+func checkTreap(t *testing.T, n *node[int], low, high int) int {
+	t.Helper()
+	if n == nil {
+		return 0
+	}
+	if n.value < low || n.value > high || n.count <= 0 {
+		t.Fatalf("node %d with count %d outside of [%d, %d]", n.value, n.count, low, high)
+	}
+	for _, child := range []*node[int]{n.left, n.right} {
+		if child != nil && child.priority > n.priority {
+			t.Fatalf("child %d has a higher priority than its parent %d", child.value, n.value)
+		}
+	}
+	size := n.count + checkTreap(t, n.left, low, n.value-1) + checkTreap(t, n.right, n.value+1, high)
+	if n.size != size {
+		t.Fatalf("node %d has size %d, its subtree holds %d", n.value, n.size, size)
+	}
+	return size
+}
+
+// The sorted slice is the model: Rank is where a value would be inserted
+// into it and Select is indexing
+func checkAgainst(t *testing.T, s *Multiset[int], want []int) {
+	t.Helper()
+	checkTreap(t, s.root, -1<<31, 1<<31)
+	if got := slices.Collect(s.All()); !slices.Equal(got, want) {
+		t.Fatalf("Range = %v, want %v", got, want)
+	}
+	for value := -1; value <= 51; value++ {
+		rank, _ := slices.BinarySearch(want, value)
+		if got := s.Rank(value); got != rank {
+			t.Fatalf("Rank(%d) = %d, want %d in %v", value, got, rank, want)
+		}
+	}
+	for k := -1; k <= len(want); k++ {
+		got, ok := s.Select(k)
+		if inside := k >= 0 && k < len(want); ok != inside || inside && got != want[k] {
+			t.Fatalf("Select(%d) = %d, %v in %v", k, got, ok, want)
+		}
+	}
+	if low, ok := s.Min(); ok != (len(want) > 0) || ok && low != want[0] {
+		t.Fatalf("Min() = %d, %v in %v", low, ok, want)
+	}
+	if high, ok := s.Max(); ok != (len(want) > 0) || ok && high != want[len(want)-1] {
+		t.Fatalf("Max() = %d, %v in %v", high, ok, want)
+	}
+}
+
+func TestAgainstSortedSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := MakeMultiset(less)
+	var want []int
+	for i := 0; i < 3000; i++ {
+		value, n := r.IntN(50), r.IntN(5)-1 // n <= 0 must do nothing
+		first, _ := slices.BinarySearch(want, value)
+		count := 0
+		for first+count < len(want) && want[first+count] == value {
+			count++
+		}
+		if r.IntN(2) == 0 {
+			s.AddN(value, n)
+			for j := 0; j < n; j++ {
+				want = slices.Insert(want, first, value)
+			}
+		} else {
+			removed := min(max(n, 0), count)
+			if got := s.RemoveN(value, n); got != removed {
+				t.Fatalf("RemoveN(%d, %d) = %d with count %d, want %d", value, n, got, count, removed)
+			}
+			want = slices.Delete(want, first, first+removed)
+		}
+		if i%50 == 0 {
+			checkAgainst(t, s, want)
+		}
+	}
+	checkAgainst(t, s, want)
+
+	for len(want) > 0 { // down to empty, through every deletion case
+		value := want[r.IntN(len(want))]
+		count := s.Count(value)
+		if !s.Remove(value) || s.Count(value) != count-1 {
+			t.Fatalf("Remove(%d) with count %d left %d", value, count, s.Count(value))
+		}
+		first, _ := slices.BinarySearch(want, value)
+		want = slices.Delete(want, first, first+1)
+		checkAgainst(t, s, want)
+	}
+	if s.Remove(1) || s.Contains(1) || s.root != nil {
+		t.Fatalf("an empty multiset still holds something")
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	s := MakeMultiset(func(a, b string) bool { return len(a) < len(b) }) // equal lengths are duplicates
+	for _, value := range []string{"ccc", "a", "bb", "b", "dd", "a"} {
+		s.Add(value)
+	}
+	var got []string
+	var counts []int
+	for value, count := range s.Distinct() {
+		got, counts = append(got, value), append(counts, count)
+	}
+	if !slices.Equal(got, []string{"a", "bb", "ccc"}) || !slices.Equal(counts, []int{3, 2, 1}) {
+		t.Fatalf("Distinct() = %v, %v, want the first value of every length with its count", got, counts)
+	}
+	if s.Rank("zz") != 3 || s.Count("x") != 3 {
+		t.Fatalf("Rank(zz) = %d, Count(x) = %d, want 3 and 3", s.Rank("zz"), s.Count("x"))
+	}
+}
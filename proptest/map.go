@@ -0,0 +1,138 @@
+package proptest
+
+import (
+	"fmt"
+	"iter"
+	"math/rand/v2"
+)
+
+// Map is the API the maps of this repo share: the six HashMaps,
+// treemap and btreemap
+type Map[K comparable, V any] interface {
+	Get(key K) *V
+	Set(key K, value V)
+	Delete(key K) (V, bool)
+	Len() int
+	All() iter.Seq2[K, V]
+}
+
+type MapOpKind int
+
+const (
+	MapGet MapOpKind = iota
+	MapSet
+	MapDelete
+)
+
+type MapOp[K, V any] struct {
+	Kind  MapOpKind
+	Key   K
+	Value V // only for MapSet
+}
+
+func (op MapOp[K, V]) String() string {
+	switch op.Kind {
+	case MapSet:
+		return fmt.Sprintf("Set(%#v, %#v)", op.Key, op.Value)
+	case MapDelete:
+		return fmt.Sprintf("Delete(%#v)", op.Key)
+	default:
+		return fmt.Sprintf("Get(%#v)", op.Key)
+	}
+}
+
+// MapOps makes Sets, Gets and Deletes in a 2:1:1 ratio, so maps tend to
+// grow over a sequence but also lose entries along the way
+func MapOps[K, V any](keys Gen[K], values Gen[V]) Gen[MapOp[K, V]] {
+	return func(r *rand.Rand) MapOp[K, V] {
+		op := MapOp[K, V]{Key: keys(r)}
+		switch r.IntN(4) {
+		case 0, 1:
+			op.Kind, op.Value = MapSet, values(r)
+		case 2:
+			op.Kind = MapGet
+		default:
+			op.Kind = MapDelete
+		}
+		return op
+	}
+}
+
+// ReplayMap applies ops to m and to a built-in map, comparing every result
+// and, after each operation, Len and the entries yielded by All
+func ReplayMap[K, V comparable](m Map[K, V], ops []MapOp[K, V]) error {
+	model := make(map[K]V)
+	for i, op := range ops {
+		switch op.Kind {
+		case MapSet:
+			m.Set(op.Key, op.Value)
+			model[op.Key] = op.Value
+		case MapGet:
+			got := m.Get(op.Key)
+			want, ok := model[op.Key]
+			if ok != (got != nil) || ok && *got != want {
+				return fmt.Errorf("op %d %v: got %s, want %s", i, op, pointee(got), found(want, ok))
+			}
+		case MapDelete:
+			got, gotOK := m.Delete(op.Key)
+			want, ok := model[op.Key]
+			delete(model, op.Key)
+			if gotOK != ok || ok && got != want {
+				return fmt.Errorf("op %d %v: got %s, want %s", i, op, found(got, gotOK), found(want, ok))
+			}
+		}
+		if err := compareEntries(m.Len(), m.All(), model); err != nil {
+			return fmt.Errorf("after op %d %v: %w", i, op, err)
+		}
+	}
+	return nil
+}
+
+func compareEntries[K, V comparable](length int, all iter.Seq2[K, V], model map[K]V) error {
+	if length != len(model) {
+		return fmt.Errorf("Len is %d, want %d", length, len(model))
+	}
+	seen := make(map[K]bool, len(model))
+	for key, value := range all {
+		want, ok := model[key]
+		switch {
+		case !ok:
+			return fmt.Errorf("All yields %#v, which shouldn't be there", key)
+		case seen[key]:
+			return fmt.Errorf("All yields %#v twice", key)
+		case value != want:
+			return fmt.Errorf("All yields %#v: %#v, want %#v", key, value, want)
+		}
+		seen[key] = true
+	}
+	if len(seen) != len(model) {
+		return fmt.Errorf("All yields %d entries, want %d", len(seen), len(model))
+	}
+	return nil
+}
+
+// CheckMap runs Check with MapOps and ReplayMap, on a fresh map from
+// makeMap for every sequence, and fails t with the shrunk sequence
+func CheckMap[K, V comparable, M Map[K, V]](t TB, cfg Config, makeMap func() M, keys Gen[K], values Gen[V]) {
+	t.Helper()
+	failure := Check(cfg, MapOps(keys, values), func(ops []MapOp[K, V]) error {
+		return ReplayMap[K, V](makeMap(), ops)
+	})
+	if failure != nil {
+		t.Fatal(failure)
+	}
+}
+
+func pointee[V any](p *V) string {
+	if p == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("&%#v", *p)
+}
+
+func found[V any](value V, ok bool) string {
+	if !ok {
+		return "not found"
+	}
+	return fmt.Sprintf("%#v", value)
+}
@@ -0,0 +1,143 @@
+// Package proptest checks collections against a model with random
+// operation sequences. A sequence that makes the collection disagree with
+// the model is shrunk to a short one before it's reported, so the failure
+// reads like a hand-written table test:
+//
+//	func TestTreeMap(t *testing.T) {
+//		proptest.CheckMap(t, proptest.Config{}, func() *treemap.TreeMap[int, string] {
+//			return treemap.MakeOrderedTreeMap[int, string]()
+//		}, proptest.IntsUpTo(50), proptest.Strings("ab", 3))
+//	}
+//
+// CheckMap and CheckSet cover the common map and set APIs with a built-in
+// map as the model. Anything else, e.g. a cache with its eviction policy,
+// brings its own model to Check.
+package proptest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Gen makes a random value
+type Gen[T any] func(r *rand.Rand) T
+
+// IntsUpTo makes ints in [0, n). A small n makes the operations hit the same
+// keys again, which is where the interesting bugs are.
+func IntsUpTo(n int) Gen[int] {
+	return func(r *rand.Rand) int { return r.IntN(n) }
+}
+
+// Strings makes strings of up to maxLen runes from alphabet
+func Strings(alphabet string, maxLen int) Gen[string] {
+	runes := []rune(alphabet)
+	return func(r *rand.Rand) string {
+		var s strings.Builder
+		for n := r.IntN(maxLen + 1); n > 0; n-- {
+			s.WriteRune(runes[r.IntN(len(runes))])
+		}
+		return s.String()
+	}
+}
+
+// OneOf picks one of items
+func OneOf[T any](items ...T) Gen[T] {
+	return func(r *rand.Rand) T { return items[r.IntN(len(items))] }
+}
+
+// Config tunes a check, the zero value is fine
+type Config struct {
+	Seed uint64 // 0 means a random seed, reported on failure to replay it
+	Runs int    // sequences to try, 100 by default
+	Ops  int    // operations per sequence, 100 by default
+}
+
+func (c Config) withDefaults() Config {
+	if c.Seed == 0 {
+		c.Seed = rand.Uint64()
+	}
+	if c.Runs <= 0 {
+		c.Runs = 100
+	}
+	if c.Ops <= 0 {
+		c.Ops = 100
+	}
+	return c
+}
+
+// Failure is a shrunk sequence that breaks the property
+type Failure[O any] struct {
+	Seed uint64
+	Ops  []O
+	Err  error // what replaying Ops reported
+}
+
+func (f *Failure[O]) Error() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%v\nafter %d operations (seed %d):", f.Err, len(f.Ops), f.Seed)
+	for _, op := range f.Ops {
+		fmt.Fprintf(&s, "\n\t%v", op)
+	}
+	return s.String()
+}
+
+// TB is the part of testing.TB the Check functions use
+type TB interface {
+	Helper()
+	Fatal(args ...any)
+}
+
+// Check generates Config.Runs sequences of Config.Ops operations with gen
+// and replays each one. replay builds a fresh collection and model, applies
+// the operations and returns an error at the first disagreement, a panic
+// counts as one too. The first failing sequence is shrunk, replay has to be
+// deterministic for that.
+func Check[O any](cfg Config, gen Gen[O], replay func(ops []O) error) *Failure[O] {
+	cfg = cfg.withDefaults()
+	replay = recovering(replay)
+	r := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	for run := 0; run < cfg.Runs; run++ {
+		ops := make([]O, cfg.Ops)
+		for i := range ops {
+			ops[i] = gen(r)
+		}
+		if err := replay(ops); err != nil {
+			ops, err = shrink(ops, err, replay)
+			return &Failure[O]{Seed: cfg.Seed, Ops: ops, Err: err}
+		}
+	}
+	return nil
+}
+
+// shrink removes chunks of operations while the sequence still fails,
+// halving the chunk size down to single operations (a simple ddmin)
+func shrink[O any](ops []O, err error, replay func(ops []O) error) ([]O, error) {
+	for chunk := len(ops) / 2; chunk >= 1; {
+		removed := false
+		for start := 0; start < len(ops); {
+			end := min(start+chunk, len(ops))
+			candidate := append(append([]O(nil), ops[:start]...), ops[end:]...)
+			if candidateErr := replay(candidate); candidateErr != nil {
+				ops, err, removed = candidate, candidateErr, true
+			} else {
+				start = end
+			}
+		}
+		if !removed {
+			chunk /= 2
+		}
+	}
+	return ops, err
+}
+
+func recovering[O any](replay func(ops []O) error) func(ops []O) error {
+	return func(ops []O) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return replay(ops)
+	}
+}
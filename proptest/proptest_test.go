@@ -0,0 +1,121 @@
+package proptest_test
+
+import (
+	"encoding/json"
+	"maps"
+	"strconv"
+	"testing"
+
+	"hashmaps/btreemap"
+	"hashmaps/chainedmap"
+	"hashmaps/cuckoo"
+	"hashmaps/hashset"
+	"hashmaps/openmap"
+	"hashmaps/proptest"
+	"hashmaps/robinhood"
+	"hashmaps/simplemap"
+	"hashmaps/swissmap"
+	"hashmaps/treemap"
+)
+
+// checkMaps runs CheckMap against every map of the repo with the same
+// key and value generators
+func checkMaps[K comparable, V comparable](t *testing.T, keys proptest.Gen[K], values proptest.Gen[V]) {
+	cfg := proptest.Config{}
+	t.Run("simplemap", func(t *testing.T) {
+		proptest.CheckMap(t, cfg, simplemap.MakeHashMap[K, V], keys, values)
+	})
+	t.Run("chainedmap", func(t *testing.T) {
+		proptest.CheckMap(t, cfg, func() *chainedmap.HashMap[K, V] {
+			return chainedmap.MakeHashMap[K, V]()
+		}, keys, values)
+	})
+	t.Run("openmap", func(t *testing.T) {
+		proptest.CheckMap(t, cfg, openmap.MakeHashMap[K, V], keys, values)
+	})
+	t.Run("robinhood", func(t *testing.T) {
+		proptest.CheckMap(t, cfg, robinhood.MakeHashMap[K, V], keys, values)
+	})
+	t.Run("cuckoo", func(t *testing.T) {
+		proptest.CheckMap(t, cfg, cuckoo.MakeHashMap[K, V], keys, values)
+	})
+	t.Run("swissmap", func(t *testing.T) {
+		proptest.CheckMap(t, cfg, swissmap.MakeHashMap[K, V], keys, values)
+	})
+}
+
+func TestHashMapsIntKeys(t *testing.T) {
+	checkMaps(t, proptest.IntsUpTo(50), proptest.IntsUpTo(1000))
+}
+
+func TestHashMapsStringKeys(t *testing.T) {
+	checkMaps(t, proptest.Strings("abc", 4), proptest.IntsUpTo(1000))
+}
+
+// wide key ranges make the maps grow through several rehashes
+func TestHashMapsGrowth(t *testing.T) {
+	checkMaps(t, proptest.IntsUpTo(1<<20), proptest.IntsUpTo(1000))
+}
+
+func TestTreeMaps(t *testing.T) {
+	keys, values := proptest.IntsUpTo(50), proptest.Strings("ab", 3)
+	t.Run("treemap", func(t *testing.T) {
+		proptest.CheckMap(t, proptest.Config{}, treemap.MakeOrderedTreeMap[int, string], keys, values)
+	})
+	t.Run("btreemap", func(t *testing.T) {
+		proptest.CheckMap(t, proptest.Config{}, btreemap.MakeOrderedBTreeMap[int, string], keys, values)
+	})
+}
+
+func TestSet(t *testing.T) {
+	proptest.CheckSet(t, proptest.Config{}, hashset.MakeSet[int], proptest.IntsUpTo(50))
+}
+
+type jsonMap[K comparable, V any] interface {
+	proptest.Map[K, V]
+	json.Marshaler
+	json.Unmarshaler
+}
+
+// checkJSON round-trips a map through a nil pointer field, which
+// json.Unmarshal fills with a zero map, so every UnmarshalJSON has to
+// make a zero map ready
+func checkJSON[K comparable, V comparable, M jsonMap[K, V]](t *testing.T, makeMap func() M, entries map[K]V) {
+	t.Helper()
+	m := makeMap()
+	for key, value := range entries {
+		m.Set(key, value)
+	}
+	data, err := json.Marshal(struct{ Map M }{m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct{ Map M }
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", data, err)
+	}
+	if got := maps.Collect(decoded.Map.All()); !maps.Equal(got, entries) {
+		t.Fatalf("decoded %v from %s, want %v", got, data, entries)
+	}
+}
+
+func TestHashMapsJSON(t *testing.T) {
+	type point struct{ X, Y int }
+	named, points := map[string]int{}, map[point]string{}
+	for i := 0; i < 100; i++ {
+		named[strconv.Itoa(i)] = i
+		points[point{i, -i}] = strconv.Itoa(i)
+	}
+	checkJSON(t, simplemap.MakeHashMap[string, int], named)
+	checkJSON(t, simplemap.MakeHashMap[point, string], points)
+	checkJSON(t, func() *chainedmap.HashMap[string, int] { return chainedmap.MakeHashMap[string, int]() }, named)
+	checkJSON(t, func() *chainedmap.HashMap[point, string] { return chainedmap.MakeHashMap[point, string]() }, points)
+	checkJSON(t, openmap.MakeHashMap[string, int], named)
+	checkJSON(t, openmap.MakeHashMap[point, string], points)
+	checkJSON(t, robinhood.MakeHashMap[string, int], named)
+	checkJSON(t, robinhood.MakeHashMap[point, string], points)
+	checkJSON(t, cuckoo.MakeHashMap[string, int], named)
+	checkJSON(t, cuckoo.MakeHashMap[point, string], points)
+	checkJSON(t, swissmap.MakeHashMap[string, int], named)
+	checkJSON(t, swissmap.MakeHashMap[point, string], points)
+}
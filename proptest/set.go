@@ -0,0 +1,100 @@
+package proptest
+
+import (
+	"fmt"
+	"iter"
+	"math/rand/v2"
+)
+
+// Set is the API of hashset.Set
+type Set[T comparable] interface {
+	Add(item T) bool
+	Remove(item T) bool
+	Contains(item T) bool
+	Len() int
+	All() iter.Seq[T]
+}
+
+type SetOpKind int
+
+const (
+	SetContains SetOpKind = iota
+	SetAdd
+	SetRemove
+)
+
+type SetOp[T any] struct {
+	Kind SetOpKind
+	Item T
+}
+
+func (op SetOp[T]) String() string {
+	switch op.Kind {
+	case SetAdd:
+		return fmt.Sprintf("Add(%#v)", op.Item)
+	case SetRemove:
+		return fmt.Sprintf("Remove(%#v)", op.Item)
+	default:
+		return fmt.Sprintf("Contains(%#v)", op.Item)
+	}
+}
+
+// SetOps makes Adds, Containses and Removes in a 2:1:1 ratio
+func SetOps[T any](items Gen[T]) Gen[SetOp[T]] {
+	return func(r *rand.Rand) SetOp[T] {
+		op := SetOp[T]{Item: items(r)}
+		switch r.IntN(4) {
+		case 0, 1:
+			op.Kind = SetAdd
+		case 2:
+			op.Kind = SetContains
+		default:
+			op.Kind = SetRemove
+		}
+		return op
+	}
+}
+
+// ReplaySet applies ops to s and to a built-in map, comparing every result
+// and, after each operation, Len and the items yielded by All
+func ReplaySet[T comparable](s Set[T], ops []SetOp[T]) error {
+	model := make(map[T]bool)
+	for i, op := range ops {
+		var got, want bool
+		switch op.Kind {
+		case SetAdd:
+			got, want = s.Add(op.Item), !model[op.Item]
+			model[op.Item] = true
+		case SetContains:
+			got, want = s.Contains(op.Item), model[op.Item]
+		case SetRemove:
+			got, want = s.Remove(op.Item), model[op.Item]
+			delete(model, op.Item)
+		}
+		if got != want {
+			return fmt.Errorf("op %d %v: got %v, want %v", i, op, got, want)
+		}
+		items := func(yield func(T, bool) bool) {
+			for item := range s.All() {
+				if !yield(item, true) {
+					return
+				}
+			}
+		}
+		if err := compareEntries(s.Len(), items, model); err != nil {
+			return fmt.Errorf("after op %d %v: %w", i, op, err)
+		}
+	}
+	return nil
+}
+
+// CheckSet is CheckMap for sets
+func CheckSet[T comparable, S Set[T]](t TB, cfg Config, makeSet func() S, items Gen[T]) {
+	t.Helper()
+	failure := Check(cfg, SetOps(items), func(ops []SetOp[T]) error {
+		return ReplaySet[T](makeSet(), ops)
+	})
+	if failure != nil {
+		t.Fatal(failure)
+	}
+}
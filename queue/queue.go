@@ -0,0 +1,78 @@
+// Package queue is a FIFO queue over a ring buffer
+package queue
+
+import "iter"
+
+const minCapacity = 8
+
+// Queue keeps its items in a ring: Dequeue moves the head forward instead
+// of reslicing, so the slots in front are reused rather than left behind
+// for the garbage collector. The ring doubles when full and halves when
+// a quarter full.
+type Queue[T any] struct {
+	items  []T
+	head   int // index of the oldest item
+	length int
+}
+
+func MakeQueue[T any]() *Queue[T] {
+	return &Queue[T]{}
+}
+
+func (q *Queue[T]) Enqueue(item T) {
+	if q.length == len(q.items) {
+		q.resize(max(2*len(q.items), minCapacity))
+	}
+	q.items[(q.head+q.length)%len(q.items)] = item
+	q.length++
+}
+
+// Dequeue returns false when the queue is empty
+func (q *Queue[T]) Dequeue() (T, bool) {
+	var zero T
+	if q.length == 0 {
+		return zero, false
+	}
+	item := q.items[q.head]
+	q.items[q.head] = zero // don't keep what it points to alive
+	q.head = (q.head + 1) % len(q.items)
+	q.length--
+	if len(q.items) > minCapacity && q.length < len(q.items)/4 {
+		q.resize(len(q.items) / 2)
+	}
+	return item, true
+}
+
+// Peek returns the item Dequeue would return
+func (q *Queue[T]) Peek() (T, bool) {
+	if q.length == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.items[q.head], true
+}
+
+func (q *Queue[T]) Len() int {
+	return q.length
+}
+
+// All yields the items from the oldest to the newest.
+// The queue must not be modified during the loop.
+func (q *Queue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < q.length; i++ {
+			if !yield(q.items[(q.head+i)%len(q.items)]) {
+				return
+			}
+		}
+	}
+}
+
+// resize moves the items to a new ring of newCapacity, head first
+func (q *Queue[T]) resize(newCapacity int) {
+	items := make([]T, newCapacity)
+	n := copy(items, q.items[q.head:min(q.head+q.length, len(q.items))])
+	copy(items[n:], q.items[:q.length-n])
+	q.items = items
+	q.head = 0
+}
@@ -0,0 +1,67 @@
+package queue
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// Bursts of enqueues and dequeues make the ring wrap around, grow and
+// shrink with the head anywhere in it
+func TestAgainstSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	q := MakeQueue[int]()
+	var want []int
+	next := 0
+	for burst := 0; burst < 200; burst++ {
+		enqueue := r.IntN(2) == 0
+		for n := r.IntN(100); n > 0; n-- {
+			if enqueue {
+				q.Enqueue(next)
+				want = append(want, next)
+				next++
+				continue
+			}
+			item, ok := q.Dequeue()
+			if ok != (len(want) > 0) || ok && item != want[0] {
+				t.Fatalf("Dequeue() = %d, %v with %d items", item, ok, len(want))
+			}
+			if ok {
+				want = want[1:]
+			}
+		}
+		if item, ok := q.Peek(); ok != (len(want) > 0) || ok && item != want[0] || q.Len() != len(want) {
+			t.Fatalf("Peek() = %d, %v with Len() %d, want %d items", item, ok, q.Len(), len(want))
+		}
+		if got := slices.Collect(q.All()); !slices.Equal(got, want) {
+			t.Fatalf("All() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestShrinks(t *testing.T) {
+	q := MakeQueue[*int]()
+	for i := 0; i < 1000; i++ {
+		q.Enqueue(new(int))
+	}
+	for i := 0; i < 990; i++ {
+		q.Dequeue()
+		if len(q.items) > minCapacity && q.length < len(q.items)/4 {
+			t.Fatalf("%d items in a ring of %d", q.length, len(q.items))
+		}
+	}
+	for i, item := range q.items {
+		if inside := (i-q.head+len(q.items))%len(q.items) < q.length; inside != (item != nil) {
+			t.Fatalf("slot %d = %v with the head at %d and %d items", i, item, q.head, q.length)
+		}
+	}
+	for q.Len() > 0 {
+		q.Dequeue()
+	}
+	if len(q.items) != minCapacity {
+		t.Fatalf("an emptied queue keeps a ring of %d", len(q.items))
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Fatalf("Dequeue() on an empty queue = true")
+	}
+}
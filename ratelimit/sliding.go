@@ -0,0 +1,111 @@
+// Package ratelimit contains per-key rate limiters that are safe for concurrent use.
+// Idle keys are dropped on the fly, so keying by user or IP does not grow
+// memory without bounds.
+package ratelimit
+
+import (
+	"sync/atomic"
+	"time"
+
+	"hashmaps/chainedmap"
+)
+
+type windowCounter struct {
+	start    int64 // beginning of the current window, in nanoseconds
+	current  int
+	previous int
+}
+
+// RateLimiter allows at most limit events per key within any window-long period.
+// It uses the sliding window counter approximation: the count of the previous
+// fixed window is weighted by how much of it still overlaps the sliding window.
+// This needs two counters per key instead of a timestamp per event.
+// The counters live in a chainedmap.ShardedMap, keys in different shards
+// don't wait for each other.
+type RateLimiter[K comparable] struct {
+	counters  *chainedmap.ShardedMap[K, windowCounter]
+	limit     int
+	window    time.Duration
+	lastSweep atomic.Int64
+	now       func() time.Time
+}
+
+func MakeRateLimiter[K comparable](limit int, window time.Duration) *RateLimiter[K] {
+	return MakeRateLimiterWithClock[K](limit, window, time.Now)
+}
+
+func MakeRateLimiterWithClock[K comparable](limit int, window time.Duration, now func() time.Time) *RateLimiter[K] {
+	if window <= 0 {
+		window = time.Second
+	}
+	l := &RateLimiter[K]{
+		counters: chainedmap.MakeShardedMap[K, windowCounter](),
+		limit:    limit,
+		window:   window,
+		now:      now,
+	}
+	l.lastSweep.Store(now().UnixNano())
+	return l
+}
+
+func (l *RateLimiter[K]) Allow(key K) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN reports whether n events may happen now for key and records them
+// if so. The counter of key is created on its first call.
+func (l *RateLimiter[K]) AllowN(key K, n int) bool {
+	now := l.now().UnixNano()
+	l.sweep(now)
+	create := func() windowCounter { return windowCounter{start: l.windowStart(now)} }
+	return l.counters.Update(key, create, func(counter *windowCounter) bool {
+		l.roll(counter, now)
+		if l.estimate(counter, now)+float64(n) > float64(l.limit) {
+			return false
+		}
+		counter.current += n
+		return true
+	})
+}
+
+// Len returns the number of keys currently tracked
+func (l *RateLimiter[K]) Len() int {
+	return l.counters.Len()
+}
+
+func (l *RateLimiter[K]) windowStart(now int64) int64 {
+	return now - now%int64(l.window)
+}
+
+func (l *RateLimiter[K]) roll(counter *windowCounter, now int64) {
+	start := l.windowStart(now)
+	switch {
+	case start == counter.start:
+		return
+	case start-int64(l.window) == counter.start:
+		counter.previous = counter.current
+	default:
+		counter.previous = 0
+	}
+	counter.current = 0
+	counter.start = start
+}
+
+func (l *RateLimiter[K]) estimate(counter *windowCounter, now int64) float64 {
+	overlap := 1 - float64(now-counter.start)/float64(l.window)
+	return float64(counter.previous)*overlap + float64(counter.current)
+}
+
+// sweep runs at most once per window and forgets keys without events
+// in the last two windows, they would start from zero anyway. Of the
+// callers that find it due, the one that moves lastSweep does it.
+func (l *RateLimiter[K]) sweep(now int64) {
+	last := l.lastSweep.Load()
+	if now-last < int64(l.window) || !l.lastSweep.CompareAndSwap(last, now) {
+		return
+	}
+	idle := l.windowStart(now) - int64(l.window)
+	l.counters.DeleteFunc(func(_ K, counter windowCounter) bool {
+		return counter.start < idle
+	})
+}
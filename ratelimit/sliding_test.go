@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock for the WithClock constructors that only moves
+// when told to, safe for concurrent use
+type fakeClock struct {
+	now atomic.Int64
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{}
+	c.now.Store(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	return time.Unix(0, c.now.Load())
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now.Add(int64(d))
+}
+
+func allowed[K comparable](allow func(key K) bool, key K, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if allow(key) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRateLimiterWindowRollover(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeRateLimiterWithClock[string](10, time.Second, clock.Now)
+	if got := allowed(l.Allow, "a", 15); got != 10 {
+		t.Fatalf("allowed %d of 15 in the first window, want 10", got)
+	}
+	if got := allowed(l.Allow, "b", 15); got != 10 {
+		t.Fatalf("key b allowed %d of 15, keys must not share a limit", got)
+	}
+
+	// half way into the next window, half of the previous one still counts
+	clock.Advance(1500 * time.Millisecond)
+	if got := allowed(l.Allow, "a", 15); got != 5 {
+		t.Fatalf("allowed %d half way into the next window, want 5", got)
+	}
+
+	// two windows later nothing of the old events is left
+	clock.Advance(2 * time.Second)
+	if got := allowed(l.Allow, "a", 15); got != 10 {
+		t.Fatalf("allowed %d after two idle windows, want 10", got)
+	}
+	if l.AllowN("c", 11) {
+		t.Fatalf("AllowN(11) with a limit of 10 = true")
+	}
+}
+
+func TestRateLimiterForgetsIdleKeys(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeRateLimiterWithClock[int](10, time.Second, clock.Now)
+	for key := 0; key < 100; key++ {
+		l.Allow(key)
+	}
+	if l.Len() != 100 {
+		t.Fatalf("Len() = %d, want 100", l.Len())
+	}
+	clock.Advance(3 * time.Second)
+	l.Allow(-1)
+	if l.Len() != 1 {
+		t.Fatalf("Len() = %d after two idle windows, want only the new key", l.Len())
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeRateLimiterWithClock[int](100, time.Second, clock.Now)
+	var counts [4]atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if key := i % len(counts); l.Allow(key) {
+					counts[key].Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for key := range counts {
+		if got := counts[key].Load(); got != 100 {
+			t.Fatalf("key %d allowed %d of 400 concurrent events, want exactly 100", key, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"sync/atomic"
+	"time"
+
+	"hashmaps/chainedmap"
+)
+
+// Limit is a token bucket configuration: Rate tokens are added per second,
+// up to Burst tokens can be saved up
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   int64 // when tokens was last brought up to date, in nanoseconds
+	limit  Limit
+}
+
+// TokenBucketLimiter keeps a token bucket per key. Buckets are created on the
+// first request of a key, and dropped once they refill completely, as a full
+// bucket is indistinguishable from a new one. Buckets and overrides live in
+// chainedmap.ShardedMaps, keys in different shards don't wait for each other.
+type TokenBucketLimiter[K comparable] struct {
+	buckets      *chainedmap.ShardedMap[K, tokenBucket]
+	overrides    *chainedmap.ShardedMap[K, Limit]
+	defaultLimit Limit
+	lastSweep    atomic.Int64
+	now          func() time.Time
+}
+
+func MakeTokenBucketLimiter[K comparable](defaultLimit Limit) *TokenBucketLimiter[K] {
+	return MakeTokenBucketLimiterWithClock[K](defaultLimit, time.Now)
+}
+
+func MakeTokenBucketLimiterWithClock[K comparable](defaultLimit Limit, now func() time.Time) *TokenBucketLimiter[K] {
+	l := &TokenBucketLimiter[K]{
+		buckets:      chainedmap.MakeShardedMap[K, tokenBucket](),
+		overrides:    chainedmap.MakeShardedMap[K, Limit](),
+		defaultLimit: defaultLimit,
+		now:          now,
+	}
+	l.lastSweep.Store(now().UnixNano())
+	return l
+}
+
+// SetLimit overrides the default limit for a single key,
+// tokens already saved up are kept but capped at the new burst.
+// The override is stored first, so a bucket created meanwhile gets it too.
+func (l *TokenBucketLimiter[K]) SetLimit(key K, limit Limit) {
+	l.overrides.Set(key, limit)
+	l.buckets.Update(key, nil, func(bucket *tokenBucket) bool {
+		l.refill(bucket, l.now().UnixNano())
+		bucket.limit = limit
+		if bucket.tokens > float64(limit.Burst) {
+			bucket.tokens = float64(limit.Burst)
+		}
+		return true
+	})
+}
+
+// ResetLimit makes key use the default limit again
+func (l *TokenBucketLimiter[K]) ResetLimit(key K) {
+	l.overrides.Delete(key)
+	l.buckets.Delete(key)
+}
+
+func (l *TokenBucketLimiter[K]) Allow(key K) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN takes n tokens from the bucket of key if it has enough of them.
+// The bucket of key is created full on its first call.
+func (l *TokenBucketLimiter[K]) AllowN(key K, n int) bool {
+	now := l.now().UnixNano()
+	l.sweep(now)
+	create := func() tokenBucket {
+		limit := l.limitFor(key)
+		return tokenBucket{tokens: float64(limit.Burst), last: now, limit: limit}
+	}
+	return l.buckets.Update(key, create, func(bucket *tokenBucket) bool {
+		l.refill(bucket, now)
+		if bucket.tokens < float64(n) {
+			return false
+		}
+		bucket.tokens -= float64(n)
+		return true
+	})
+}
+
+// Len returns the number of buckets currently kept
+func (l *TokenBucketLimiter[K]) Len() int {
+	return l.buckets.Len()
+}
+
+func (l *TokenBucketLimiter[K]) limitFor(key K) Limit {
+	if limit, ok := l.overrides.Get(key); ok {
+		return limit
+	}
+	return l.defaultLimit
+}
+
+func (l *TokenBucketLimiter[K]) refill(bucket *tokenBucket, now int64) {
+	elapsed := time.Duration(now - bucket.last).Seconds()
+	bucket.last = now
+	if elapsed <= 0 {
+		return
+	}
+	bucket.tokens += elapsed * bucket.limit.Rate
+	if bucket.tokens > float64(bucket.limit.Burst) {
+		bucket.tokens = float64(bucket.limit.Burst)
+	}
+}
+
+// sweep runs at most once per second and drops buckets that are full again.
+// Of the callers that find it due, the one that moves lastSweep does it.
+func (l *TokenBucketLimiter[K]) sweep(now int64) {
+	last := l.lastSweep.Load()
+	if now-last < int64(time.Second) || !l.lastSweep.CompareAndSwap(last, now) {
+		return
+	}
+	l.buckets.DeleteFunc(func(_ K, bucket tokenBucket) bool {
+		l.refill(&bucket, now) // a copy, the ones that stay refill on their next call
+		return bucket.tokens >= float64(bucket.limit.Burst)
+	})
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurstAndRefill(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeTokenBucketLimiterWithClock[string](Limit{Rate: 2, Burst: 5}, clock.Now)
+	if got := allowed(l.Allow, "a", 10); got != 5 {
+		t.Fatalf("allowed %d of 10 from a new bucket, want the burst of 5", got)
+	}
+	clock.Advance(time.Second)
+	if got := allowed(l.Allow, "a", 10); got != 2 {
+		t.Fatalf("allowed %d after a second at rate 2, want 2", got)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if got := allowed(l.Allow, "a", 10); got != 1 {
+		t.Fatalf("allowed %d after half a second at rate 2, want 1", got)
+	}
+	clock.Advance(time.Hour)
+	if got := allowed(l.Allow, "a", 10); got != 5 {
+		t.Fatalf("allowed %d after an hour, want no more than the burst of 5", got)
+	}
+	if l.AllowN("b", 6) {
+		t.Fatalf("AllowN(6) with a burst of 5 = true")
+	}
+	if !l.AllowN("b", 5) {
+		t.Fatalf("AllowN(5) on a full bucket of 5 = false")
+	}
+}
+
+func TestTokenBucketOverrides(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeTokenBucketLimiterWithClock[string](Limit{Rate: 1, Burst: 10}, clock.Now)
+	l.SetLimit("vip", Limit{Rate: 1, Burst: 20})
+	if got := allowed(l.Allow, "vip", 30); got != 20 {
+		t.Fatalf("allowed %d for an override with burst 20", got)
+	}
+	allowed(l.Allow, "user", 5)
+	l.SetLimit("user", Limit{Rate: 1, Burst: 2}) // the 5 saved tokens are capped at 2
+	if got := allowed(l.Allow, "user", 10); got != 2 {
+		t.Fatalf("allowed %d after lowering the burst to 2", got)
+	}
+	l.ResetLimit("vip")
+	if got := allowed(l.Allow, "vip", 30); got != 10 {
+		t.Fatalf("allowed %d after ResetLimit, want the default burst of 10", got)
+	}
+}
+
+func TestTokenBucketDropsFullBuckets(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeTokenBucketLimiterWithClock[int](Limit{Rate: 1, Burst: 3}, clock.Now)
+	for key := 0; key < 10; key++ {
+		l.AllowN(key, 3)
+	}
+	clock.Advance(time.Second)
+	l.Allow(-1) // 1 token back per key, no bucket is full yet
+	if l.Len() != 11 {
+		t.Fatalf("Len() = %d, want 11", l.Len())
+	}
+	clock.Advance(2 * time.Second)
+	l.Allow(-2)
+	if l.Len() != 1 {
+		t.Fatalf("Len() = %d once the buckets refilled, want only the new key", l.Len())
+	}
+}
+
+func TestTokenBucketConcurrent(t *testing.T) {
+	clock := newFakeClock()
+	l := MakeTokenBucketLimiterWithClock[int](Limit{Rate: 1, Burst: 100}, clock.Now)
+	var counts [4]atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if key := i % len(counts); l.Allow(key) {
+					counts[key].Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for key := range counts {
+		if got := counts[key].Load(); got != 100 {
+			t.Fatalf("key %d allowed %d of 400 concurrent events, want exactly the burst of 100", key, got)
+		}
+	}
+}
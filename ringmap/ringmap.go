@@ -0,0 +1,128 @@
+// Package ringmap provides a map that only remembers entries written during
+// a recent time window, e.g. "everything seen in the last 5 minutes".
+package ringmap
+
+import (
+	"iter"
+	"time"
+)
+
+type slot[K comparable, V any] struct {
+	epoch   int64 // index of the time bucket this slot currently holds
+	entries map[K]V
+}
+
+// RingMap splits the window into a fixed number of equally long time buckets
+// kept in a ring. Every write goes to the bucket of the current time, and once
+// a bucket falls out of the window it is dropped as a whole, so expiry costs
+// O(1) per bucket instead of tracking a deadline per entry.
+// A key lives in the bucket of its latest Set, so the window is counted from there.
+type RingMap[K comparable, V any] struct {
+	slots     []slot[K, V]
+	width     time.Duration
+	lastEpoch int64
+	now       func() time.Time
+}
+
+// MakeRingMap keeps entries for window, expiring them in steps of window/buckets
+func MakeRingMap[K comparable, V any](window time.Duration, buckets int) *RingMap[K, V] {
+	return MakeRingMapWithClock[K, V](window, buckets, time.Now)
+}
+
+func MakeRingMapWithClock[K comparable, V any](window time.Duration, buckets int, now func() time.Time) *RingMap[K, V] {
+	if buckets < 1 {
+		buckets = 1
+	}
+	width := window / time.Duration(buckets)
+	if width <= 0 {
+		width = 1
+	}
+	return &RingMap[K, V]{
+		slots: make([]slot[K, V], buckets),
+		width: width,
+		now:   now,
+	}
+}
+
+func (m *RingMap[K, V]) Set(key K, value V) {
+	current := m.advance()
+	for i := range m.slots {
+		if i != current {
+			delete(m.slots[i].entries, key)
+		}
+	}
+	if m.slots[current].entries == nil {
+		m.slots[current].entries = make(map[K]V)
+	}
+	m.slots[current].entries[key] = value
+}
+
+func (m *RingMap[K, V]) Get(key K) (V, bool) {
+	m.advance()
+	for i := range m.slots {
+		if value, ok := m.slots[i].entries[key]; ok {
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *RingMap[K, V]) Delete(key K) bool {
+	m.advance()
+	for i := range m.slots {
+		if _, ok := m.slots[i].entries[key]; ok {
+			delete(m.slots[i].entries, key)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *RingMap[K, V]) Len() int {
+	m.advance()
+	length := 0
+	for i := range m.slots {
+		length += len(m.slots[i].entries)
+	}
+	return length
+}
+
+// Range visits live entries, most recently written buckets first, until fn returns false
+func (m *RingMap[K, V]) Range(fn func(key K, value V) bool) {
+	current := m.advance()
+	for step := 0; step < len(m.slots); step++ {
+		i := (current - step + len(m.slots)) % len(m.slots)
+		for key, value := range m.slots[i].entries {
+			if !fn(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// All is Range as an iter.Seq2
+func (m *RingMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+// advance drops every bucket that slid out of the window and returns
+// the index of the slot for the current time
+func (m *RingMap[K, V]) advance() int {
+	epoch := m.now().UnixNano() / int64(m.width)
+	n := int64(len(m.slots))
+	current := int(((epoch % n) + n) % n)
+	if epoch == m.lastEpoch {
+		return current
+	}
+	m.lastEpoch = epoch
+	for i := range m.slots {
+		if m.slots[i].epoch <= epoch-n {
+			m.slots[i].entries = nil
+		}
+	}
+	if m.slots[current].epoch != epoch {
+		m.slots[current] = slot[K, V]{epoch: epoch}
+	}
+	return current
+}
@@ -0,0 +1,102 @@
+package ringmap
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type modelEntry struct {
+	value int
+	epoch int64 // the bucket of its latest Set
+}
+
+// An entry must live exactly as long as the bucket of its latest Set is
+// one of the newest buckets, however the clock jumps between calls
+func TestRingMapAgainstModel(t *testing.T) {
+	const buckets, width = 5, 2 * time.Second
+	r := rand.New(rand.NewPCG(1, 2))
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	m := MakeRingMapWithClock[int, int](buckets*width, buckets, clock.Now)
+	model := map[int]modelEntry{}
+	for step := 0; step < 5000; step++ {
+		if r.IntN(4) == 0 {
+			clock.now = clock.now.Add(time.Duration(r.IntN(int(3 * width))))
+		}
+		epoch := clock.now.UnixNano() / int64(width)
+		for key, e := range model {
+			if e.epoch <= epoch-buckets {
+				delete(model, key)
+			}
+		}
+		key := r.IntN(40)
+		switch r.IntN(4) {
+		case 0, 1:
+			m.Set(key, step)
+			model[key] = modelEntry{value: step, epoch: epoch}
+		case 2:
+			want, found := model[key]
+			if value, ok := m.Get(key); ok != found || ok && value != want.value {
+				t.Fatalf("step %d: Get(%d) = %d, %v, want %+v", step, key, value, ok, want)
+			}
+		case 3:
+			_, found := model[key]
+			if m.Delete(key) != found {
+				t.Fatalf("step %d: Delete(%d) = %v", step, key, !found)
+			}
+			delete(model, key)
+		}
+		if m.Len() != len(model) {
+			t.Fatalf("step %d: Len() = %d, want %d", step, m.Len(), len(model))
+		}
+		visited, last := 0, epoch
+		for key, value := range m.All() {
+			e, ok := model[key]
+			if !ok || e.value != value || e.epoch > last {
+				t.Fatalf("step %d: Range passed %d=%d, want %+v, %v, newest buckets first", step, key, value, e, ok)
+			}
+			last = e.epoch
+			visited++
+		}
+		if visited != len(model) {
+			t.Fatalf("step %d: Range visited %d of %d entries", step, visited, len(model))
+		}
+	}
+}
+
+func TestRingMapWindowEdges(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	m := MakeRingMapWithClock[string, int](3*time.Second, 3, clock.Now)
+	m.Set("a", 1)
+	clock.now = clock.now.Add(2999 * time.Millisecond)
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("a expired while its bucket was still in the window")
+	}
+	m.Set("b", 2)
+	m.Set("a", 3) // moves a to the newest bucket
+	clock.now = clock.now.Add(time.Millisecond)
+	if value, ok := m.Get("a"); !ok || value != 3 {
+		t.Fatalf("Get(a) = %d, %v, want the Set that moved it", value, ok)
+	}
+	clock.now = clock.now.Add(time.Hour) // every bucket at once
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d an hour later", m.Len())
+	}
+	clock.now = time.Unix(-10, 0) // before the epoch, buckets index from the end
+	m.Set("c", 4)
+	if value, ok := m.Get("c"); !ok || value != 4 {
+		t.Fatalf("Get(c) = %d, %v at a negative time", value, ok)
+	}
+	if one := MakeRingMapWithClock[string, int](0, 0, clock.Now); len(one.slots) != 1 || one.width != 1 {
+		t.Fatalf("a zero window has %d buckets of %v, want one of 1ns", len(one.slots), one.width)
+	}
+}
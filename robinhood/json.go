@@ -0,0 +1,19 @@
+package robinhood
+
+import "hashmaps/internal/mapjson"
+
+// MarshalJSON writes the map as internal/mapjson describes, the pairs of
+// non-string keys come in slot order, keys of one home slot next to each other
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mapjson.Marshal(m.Range, m.Len())
+}
+
+// UnmarshalJSON adds the decoded entries through TrySet, each one displacing
+// richer entries as a Set would. A zero HashMap, e.g. one that json.Unmarshal
+// allocated, gets MakeHashMap's load factor first.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	if m.capacity == 0 {
+		*m = *MakeHashMap[K, V]()
+	}
+	return mapjson.Unmarshal(data, m.TrySet)
+}
@@ -0,0 +1,33 @@
+package robinhood
+
+// ProbeStats describes how far entries sit from their home slot,
+// i.e. how many extra slots a lookup of each key has to look at
+type ProbeStats struct {
+	Max      int
+	Mean     float64
+	Variance float64
+}
+
+// ProbeStats is for comparing the map with other open addressing schemes
+func (m *HashMap[K, V]) ProbeStats() ProbeStats {
+	var stats ProbeStats
+	if m.length == 0 {
+		return stats
+	}
+	sum, sumOfSquares := 0.0, 0.0
+	for i := range m.slots {
+		if m.slots[i].psl == 0 {
+			continue
+		}
+		distance := m.slots[i].psl - 1
+		if distance > stats.Max {
+			stats.Max = distance
+		}
+		sum += float64(distance)
+		sumOfSquares += float64(distance) * float64(distance)
+	}
+	n := float64(m.length)
+	stats.Mean = sum / n
+	stats.Variance = sumOfSquares/n - stats.Mean*stats.Mean
+	return stats
+}
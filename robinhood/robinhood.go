@@ -0,0 +1,288 @@
+// Package robinhood is an open addressing hashmap with Robin Hood hashing:
+// on insert an entry that is further from its home slot than the one it
+// probes past takes that slot, and the displaced entry keeps probing.
+// Probe lengths end up short and very even, lookups can stop as soon as
+// they'd be poorer than the slot they look at, and deletes shift the
+// following entries back instead of leaving tombstones.
+package robinhood
+
+import (
+	"errors"
+	"iter"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+type KVPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type slot[K comparable, V any] struct {
+	psl  int // probe sequence length: distance from the home slot + 1, 0 means empty
+	pair KVPair[K, V]
+}
+
+type HashMap[K comparable, V any] struct {
+	capacity int64 // len(slots)
+	slots    []slot[K, V]
+
+	length        int          // occupied slots
+	maxLoadFactor float64      // the slice doubles once length/capacity would go beyond this
+	seed          hashing.Seed // see internal/hashing
+
+	norm keynorm.Normalizer[K] // float keys and the NaNPolicy, see internal/keynorm
+}
+
+// Robin Hood keeps probes short at higher loads than plain linear probing
+const (
+	defaultCapacity      = 8
+	defaultMaxLoadFactor = 0.85
+)
+
+var ErrInvalidLoadFactor = errors.New("max load factor must be between 0 and 1")
+
+func MakeHashMap[K comparable, V any]() *HashMap[K, V] {
+	m, _ := makeHashMap[K, V](CanonicalizeNaN, defaultMaxLoadFactor) // the defaults are always valid
+	return m
+}
+
+// NaNPolicy is what MakeHashMapWithNaNPolicy takes, it is internal/keynorm's,
+// so the other map packages take the same values. A NaN key rejected under
+// RejectNaN is turned away before it can displace anything.
+type NaNPolicy = keynorm.NaNPolicy
+
+const (
+	CanonicalizeNaN = keynorm.CanonicalizeNaN
+	RejectNaN       = keynorm.RejectNaN
+)
+
+var (
+	ErrNaNKey           = keynorm.ErrNaNKey
+	ErrInvalidNaNPolicy = keynorm.ErrInvalidNaNPolicy
+)
+
+func MakeHashMapWithNaNPolicy[K comparable, V any](nanPolicy NaNPolicy) (*HashMap[K, V], error) {
+	return makeHashMap[K, V](nanPolicy, defaultMaxLoadFactor)
+}
+
+// MakeHashMapWithLoadFactor sets how full the slice may get before it doubles,
+// it must stay below 1
+func MakeHashMapWithLoadFactor[K comparable, V any](maxLoadFactor float64) (*HashMap[K, V], error) {
+	return makeHashMap[K, V](CanonicalizeNaN, maxLoadFactor)
+}
+
+func makeHashMap[K comparable, V any](nanPolicy NaNPolicy, maxLoadFactor float64) (*HashMap[K, V], error) {
+	norm, err := keynorm.MakeNormalizer[K](nanPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if !(maxLoadFactor > 0 && maxLoadFactor < 1) {
+		return nil, ErrInvalidLoadFactor
+	}
+	return &HashMap[K, V]{
+		capacity:      defaultCapacity,
+		slots:         make([]slot[K, V], defaultCapacity),
+		maxLoadFactor: maxLoadFactor,
+		seed:          hashing.MakeSeed[K](),
+		norm:          norm,
+	}, nil
+}
+
+var ErrKeyEncoding = hashing.ErrKeyEncoding
+
+func (m *HashMap[K, V]) tryHash(key K) (int, error) {
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return 0, err
+	}
+	return int(hashedKey % uint64(m.capacity)), nil
+}
+
+// Get, Set, Delete and Remove panic when the key can't be hashed, e.g. a gob encoding failure.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *HashMap[K, V]) Get(key K) *V {
+	value, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (m *HashMap[K, V]) TryGet(key K) (*V, error) {
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return nil, nil
+	}
+	index, err := m.find(key)
+	if err != nil || index < 0 {
+		return nil, err
+	}
+	return &m.slots[index].pair.Value, nil
+}
+
+// Set also panics when the key is rejected by the NaNPolicy
+func (m *HashMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *HashMap[K, V]) TrySet(key K, value V) error {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		return err
+	}
+	index, err := m.find(key)
+	if err != nil {
+		return err
+	}
+	if index >= 0 {
+		m.slots[index].pair.Value = value
+		return nil
+	}
+	if float64(m.length+1) > m.maxLoadFactor*float64(m.capacity) {
+		m.resize(m.capacity * 2)
+	}
+	m.insert(KVPair[K, V]{Key: key, Value: value})
+	m.length++
+	return nil
+}
+
+// insert places a pair whose key isn't in the map yet. Whenever the probing
+// entry is further from home than the resident one they swap places, and
+// the resident continues probing instead.
+func (m *HashMap[K, V]) insert(pair KVPair[K, V]) {
+	// keys in the table were hashed before, new keys were hashed by find
+	index, _ := m.tryHash(pair.Key)
+	probing := slot[K, V]{psl: 1, pair: pair}
+	for {
+		if m.slots[index].psl == 0 {
+			m.slots[index] = probing
+			return
+		}
+		if m.slots[index].psl < probing.psl {
+			probing, m.slots[index] = m.slots[index], probing
+		}
+		probing.psl++
+		index = m.next(index)
+	}
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *HashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *HashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+// Pop is Delete, the entries after the popped one shift back a slot
+// instead of leaving a tombstone
+func (m *HashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+// TryDelete shifts the entries after the deleted one a slot back, up to
+// the first one that is empty or already in its home slot, so no
+// tombstones are needed
+func (m *HashMap[K, V]) TryDelete(key K) (V, bool, error) {
+	var value V
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return value, false, nil
+	}
+	index, err := m.find(key)
+	if err != nil || index < 0 {
+		return value, false, err
+	}
+	value = m.slots[index].pair.Value
+	for next := m.next(index); m.slots[next].psl > 1; next = m.next(next) {
+		m.slots[index] = m.slots[next]
+		m.slots[index].psl--
+		index = next
+	}
+	m.slots[index] = slot[K, V]{}
+	m.length--
+	return value, true, nil
+}
+
+// Len returns the number of entries, in O(1)
+func (m *HashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, like sync.Map.Range.
+// The map must not be modified by fn.
+func (m *HashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for i := range m.slots {
+		if m.slots[i].psl != 0 && !fn(m.slots[i].pair.Key, m.slots[i].pair.Value) {
+			return
+		}
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// find returns the slot holding key, or -1. The probe stops early at the
+// first entry closer to its home than key would be at that point, since
+// insert would have put key in front of it.
+func (m *HashMap[K, V]) find(key K) (int, error) {
+	index, err := m.tryHash(key)
+	if err != nil {
+		return -1, err
+	}
+	for psl := 1; m.slots[index].psl >= psl; psl++ {
+		if m.norm.Equal(m.slots[index].pair.Key, key) {
+			return index, nil
+		}
+		index = m.next(index)
+	}
+	return -1, nil
+}
+
+func (m *HashMap[K, V]) next(index int) int {
+	index++
+	if index == len(m.slots) {
+		return 0
+	}
+	return index
+}
+
+func (m *HashMap[K, V]) resize(newCapacity int64) {
+	oldSlots := m.slots
+	m.capacity = newCapacity
+	m.slots = make([]slot[K, V], newCapacity)
+	for i := range oldSlots {
+		if oldSlots[i].psl != 0 {
+			m.insert(oldSlots[i].pair)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package robinhood
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i*i)
+	}
+	for i := 0; i < 1000; i += 2 {
+		if value, ok := m.Delete(i); !ok || value != i*i {
+			t.Fatalf("Delete(%d) = %d, %v, want %d, true", i, value, ok, i*i)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		value := m.Get(i)
+		if deleted := i%2 == 0; deleted != (value == nil) || !deleted && *value != i*i {
+			t.Fatalf("Get(%d) = %v after deleting the even keys", i, value)
+		}
+	}
+	if m.Len() != 500 {
+		t.Fatalf("Len() = %d, want 500", m.Len())
+	}
+}
+
+func TestRejectNaNReturnsError(t *testing.T) {
+	m, err := MakeHashMapWithNaNPolicy[float64, int](RejectNaN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.TrySet(math.NaN(), 1); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("TrySet(NaN) = %v, want ErrNaNKey", err)
+	}
+	if value, err := m.TryGet(math.NaN()); value != nil || err != nil {
+		t.Fatalf("TryGet(NaN) = %v, %v, want nil, nil", value, err)
+	}
+	if _, ok, err := m.TryDelete(math.NaN()); ok || err != nil {
+		t.Fatalf("TryDelete(NaN) = %v, %v, want false, nil", ok, err)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d after a rejected key, want 0", m.Len())
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	if _, err := MakeHashMapWithNaNPolicy[float64, int](NaNPolicy(-1)); !errors.Is(err, ErrInvalidNaNPolicy) {
+		t.Fatalf("MakeHashMapWithNaNPolicy(-1) = %v, want ErrInvalidNaNPolicy", err)
+	}
+	for _, maxLoadFactor := range []float64{0, -1, 1, math.NaN(), math.Inf(1)} {
+		if _, err := MakeHashMapWithLoadFactor[int, int](maxLoadFactor); !errors.Is(err, ErrInvalidLoadFactor) {
+			t.Fatalf("MakeHashMapWithLoadFactor(%v) = %v, want ErrInvalidLoadFactor", maxLoadFactor, err)
+		}
+	}
+}
+
+// point{NaN} never equals itself, so every Set adds another key with the
+// same hash. They all probe the same run of slots, but every Set ends.
+type point struct {
+	X float64
+}
+
+func TestKeysThatNeverEqual(t *testing.T) {
+	m := MakeHashMap[point, int]()
+	for i := 0; i < 100; i++ {
+		if err := m.TrySet(point{math.NaN()}, i); err != nil {
+			t.Fatalf("TrySet of NaN key %d = %v", i, err)
+		}
+	}
+	if m.Len() != 100 {
+		t.Fatalf("Len() = %d, want 100", m.Len())
+	}
+	if value := m.Get(point{math.NaN()}); value != nil {
+		t.Fatalf("Get(NaN) = %v, want nil", value)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !tinygo && !lighthash
+
+package simplemap
+
+import (
+	"errors"
+	"testing"
+)
+
+// unencodable has no exported fields, so gob, the fallback hash of the
+// default build, can't encode it
+type unencodable struct {
+	n int
+}
+
+func TestTryMethodsReturnEncodingErrors(t *testing.T) {
+	m := MakeHashMap[unencodable, int]()
+	key := unencodable{1}
+	if err := m.TrySet(key, 1); !errors.Is(err, ErrKeyEncoding) {
+		t.Fatalf("TrySet = %v, want ErrKeyEncoding", err)
+	}
+	if _, err := m.TryGet(key); !errors.Is(err, ErrKeyEncoding) {
+		t.Fatalf("TryGet = %v, want ErrKeyEncoding", err)
+	}
+	if _, _, err := m.TryDelete(key); !errors.Is(err, ErrKeyEncoding) {
+		t.Fatalf("TryDelete = %v, want ErrKeyEncoding", err)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d after failed TrySet, want 0", m.Len())
+	}
+}
+
+func TestGetPanicsOnEncodingError(t *testing.T) {
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrKeyEncoding) {
+			t.Fatalf("Get panicked with %v, want ErrKeyEncoding", err)
+		}
+	}()
+	MakeHashMap[unencodable, int]().Get(unencodable{1})
+}
@@ -0,0 +1,19 @@
+package simplemap
+
+import "hashmaps/internal/mapjson"
+
+// MarshalJSON writes the map as internal/mapjson describes, the pairs of
+// non-string keys come in table order
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mapjson.Marshal(m.Range, m.Len())
+}
+
+// UnmarshalJSON adds the decoded entries through TrySet, so the table
+// doubles on every collision just like when they are Set one by one.
+// A zero HashMap, e.g. one that json.Unmarshal allocated, is made ready first.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	if m.capacity == 0 {
+		*m = *MakeHashMap[K, V]()
+	}
+	return mapjson.Unmarshal(data, m.TrySet)
+}
@@ -0,0 +1,28 @@
+package simplemap
+
+import "iter"
+
+// All, Keys and Values are the range-over-func forms of Range:
+//
+//	for key, value := range m.All() { ... }
+//
+// The map must not be modified during the loop.
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
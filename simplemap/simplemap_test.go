@@ -0,0 +1,119 @@
+package simplemap
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGetFindsOnlyItsKey(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	m.Set(30, 2)
+	for key := 0; key < 256; key++ {
+		value := m.Get(key)
+		switch {
+		case key == 30 && (value == nil || *value != 2):
+			t.Fatalf("Get(30) = %v, want &2", value)
+		case key != 30 && value != nil:
+			t.Fatalf("Get(%d) = &%d, want nil, only 30 is in the map", key, *value)
+		}
+	}
+}
+
+func TestSetGetDelete(t *testing.T) {
+	m := MakeHashMap[string, int]()
+	words := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	for i, word := range words {
+		m.Set(word, i)
+	}
+	m.Set("a", 100)
+	if m.Len() != len(words) {
+		t.Fatalf("Len() = %d, want %d", m.Len(), len(words))
+	}
+	for i, word := range words {
+		want := i
+		if word == "a" {
+			want = 100
+		}
+		if value := m.Get(word); value == nil || *value != want {
+			t.Fatalf("Get(%q) = %v, want &%d", word, value, want)
+		}
+	}
+	if value := m.Get("z"); value != nil {
+		t.Fatalf("Get(%q) = &%d, want nil", "z", *value)
+	}
+	if value, ok := m.Delete("b"); !ok || value != 1 {
+		t.Fatalf("Delete(%q) = %d, %v, want 1, true", "b", value, ok)
+	}
+	if _, ok := m.Delete("b"); ok {
+		t.Fatalf("second Delete(%q) found it", "b")
+	}
+	if m.Get("b") != nil || m.Len() != len(words)-1 {
+		t.Fatalf("after Delete: Get(%q) = %v, Len() = %d", "b", m.Get("b"), m.Len())
+	}
+}
+
+func TestFloatKeys(t *testing.T) {
+	m := MakeHashMap[float64, string]()
+	m.Set(math.NaN(), "nan")
+	m.Set(math.Copysign(0, -1), "zero")
+	if value := m.Get(math.NaN()); value == nil || *value != "nan" {
+		t.Fatalf("Get(NaN) = %v, want &\"nan\"", value)
+	}
+	if value := m.Get(0); value == nil || *value != "zero" {
+		t.Fatalf("Get(0) = %v, want &\"zero\", -0 and +0 are the same key", value)
+	}
+	if m.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", m.Len())
+	}
+}
+
+func TestRejectNaNReturnsError(t *testing.T) {
+	m, err := MakeHashMapWithNaNPolicy[float64, int](RejectNaN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.TrySet(math.NaN(), 1); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("TrySet(NaN) = %v, want ErrNaNKey", err)
+	}
+	if value, err := m.TryGet(math.NaN()); value != nil || err != nil {
+		t.Fatalf("TryGet(NaN) = %v, %v, want nil, nil", value, err)
+	}
+	if _, ok, err := m.TryDelete(math.NaN()); ok || err != nil {
+		t.Fatalf("TryDelete(NaN) = %v, %v, want false, nil", ok, err)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("Len() = %d after a rejected key, want 0", m.Len())
+	}
+}
+
+func TestInvalidNaNPolicy(t *testing.T) {
+	if _, err := MakeHashMapWithNaNPolicy[float64, int](NaNPolicy(-1)); !errors.Is(err, ErrInvalidNaNPolicy) {
+		t.Fatalf("MakeHashMapWithNaNPolicy(-1) = %v, want ErrInvalidNaNPolicy", err)
+	}
+}
+
+// point{NaN} never equals itself, so a second one is another key with the
+// same hash. No table separates them, which has to fail before the table
+// doubles all the way to maxCapacity.
+type point struct {
+	X float64
+}
+
+func TestCapacityOverflowBeforeAllocating(t *testing.T) {
+	m := MakeHashMap[point, int]()
+	m.Set(point{1}, 1)
+	if err := m.TrySet(point{math.NaN()}, 2); err != nil {
+		t.Fatal(err)
+	}
+	capacity := m.capacity
+	if err := m.TrySet(point{math.NaN()}, 3); !errors.Is(err, ErrCapacityOverflow) {
+		t.Fatalf("TrySet of a second NaN key = %v, want ErrCapacityOverflow", err)
+	}
+	if m.capacity != capacity || m.Len() != 2 {
+		t.Fatalf("capacity %d and Len() %d after the failed TrySet, want %d and 2", m.capacity, m.Len(), capacity)
+	}
+	if value := m.Get(point{1}); value == nil || *value != 1 {
+		t.Fatalf("Get(point{1}) = %v after the failed TrySet, want 1", value)
+	}
+}
@@ -0,0 +1,276 @@
+// Package simplemap is the simplest hashmap: one entry per slot, and the
+// table doubles on every collision until the keys no longer collide.
+package simplemap
+
+import (
+	"errors"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+type KVPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// This is the simplest hashmap implementation
+// in case of hash collision it just doubles its size
+// until keyspace won't end up in a collision
+
+type HashMap[K comparable, V any] struct {
+	capacity int64
+	entries  []*KVPair[K, V]
+	length   int          // number of entries, maintained by Set and Remove
+	rehashes int          // tables built by rehash, see Stats
+	seed     hashing.Seed // see internal/hashing
+
+	norm keynorm.Normalizer[K] // float keys and the NaNPolicy, see internal/keynorm
+}
+
+// Get, Set, Delete and Remove panic when the key can't be hashed, e.g. a gob encoding failure,
+// or when the table would have to grow beyond maxCapacity.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *HashMap[K, V]) Get(key K) *V {
+	value, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (m *HashMap[K, V]) TryGet(key K) (*V, error) {
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return nil, nil
+	}
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		return nil, err
+	}
+	entry := m.entries[hashedKey]
+	if entry == nil || !m.norm.Equal(entry.Key, key) { // another key may own the slot
+		return nil, nil
+	}
+	return &entry.Value, nil
+}
+
+// Set also panics when the key is rejected by the NaNPolicy
+func (m *HashMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *HashMap[K, V]) TrySet(key K, value V) error {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		return err
+	}
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		return err
+	}
+	existing := m.entries[hashedKey]
+	updated := existing != nil && m.norm.Equal(existing.Key, key)
+	if err := m.insert(key, value); err != nil {
+		return err
+	}
+	if !updated {
+		m.length++
+	}
+	return nil
+}
+
+// Len returns the number of entries, in O(1)
+func (m *HashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, like sync.Map.Range.
+// The map must not be modified by fn.
+func (m *HashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for _, entry := range m.entries {
+		if entry != nil && !fn(entry.Key, entry.Value) {
+			return
+		}
+	}
+}
+
+// insert is for keys that were hashed successfully before
+func (m *HashMap[K, V]) insert(key K, value V) error {
+	hashedKey := m.hash(key)
+	if m.entries[hashedKey] == nil {
+		kvPairToInsert := KVPair[K, V]{Key: key, Value: value}
+		m.entries[hashedKey] = &kvPairToInsert
+	} else {
+		if m.norm.Equal(m.entries[hashedKey].Key, key) {
+			m.entries[hashedKey].Value = value
+		} else {
+			if err := m.rehash(key, m.entries[hashedKey].Key); err != nil {
+				return err
+			}
+			return m.insert(key, value)
+		}
+	}
+	return nil
+}
+
+// Every collision doubles the table, so keys whose hashes agree on many low bits
+// would keep doubling it until allocation fails. We give up at maxCapacity instead.
+const maxCapacity = 1 << 32
+
+var ErrCapacityOverflow = errors.New("table would have to grow beyond maxCapacity to avoid a collision")
+
+// Rehash map so that newKey won't collide with oldKey, the key in its slot.
+// The capacity that takes follows from their hashes, so keys that would
+// collide up to maxCapacity fail before a bigger table is allocated.
+func (m *HashMap[K, V]) rehash(newKey, oldKey K) error {
+	// both keys were hashed successfully before
+	newHash, _ := hashing.Hash(m.seed, newKey)
+	oldHash, _ := hashing.Hash(m.seed, oldKey)
+	newCapacity := m.capacity
+	for newHash%uint64(newCapacity) == oldHash%uint64(newCapacity) {
+		newCapacity = newCapacity * 2
+		if newCapacity > maxCapacity {
+			return ErrCapacityOverflow
+		}
+	}
+	m.capacity = newCapacity
+	m.rehashes++
+	oldEntries := m.entries
+
+	m.entries = make([]*KVPair[K, V], m.capacity)
+	for _, oldEntry := range oldEntries {
+		if oldEntry != nil {
+			// entries that didn't collide before can't collide in a table a power of two times the size
+			if err := m.insert(oldEntry.Key, oldEntry.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *HashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *HashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+// Pop is Delete, the popped slot is simply emptied, no other key lives there
+func (m *HashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+func (m *HashMap[K, V]) TryDelete(key K) (V, bool, error) {
+	var value V
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return value, false, nil
+	}
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		return value, false, err
+	}
+	entry := m.entries[hashedKey]
+	if entry == nil || !m.norm.Equal(entry.Key, key) {
+		return value, false, nil
+	}
+	m.entries[hashedKey] = nil
+	m.length--
+	return entry.Value, true, nil
+}
+
+// Clear removes all entries but keeps the table, so refilling the map
+// to a similar size doesn't have to grow it again
+func (m *HashMap[K, V]) Clear() {
+	for i := range m.entries {
+		m.entries[i] = nil
+	}
+	m.length = 0
+}
+
+// ClearAndShrink removes all entries and goes back to the default capacity
+func (m *HashMap[K, V]) ClearAndShrink() {
+	m.capacity = defaultCapacity
+	m.entries = make([]*KVPair[K, V], defaultCapacity)
+	m.length = 0
+}
+
+// Clone returns an independent copy, changes to one map never show up in the other.
+// Keys and values themselves are copied by assignment.
+func (m *HashMap[K, V]) Clone() *HashMap[K, V] {
+	clone := *m
+	clone.entries = make([]*KVPair[K, V], len(m.entries))
+	for i, entry := range m.entries {
+		if entry != nil {
+			copied := *entry
+			clone.entries[i] = &copied
+		}
+	}
+	return &clone
+}
+
+// defaultCapacity is what new maps start with, and what ClearAndShrink goes back to
+const defaultCapacity = 4
+
+func MakeHashMap[K comparable, V any]() *HashMap[K, V] {
+	m, _ := MakeHashMapWithNaNPolicy[K, V](CanonicalizeNaN) // the default policy is always valid
+	return m
+}
+
+// NaNPolicy is what MakeHashMapWithNaNPolicy takes, it is internal/keynorm's,
+// so the other map packages take the same values. Under CanonicalizeNaN all
+// NaNs hash alike and take a single slot, they can't force a doubling.
+type NaNPolicy = keynorm.NaNPolicy
+
+const (
+	CanonicalizeNaN = keynorm.CanonicalizeNaN
+	RejectNaN       = keynorm.RejectNaN
+)
+
+var (
+	ErrNaNKey           = keynorm.ErrNaNKey
+	ErrInvalidNaNPolicy = keynorm.ErrInvalidNaNPolicy
+)
+
+func MakeHashMapWithNaNPolicy[K comparable, V any](nanPolicy NaNPolicy) (*HashMap[K, V], error) {
+	norm, err := keynorm.MakeNormalizer[K](nanPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return &HashMap[K, V]{
+		capacity: defaultCapacity,
+		entries:  make([]*KVPair[K, V], defaultCapacity),
+		seed:     hashing.MakeSeed[K](),
+		norm:     norm,
+	}, nil
+}
+
+var ErrKeyEncoding = hashing.ErrKeyEncoding
+
+func (m *HashMap[K, V]) tryHash(key K) (int, error) {
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return 0, err
+	}
+	return int(hashedKey % uint64(m.capacity)), nil
+}
+
+func (m *HashMap[K, V]) hash(key K) int {
+	hashedKey, err := m.tryHash(key)
+	if err != nil {
+		panic(err)
+	}
+	return hashedKey
+}
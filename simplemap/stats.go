@@ -0,0 +1,28 @@
+package simplemap
+
+// Stats is a picture of the table, laid out like chainedmap.Stats so the two
+// can be compared. A slot holds at most one entry, so chains are never
+// longer than 1 and what the map pays for that shows in Capacity and Rehashes.
+type Stats struct {
+	Len          int
+	Capacity     int
+	LoadFactor   float64 // Len / Capacity
+	UsedBuckets  int     // slots holding an entry, always Len
+	LongestChain int
+	MeanChain    float64
+	Rehashes     int // times the table was doubled since the map was made
+}
+
+func (m *HashMap[K, V]) Stats() Stats {
+	stats := Stats{
+		Len:         m.length,
+		Capacity:    int(m.capacity),
+		LoadFactor:  float64(m.length) / float64(m.capacity),
+		UsedBuckets: m.length,
+		Rehashes:    m.rehashes,
+	}
+	if m.length > 0 {
+		stats.LongestChain, stats.MeanChain = 1, 1
+	}
+	return stats
+}
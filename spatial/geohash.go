@@ -0,0 +1,209 @@
+package spatial
+
+import (
+	"iter"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	geohashAlphabet  = "0123456789bcdefghjkmnpqrstuvwxyz"
+	geohashPrecision = 12 // ~3.7cm x 1.9cm cells, stored for every item
+	earthRadius      = 6371008.8
+	metersPerDegree  = math.Pi * earthRadius / 180
+)
+
+// EncodeGeohash returns the geohash of the given coordinates with precision characters
+func EncodeGeohash(lat, lon float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	var hash strings.Builder
+	bit, char, even := 0, 0, true
+	for hash.Len() < precision {
+		r, value := &latRange, lat
+		if even {
+			r, value = &lonRange, lon
+		}
+		mid := (r[0] + r[1]) / 2
+		char <<= 1
+		if value >= mid {
+			char |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+		even = !even
+		bit++
+		if bit == 5 {
+			hash.WriteByte(geohashAlphabet[char])
+			bit, char = 0, 0
+		}
+	}
+	return hash.String()
+}
+
+// geohashCellSize returns the height and width in degrees of a cell with precision characters
+func geohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lonBits))
+}
+
+// GeohashNeighbors returns the geohash of the cell containing the coordinates
+// followed by the up to 8 distinct cells around it. Longitude wraps around,
+// cells beyond the poles don't exist.
+func GeohashNeighbors(lat, lon float64, precision int) []string {
+	height, width := geohashCellSize(precision)
+	// snap to the center of the cell so the offsets land in the middle of the neighbours
+	centerLat := (math.Floor((lat+90)/height)+0.5)*height - 90
+	centerLon := (math.Floor((lon+180)/width)+0.5)*width - 180
+	seen := make(map[string]bool, 9)
+	var hashes []string
+	for _, dLat := range []float64{0, -1, 1} {
+		for _, dLon := range []float64{0, -1, 1} {
+			neighbourLat := centerLat + dLat*height
+			if neighbourLat < -90 || neighbourLat > 90 {
+				continue
+			}
+			neighbourLon := math.Mod(centerLon+dLon*width+540, 360) - 180
+			hash := EncodeGeohash(neighbourLat, neighbourLon, precision)
+			if !seen[hash] {
+				seen[hash] = true
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	return hashes
+}
+
+// DistanceMeters is the great-circle distance between two coordinates
+func DistanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLon := (lon2 - lon1) * toRadians
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// LatLon is a coordinate pair in degrees
+type LatLon struct {
+	Lat, Lon float64
+}
+
+type geoEntry[T comparable] struct {
+	hash     string
+	item     T
+	lat, lon float64
+}
+
+// GeoIndex keeps items sorted by geohash, so all items in a geohash cell
+// form a contiguous run found by binary search on the cell prefix.
+// Proximity queries pick the finest cell at least as big as the radius
+// and scan it together with its 8 neighbours.
+// Inserts and removals shift the sorted slice, which favours read heavy use.
+type GeoIndex[T comparable] struct {
+	entries []geoEntry[T] // sorted by hash
+	hashes  map[T]string
+}
+
+func MakeGeoIndex[T comparable]() *GeoIndex[T] {
+	return &GeoIndex[T]{hashes: make(map[T]string)}
+}
+
+func (g *GeoIndex[T]) Len() int {
+	return len(g.entries)
+}
+
+// Insert places item at the coordinates, an item inserted again is moved
+func (g *GeoIndex[T]) Insert(item T, lat, lon float64) {
+	g.Remove(item)
+	hash := EncodeGeohash(lat, lon, geohashPrecision)
+	i := sort.Search(len(g.entries), func(i int) bool { return g.entries[i].hash > hash })
+	var zero geoEntry[T]
+	g.entries = append(g.entries, zero)
+	copy(g.entries[i+1:], g.entries[i:])
+	g.entries[i] = geoEntry[T]{hash: hash, item: item, lat: lat, lon: lon}
+	g.hashes[item] = hash
+}
+
+func (g *GeoIndex[T]) Remove(item T) bool {
+	hash, ok := g.hashes[item]
+	if !ok {
+		return false
+	}
+	delete(g.hashes, item)
+	for i := sort.Search(len(g.entries), func(i int) bool { return g.entries[i].hash >= hash }); i < len(g.entries); i++ {
+		if g.entries[i].item == item {
+			g.entries = append(g.entries[:i], g.entries[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// RangePrefix visits items whose geohash starts with prefix, until fn returns false
+func (g *GeoIndex[T]) RangePrefix(prefix string, fn func(item T, lat, lon float64) bool) {
+	start := sort.Search(len(g.entries), func(i int) bool { return g.entries[i].hash >= prefix })
+	for i := start; i < len(g.entries) && strings.HasPrefix(g.entries[i].hash, prefix); i++ {
+		if !fn(g.entries[i].item, g.entries[i].lat, g.entries[i].lon) {
+			return
+		}
+	}
+}
+
+// All yields every item with its coordinates, in geohash order.
+// The index must not be modified during the loop.
+func (g *GeoIndex[T]) All() iter.Seq2[T, LatLon] {
+	return func(yield func(T, LatLon) bool) {
+		for _, entry := range g.entries {
+			if !yield(entry.item, LatLon{entry.lat, entry.lon}) {
+				return
+			}
+		}
+	}
+}
+
+// Nearby visits items within radiusMeters of the coordinates, in no particular
+// order, until fn returns false
+func (g *GeoIndex[T]) Nearby(lat, lon, radiusMeters float64, fn func(item T, distanceMeters float64) bool) {
+	visit := func(item T, itemLat, itemLon float64) bool {
+		distance := DistanceMeters(lat, lon, itemLat, itemLon)
+		if distance > radiusMeters {
+			return true
+		}
+		return fn(item, distance)
+	}
+	precision := g.searchPrecision(lat, radiusMeters)
+	if precision == 0 {
+		g.RangePrefix("", visit)
+		return
+	}
+	for _, prefix := range GeohashNeighbors(lat, lon, precision) {
+		stopped := false
+		g.RangePrefix(prefix, func(item T, itemLat, itemLon float64) bool {
+			stopped = !visit(item, itemLat, itemLon)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// searchPrecision is the longest prefix whose cells are at least radius tall
+// and wide at the given latitude, 0 means scanning everything
+func (g *GeoIndex[T]) searchPrecision(lat, radiusMeters float64) int {
+	// the widest latitude the 3x3 block can reach is where cells are narrowest
+	for precision := geohashPrecision; precision > 0; precision-- {
+		height, width := geohashCellSize(precision)
+		edgeLat := math.Min(90, math.Abs(lat)+1.5*height)
+		if height*metersPerDegree >= radiusMeters &&
+			width*metersPerDegree*math.Cos(edgeLat*math.Pi/180) >= radiusMeters {
+			return precision
+		}
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package spatial
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	for _, tc := range []struct {
+		lat, lon  float64
+		precision int
+		want      string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{42.6, -5.6, 5, "ezs42"},
+		{-90, -180, 4, "0000"},
+		{90, 180, 4, "zzzz"},
+		{0, 0, 1, "s"},
+	} {
+		if got := EncodeGeohash(tc.lat, tc.lon, tc.precision); got != tc.want {
+			t.Fatalf("EncodeGeohash(%v, %v, %d) = %q, want %q", tc.lat, tc.lon, tc.precision, got, tc.want)
+		}
+	}
+}
+
+func TestGeohashNeighbors(t *testing.T) {
+	hashes := GeohashNeighbors(57.64911, 10.40744, 5)
+	if len(hashes) != 9 || hashes[0] != "u4pru" {
+		t.Fatalf("GeohashNeighbors = %v, want the cell u4pru and its 8 neighbours", hashes)
+	}
+	// longitude wraps around: the cells east of 179.99 are at -180
+	hashes = GeohashNeighbors(0, 179.99, 3)
+	if !slices.Contains(hashes, EncodeGeohash(0, -179.99, 3)) {
+		t.Fatalf("GeohashNeighbors at the antimeridian = %v, missing the cell across it", hashes)
+	}
+	// there is nothing north of the pole, only 6 cells are left
+	if hashes = GeohashNeighbors(89.99, 0, 3); len(hashes) != 6 {
+		t.Fatalf("GeohashNeighbors at the pole = %v, want 6 cells", hashes)
+	}
+	for _, hash := range GeohashNeighbors(10, 10, 4) {
+		if len(hash) != 4 || strings.Trim(hash, geohashAlphabet) != "" {
+			t.Fatalf("neighbour %q isn't a geohash of 4 characters", hash)
+		}
+	}
+}
+
+// Nearby must find exactly what measuring the distance to every item
+// finds, at any radius, near the poles and across the antimeridian
+func TestNearbyAgainstScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	g := MakeGeoIndex[int]()
+	positions := map[int]LatLon{}
+	centers := []LatLon{{52.52, 13.40}, {0, 179.999}, {0, -179.999}, {89.9, 40}, {-89.9, -40}, {-33.87, 151.21}}
+	for i := 0; i < 2000; i++ {
+		center := centers[r.IntN(len(centers))]
+		scale := []float64{0.001, 0.1, 5}[r.IntN(3)]
+		p := LatLon{
+			Lat: max(-90, min(90, center.Lat+(r.Float64()-0.5)*scale)),
+			Lon: center.Lon + (r.Float64()-0.5)*scale,
+		}
+		p.Lon = max(-180, min(180, p.Lon))
+		g.Insert(i%1500, p.Lat, p.Lon) // some items are inserted again, which moves them
+		positions[i%1500] = p
+	}
+	if g.Len() != len(positions) {
+		t.Fatalf("Len() = %d, want %d", g.Len(), len(positions))
+	}
+	for i := 0; i < 100; i += 3 {
+		if !g.Remove(i) || g.Remove(i) {
+			t.Fatalf("Remove(%d) twice must be true, then false", i)
+		}
+		delete(positions, i)
+	}
+	previous := ""
+	for item, p := range g.All() {
+		hash := EncodeGeohash(p.Lat, p.Lon, geohashPrecision)
+		if p != positions[item] || hash < previous {
+			t.Fatalf("All() passed %d at %v after the geohash %s, want %v in geohash order", item, p, previous, positions[item])
+		}
+		previous = hash
+	}
+	for _, center := range centers {
+		for _, radius := range []float64{10, 500, 20_000, 1_000_000, 30_000_000} {
+			var want, got []int
+			for item, p := range positions {
+				if DistanceMeters(center.Lat, center.Lon, p.Lat, p.Lon) <= radius {
+					want = append(want, item)
+				}
+			}
+			g.Nearby(center.Lat, center.Lon, radius, func(item int, distance float64) bool {
+				if distance > radius {
+					t.Fatalf("Nearby passed %d at %vm for a radius of %vm", item, distance, radius)
+				}
+				got = append(got, item)
+				return true
+			})
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Fatalf("Nearby(%v, %vm) found %d items, want %d", center, radius, len(got), len(want))
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package spatial contains maps keyed by positions: integer grid cells,
+// points in 2D/3D space and latitude/longitude pairs.
+package spatial
+
+import "iter"
+
+// Point is a cell of an integer grid
+type Point struct {
+	X, Y int
+}
+
+var (
+	orthogonalOffsets = []Point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
+	allOffsets        = []Point{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+)
+
+// GridMap is a sparse 2D grid, only occupied cells take memory.
+// The coordinate pair itself is the key, so there's no string formatting
+// or encoding going on when hashing it.
+type GridMap[V any] struct {
+	cells map[Point]V
+}
+
+func MakeGridMap[V any]() *GridMap[V] {
+	return &GridMap[V]{cells: make(map[Point]V)}
+}
+
+func (g *GridMap[V]) Len() int {
+	return len(g.cells)
+}
+
+func (g *GridMap[V]) Set(x, y int, value V) {
+	g.cells[Point{x, y}] = value
+}
+
+func (g *GridMap[V]) Get(x, y int) (V, bool) {
+	value, ok := g.cells[Point{x, y}]
+	return value, ok
+}
+
+func (g *GridMap[V]) Delete(x, y int) bool {
+	p := Point{x, y}
+	if _, ok := g.cells[p]; !ok {
+		return false
+	}
+	delete(g.cells, p)
+	return true
+}
+
+// Range visits occupied cells in no particular order until fn returns false
+func (g *GridMap[V]) Range(fn func(x, y int, value V) bool) {
+	for p, value := range g.cells {
+		if !fn(p.X, p.Y, value) {
+			return
+		}
+	}
+}
+
+// All visits occupied cells in no particular order
+func (g *GridMap[V]) All() iter.Seq2[Point, V] {
+	return func(yield func(Point, V) bool) {
+		for p, value := range g.cells {
+			if !yield(p, value) {
+				return
+			}
+		}
+	}
+}
+
+// Neighbors visits the occupied cells among the 8 surrounding (x, y),
+// row by row from the top left, until fn returns false
+func (g *GridMap[V]) Neighbors(x, y int, fn func(x, y int, value V) bool) {
+	g.visitOffsets(x, y, allOffsets, fn)
+}
+
+// OrthogonalNeighbors is Neighbors without the diagonal cells
+func (g *GridMap[V]) OrthogonalNeighbors(x, y int, fn func(x, y int, value V) bool) {
+	g.visitOffsets(x, y, orthogonalOffsets, fn)
+}
+
+func (g *GridMap[V]) visitOffsets(x, y int, offsets []Point, fn func(x, y int, value V) bool) {
+	for _, offset := range offsets {
+		nx, ny := x+offset.X, y+offset.Y
+		if value, ok := g.cells[Point{nx, ny}]; ok {
+			if !fn(nx, ny, value) {
+				return
+			}
+		}
+	}
+}
+
+// RangeRect visits occupied cells with minX <= x <= maxX and minY <= y <= maxY.
+// Small rectangles are scanned cell by cell in row order, large ones
+// by filtering all occupied cells, whichever touches fewer cells.
+func (g *GridMap[V]) RangeRect(minX, minY, maxX, maxY int, fn func(x, y int, value V) bool) {
+	if minX > maxX || minY > maxY {
+		return
+	}
+	// one less than the width and height, which overflow for the full int range
+	spanX, spanY, occupied := uint64(maxX-minX), uint64(maxY-minY), uint64(len(g.cells))
+	if spanX < occupied && spanY < occupied && spanX+1 <= occupied/(spanY+1) {
+		for y := minY; ; y++ {
+			for x := minX; ; x++ {
+				if value, ok := g.cells[Point{x, y}]; ok {
+					if !fn(x, y, value) {
+						return
+					}
+				}
+				if x == maxX {
+					break
+				}
+			}
+			if y == maxY {
+				break
+			}
+		}
+		return
+	}
+	for p, value := range g.cells {
+		if p.X >= minX && p.X <= maxX && p.Y >= minY && p.Y <= maxY {
+			if !fn(p.X, p.Y, value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package spatial
+
+import (
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func rangeRect(g *GridMap[int], minX, minY, maxX, maxY int) []Point {
+	var points []Point
+	g.RangeRect(minX, minY, maxX, maxY, func(x, y, _ int) bool {
+		points = append(points, Point{x, y})
+		return true
+	})
+	return points
+}
+
+func sortPoints(points []Point) []Point {
+	slices.SortFunc(points, func(a, b Point) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	return points
+}
+
+// RangeRect must find what filtering every cell finds, for rectangles small
+// enough to walk cell by cell and ones too big for it
+func TestGridRangeRectAgainstScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	g := MakeGridMap[int]()
+	model := map[Point]int{}
+	for i := 0; i < 400; i++ {
+		p := Point{r.IntN(41) - 20, r.IntN(41) - 20}
+		if r.IntN(4) == 0 {
+			_, found := model[p]
+			if g.Delete(p.X, p.Y) != found {
+				t.Fatalf("Delete(%v) = %v", p, !found)
+			}
+			delete(model, p)
+		} else {
+			g.Set(p.X, p.Y, i)
+			model[p] = i
+		}
+	}
+	if g.Len() != len(model) {
+		t.Fatalf("Len() = %d, want %d", g.Len(), len(model))
+	}
+	for p, value := range model {
+		if got, ok := g.Get(p.X, p.Y); !ok || got != value {
+			t.Fatalf("Get(%v) = %d, %v, want %d", p, got, ok, value)
+		}
+	}
+	for i := 0; i < 300; i++ {
+		minX, minY := r.IntN(50)-25, r.IntN(50)-25
+		maxX, maxY := minX+r.IntN(1<<r.IntN(7)), minY+r.IntN(1<<r.IntN(7)) // from single cells to the whole grid
+		var want []Point
+		for p := range model {
+			if p.X >= minX && p.X <= maxX && p.Y >= minY && p.Y <= maxY {
+				want = append(want, p)
+			}
+		}
+		if got := sortPoints(rangeRect(g, minX, minY, maxX, maxY)); !slices.Equal(got, sortPoints(want)) {
+			t.Fatalf("RangeRect(%d, %d, %d, %d) = %v, want %v", minX, minY, maxX, maxY, got, want)
+		}
+	}
+}
+
+func TestGridRangeRectEdges(t *testing.T) {
+	g := MakeGridMap[int]()
+	g.Set(math.MinInt, 0, 1)
+	g.Set(math.MaxInt, 0, 2)
+	g.Set(0, math.MaxInt, 3)
+	if got := sortPoints(rangeRect(g, math.MinInt, math.MinInt, math.MaxInt, math.MaxInt)); len(got) != 3 {
+		t.Fatalf("RangeRect over every int = %v, want all 3 cells", got)
+	}
+	// spans of one cell at the ends of the int range must stop there
+	if got := rangeRect(g, math.MaxInt, 0, math.MaxInt, 0); !slices.Equal(got, []Point{{math.MaxInt, 0}}) {
+		t.Fatalf("RangeRect at MaxInt = %v", got)
+	}
+	if got := rangeRect(g, math.MinInt, 0, math.MinInt+1, 0); !slices.Equal(got, []Point{{math.MinInt, 0}}) {
+		t.Fatalf("RangeRect at MinInt = %v", got)
+	}
+	if got := rangeRect(g, 1, 0, 0, 0); got != nil {
+		t.Fatalf("RangeRect with min above max = %v", got)
+	}
+}
+
+func TestGridNeighbors(t *testing.T) {
+	g := MakeGridMap[int]()
+	for y := -1; y <= 1; y++ {
+		for x := -1; x <= 1; x++ {
+			g.Set(x, y, 10*y+x)
+		}
+	}
+	g.Delete(1, 1)
+	var all, orthogonal []Point
+	g.Neighbors(0, 0, func(x, y, value int) bool {
+		if value != 10*y+x {
+			t.Fatalf("Neighbors passed %d for (%d, %d)", value, x, y)
+		}
+		all = append(all, Point{x, y})
+		return true
+	})
+	g.OrthogonalNeighbors(0, 0, func(x, y, _ int) bool {
+		orthogonal = append(orthogonal, Point{x, y})
+		return true
+	})
+	if want := []Point{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}}; !slices.Equal(all, want) {
+		t.Fatalf("Neighbors = %v, want %v row by row without the empty cell", all, want)
+	}
+	if want := []Point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}; !slices.Equal(orthogonal, want) {
+		t.Fatalf("OrthogonalNeighbors = %v, want %v", orthogonal, want)
+	}
+	visited := 0
+	g.Neighbors(0, 0, func(int, int, int) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Fatalf("Neighbors went on after fn returned false, %d visits", visited)
+	}
+}
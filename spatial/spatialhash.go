@@ -0,0 +1,187 @@
+package spatial
+
+import (
+	"encoding/binary"
+	"iter"
+	"math"
+
+	"hashmaps/chainedmap"
+	"hashmaps/hashset"
+	"hashmaps/internal/hashing"
+)
+
+// Vec3 is a position in space, 2D users simply leave Z at 0
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+type cellKey struct {
+	X, Y, Z int64
+}
+
+// SpatialHash buckets items into cubic cells of a fixed size, so proximity
+// queries only look at the cells overlapping the queried region instead of
+// at every item. Items are identified by a comparable handle, e.g. an entity id.
+// The cell size should be around the typical query radius.
+type SpatialHash[T comparable] struct {
+	cellSize  float64
+	cells     *chainedmap.HashMap[cellKey, *hashset.Set[T]] // only occupied cells
+	positions *chainedmap.HashMap[T, Vec3]
+}
+
+func MakeSpatialHash[T comparable](cellSize float64) *SpatialHash[T] {
+	if !(cellSize > 0) {
+		cellSize = 1
+	}
+	return &SpatialHash[T]{
+		cellSize:  cellSize,
+		cells:     chainedmap.MakeHashMapWithHasher[cellKey, *hashset.Set[T]](cellHasher()),
+		positions: chainedmap.MakeHashMap[T, Vec3](),
+	}
+}
+
+// cellHasher hashes the three coordinates with a seed of its own, the
+// built-in hash would gob encode every cellKey
+func cellHasher() chainedmap.Hasher[cellKey] {
+	seed := hashing.MakeSeed[cellKey]()
+	return func(cell cellKey) uint64 {
+		var b [24]byte
+		binary.LittleEndian.PutUint64(b[0:], uint64(cell.X))
+		binary.LittleEndian.PutUint64(b[8:], uint64(cell.Y))
+		binary.LittleEndian.PutUint64(b[16:], uint64(cell.Z))
+		return seed.Bytes(b[:])
+	}
+}
+
+func (s *SpatialHash[T]) Len() int {
+	return s.positions.Len()
+}
+
+// Insert places item at position, an item inserted again is moved
+func (s *SpatialHash[T]) Insert(item T, position Vec3) {
+	if old := s.positions.Get(item); old != nil {
+		s.removeFromCell(item, s.cellOf(*old))
+	}
+	s.positions.Set(item, position)
+	s.cells.GetOrCompute(s.cellOf(position), hashset.MakeSet[T]).Add(item)
+}
+
+// Move changes the position of an inserted item, returns false for unknown items
+func (s *SpatialHash[T]) Move(item T, position Vec3) bool {
+	old := s.positions.Get(item)
+	if old == nil {
+		return false
+	}
+	if s.cellOf(*old) == s.cellOf(position) { // cheap path, the item stays in its cell
+		*old = position
+		return true
+	}
+	s.Insert(item, position)
+	return true
+}
+
+func (s *SpatialHash[T]) Remove(item T) bool {
+	position, ok := s.positions.Delete(item)
+	if !ok {
+		return false
+	}
+	s.removeFromCell(item, s.cellOf(position))
+	return true
+}
+
+func (s *SpatialHash[T]) Position(item T) (Vec3, bool) {
+	if position := s.positions.Get(item); position != nil {
+		return *position, true
+	}
+	return Vec3{}, false
+}
+
+// All yields every item with its position, in no particular order.
+// The hash must not be modified during the loop.
+func (s *SpatialHash[T]) All() iter.Seq2[T, Vec3] {
+	return s.positions.All()
+}
+
+// QueryAABB visits items inside the axis aligned box min..max (inclusive)
+// until fn returns false
+func (s *SpatialHash[T]) QueryAABB(min, max Vec3, fn func(item T, position Vec3) bool) {
+	s.queryCells(min, max, func(item T, position Vec3) bool {
+		if position.X < min.X || position.X > max.X ||
+			position.Y < min.Y || position.Y > max.Y ||
+			position.Z < min.Z || position.Z > max.Z {
+			return true
+		}
+		return fn(item, position)
+	})
+}
+
+// QueryRadius visits items within radius of center until fn returns false
+func (s *SpatialHash[T]) QueryRadius(center Vec3, radius float64, fn func(item T, position Vec3) bool) {
+	min := Vec3{center.X - radius, center.Y - radius, center.Z - radius}
+	max := Vec3{center.X + radius, center.Y + radius, center.Z + radius}
+	radiusSquared := radius * radius
+	s.queryCells(min, max, func(item T, position Vec3) bool {
+		dx, dy, dz := position.X-center.X, position.Y-center.Y, position.Z-center.Z
+		if dx*dx+dy*dy+dz*dz > radiusSquared {
+			return true
+		}
+		return fn(item, position)
+	})
+}
+
+// queryCells visits every item in cells overlapping min..max. When the box spans
+// more cells than there are occupied ones, occupied cells are filtered instead.
+func (s *SpatialHash[T]) queryCells(min, max Vec3, fn func(item T, position Vec3) bool) {
+	from, to := s.cellOf(min), s.cellOf(max)
+	if from.X > to.X || from.Y > to.Y || from.Z > to.Z {
+		return
+	}
+	spanned := float64(to.X-from.X+1) * float64(to.Y-from.Y+1) * float64(to.Z-from.Z+1)
+	if spanned > float64(s.cells.Len()) {
+		s.cells.Range(func(cell cellKey, items *hashset.Set[T]) bool {
+			if cell.X < from.X || cell.X > to.X || cell.Y < from.Y || cell.Y > to.Y || cell.Z < from.Z || cell.Z > to.Z {
+				return true
+			}
+			return s.visitCell(items, fn)
+		})
+		return
+	}
+	for x := from.X; x <= to.X; x++ {
+		for y := from.Y; y <= to.Y; y++ {
+			for z := from.Z; z <= to.Z; z++ {
+				items := s.cells.Get(cellKey{x, y, z})
+				if items != nil && !s.visitCell(*items, fn) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *SpatialHash[T]) visitCell(items *hashset.Set[T], fn func(item T, position Vec3) bool) bool {
+	keepGoing := true
+	items.Range(func(item T) bool {
+		keepGoing = fn(item, *s.positions.Get(item))
+		return keepGoing
+	})
+	return keepGoing
+}
+
+func (s *SpatialHash[T]) removeFromCell(item T, cell cellKey) {
+	items := s.cells.Get(cell)
+	if items == nil {
+		return
+	}
+	(*items).Remove(item)
+	if (*items).Len() == 0 {
+		s.cells.Delete(cell)
+	}
+}
+
+func (s *SpatialHash[T]) cellOf(position Vec3) cellKey {
+	return cellKey{
+		X: int64(math.Floor(position.X / s.cellSize)),
+		Y: int64(math.Floor(position.Y / s.cellSize)),
+		Z: int64(math.Floor(position.Z / s.cellSize)),
+	}
+}
@@ -0,0 +1,133 @@
+package spatial
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func queryAABB(s *SpatialHash[int], min, max Vec3) []int {
+	var items []int
+	s.QueryAABB(min, max, func(item int, _ Vec3) bool {
+		items = append(items, item)
+		return true
+	})
+	slices.Sort(items)
+	return items
+}
+
+func queryRadius(s *SpatialHash[int], center Vec3, radius float64) []int {
+	var items []int
+	s.QueryRadius(center, radius, func(item int, _ Vec3) bool {
+		items = append(items, item)
+		return true
+	})
+	slices.Sort(items)
+	return items
+}
+
+func TestSpatialHashCellBoundaries(t *testing.T) {
+	s := MakeSpatialHash[int](10)
+	s.Insert(1, Vec3{10, 0, 0})    // first point of cell 1
+	s.Insert(2, Vec3{9.999, 0, 0}) // last bit of cell 0
+	s.Insert(3, Vec3{-0.001, 0, 0})
+	s.Insert(4, Vec3{-10, 0, 0}) // first point of cell -1
+	s.Insert(5, Vec3{-10.001, -10, -10})
+	if s.cellOf(Vec3{-0.001, 0, 0}).X != -1 || s.cellOf(Vec3{-10, 0, 0}).X != -1 || s.cellOf(Vec3{-10.001, 0, 0}).X != -2 {
+		t.Fatalf("negative coordinates must round down to their cell")
+	}
+	for _, tc := range []struct {
+		min, max Vec3
+		want     []int
+	}{
+		{Vec3{10, 0, 0}, Vec3{20, 0, 0}, []int{1}},
+		{Vec3{0, 0, 0}, Vec3{10, 0, 0}, []int{1, 2}},
+		{Vec3{-10, 0, 0}, Vec3{0, 0, 0}, []int{3, 4}},
+		{Vec3{-10.001, -10, -10}, Vec3{-10.001, -10, -10}, []int{5}},
+		{Vec3{-100, -100, -100}, Vec3{100, 100, 100}, []int{1, 2, 3, 4, 5}},
+		{Vec3{1, 0, 0}, Vec3{0, 0, 0}, nil}, // an empty box
+	} {
+		if got := queryAABB(s, tc.min, tc.max); !slices.Equal(got, tc.want) {
+			t.Fatalf("QueryAABB(%v, %v) = %v, want %v", tc.min, tc.max, got, tc.want)
+		}
+	}
+	if got := queryRadius(s, Vec3{0, 0, 0}, 10); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Fatalf("QueryRadius(0, 10) = %v, want [1 2 3 4]", got)
+	}
+}
+
+func TestSpatialHashMoveAndRemove(t *testing.T) {
+	s := MakeSpatialHash[int](1)
+	s.Insert(1, Vec3{0.5, 0.5, 0})
+	if !s.Move(1, Vec3{0.9, 0.1, 0}) { // same cell
+		t.Fatalf("Move of an inserted item = false")
+	}
+	if !s.Move(1, Vec3{-0.5, -0.5, 0}) { // across the origin into cell -1, -1
+		t.Fatalf("Move across cells = false")
+	}
+	if got := queryAABB(s, Vec3{0, 0, 0}, Vec3{1, 1, 0}); len(got) != 0 {
+		t.Fatalf("item still found in its old cell: %v", got)
+	}
+	if got := queryAABB(s, Vec3{-1, -1, 0}, Vec3{-0.5, -0.5, 0}); !slices.Equal(got, []int{1}) {
+		t.Fatalf("item not found in its new cell: %v", got)
+	}
+	if position, _ := s.Position(1); position != (Vec3{-0.5, -0.5, 0}) {
+		t.Fatalf("Position(1) = %v after Move", position)
+	}
+	if s.Move(2, Vec3{}) {
+		t.Fatalf("Move of an unknown item = true")
+	}
+	s.Insert(2, Vec3{-0.5, -0.5, 0})
+	if !s.Remove(1) || s.Remove(1) {
+		t.Fatalf("Remove(1) twice must be true, then false")
+	}
+	if s.cells.Len() != 1 {
+		t.Fatalf("%d occupied cells with one item left", s.cells.Len())
+	}
+	s.Remove(2)
+	if s.Len() != 0 || s.cells.Len() != 0 {
+		t.Fatalf("Len() = %d with %d cells after removing everything", s.Len(), s.cells.Len())
+	}
+}
+
+// The queries must find exactly what a scan of every item finds, whether
+// they walk the cells of the box or filter the occupied ones
+func TestSpatialHashAgainstScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := MakeSpatialHash[int](4)
+	positions := map[int]Vec3{}
+	coordinate := func() float64 { return float64(r.IntN(81)-40) / 2 } // lands on cell edges too
+	for i := 0; i < 300; i++ {
+		item := r.IntN(200)
+		switch r.IntN(4) {
+		case 0:
+			s.Remove(item)
+			delete(positions, item)
+		case 1:
+			if s.Move(item, Vec3{coordinate(), coordinate(), 0}) {
+				positions[item], _ = s.Position(item)
+			}
+		default:
+			position := Vec3{coordinate(), coordinate(), coordinate()}
+			s.Insert(item, position)
+			positions[item] = position
+		}
+	}
+	if s.Len() != len(positions) {
+		t.Fatalf("Len() = %d, want %d", s.Len(), len(positions))
+	}
+	for i := 0; i < 100; i++ {
+		min := Vec3{coordinate(), coordinate(), coordinate()}
+		max := Vec3{min.X + float64(r.IntN(40)), min.Y + float64(r.IntN(40)), min.Z + float64(r.IntN(40))}
+		var want []int
+		for item, p := range positions {
+			if p.X >= min.X && p.X <= max.X && p.Y >= min.Y && p.Y <= max.Y && p.Z >= min.Z && p.Z <= max.Z {
+				want = append(want, item)
+			}
+		}
+		slices.Sort(want)
+		if got := queryAABB(s, min, max); !slices.Equal(got, want) {
+			t.Fatalf("QueryAABB(%v, %v) = %v, want %v", min, max, got, want)
+		}
+	}
+}
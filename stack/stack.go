@@ -0,0 +1,52 @@
+// Package stack is a LIFO stack over a slice
+package stack
+
+import "iter"
+
+type Stack[T any] struct {
+	items []T // the top is at the end
+}
+
+func MakeStack[T any]() *Stack[T] {
+	return &Stack[T]{}
+}
+
+func (s *Stack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+// Pop returns false when the stack is empty
+func (s *Stack[T]) Pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	top := s.items[len(s.items)-1]
+	s.items[len(s.items)-1] = zero // don't keep what it points to alive
+	s.items = s.items[:len(s.items)-1]
+	return top, true
+}
+
+func (s *Stack[T]) Peek() (T, bool) {
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
+
+// All yields the items from the top to the bottom, the order Pop would
+// return them in. The stack must not be modified during the loop.
+func (s *Stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(s.items) - 1; i >= 0; i-- {
+			if !yield(s.items[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package stack
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestAgainstSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := MakeStack[int]()
+	var want []int // the top at the end, like the stack itself
+	for i := 0; i < 2000; i++ {
+		if r.IntN(3) > 0 {
+			s.Push(i)
+			want = append(want, i)
+		} else {
+			top, ok := s.Pop()
+			if ok != (len(want) > 0) || ok && top != want[len(want)-1] {
+				t.Fatalf("Pop() = %d, %v with %d items", top, ok, len(want))
+			}
+			if ok {
+				want = want[:len(want)-1]
+			}
+		}
+		if top, ok := s.Peek(); ok != (len(want) > 0) || ok && top != want[len(want)-1] || s.Len() != len(want) {
+			t.Fatalf("Peek() = %d, %v with Len() %d, want %d items", top, ok, s.Len(), len(want))
+		}
+	}
+	reversed := slices.Clone(want)
+	slices.Reverse(reversed)
+	if got := slices.Collect(s.All()); !slices.Equal(got, reversed) {
+		t.Fatalf("All() = %v, want %v", got, reversed)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	s := MakeStack[string]()
+	if _, ok := s.Pop(); ok {
+		t.Fatalf("Pop() on an empty stack = true")
+	}
+	if _, ok := s.Peek(); ok {
+		t.Fatalf("Peek() on an empty stack = true")
+	}
+	s.Push("a")
+	s.Pop()
+	if top, ok := s.Pop(); ok || top != "" || s.Len() != 0 {
+		t.Fatalf("Pop() = %q, %v after popping the only item", top, ok)
+	}
+	for range s.All() {
+		t.Fatalf("All() yields from an empty stack")
+	}
+}
+
+func TestPopClearsTheSlot(t *testing.T) {
+	s := MakeStack[*int]()
+	s.Push(new(int))
+	s.Pop()
+	if s.items[:1][0] != nil {
+		t.Fatalf("Pop left the item in the backing array")
+	}
+}
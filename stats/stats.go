@@ -0,0 +1,127 @@
+// Package stats accumulates running statistics per key, without keeping
+// the samples around.
+package stats
+
+import (
+	"iter"
+	"math"
+
+	"hashmaps/chainedmap"
+	"hashmaps/constraints"
+)
+
+// Summary is the state of the statistics of a single key
+type Summary[N constraints.Number] struct {
+	Count int64
+	Mean  float64
+	M2    float64 // sum of squared differences from the mean, see Variance
+	Min   N
+	Max   N
+}
+
+// Variance is the population variance, 0 with fewer than two samples
+func (s Summary[N]) Variance() float64 {
+	if s.Count < 2 {
+		return 0
+	}
+	return s.M2 / float64(s.Count)
+}
+
+// SampleVariance uses Bessel's correction, 0 with fewer than two samples
+func (s Summary[N]) SampleVariance() float64 {
+	if s.Count < 2 {
+		return 0
+	}
+	return s.M2 / float64(s.Count-1)
+}
+
+func (s Summary[N]) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
+
+// add is one step of Welford's algorithm, numerically stable unlike
+// keeping a sum and a sum of squares
+func (s *Summary[N]) add(x N) {
+	if s.Count == 0 || x < s.Min {
+		s.Min = x
+	}
+	if s.Count == 0 || x > s.Max {
+		s.Max = x
+	}
+	s.Count++
+	delta := float64(x) - s.Mean
+	s.Mean += delta / float64(s.Count)
+	s.M2 += delta * (float64(x) - s.Mean)
+}
+
+// merge combines two summaries as if all samples were added to one (Chan et al.)
+func (s *Summary[N]) merge(other Summary[N]) {
+	if other.Count == 0 {
+		return
+	}
+	if s.Count == 0 {
+		*s = other
+		return
+	}
+	count := s.Count + other.Count
+	delta := other.Mean - s.Mean
+	s.M2 += other.M2 + delta*delta*float64(s.Count)*float64(other.Count)/float64(count)
+	s.Mean += delta * float64(other.Count) / float64(count)
+	s.Count = count
+	if other.Min < s.Min {
+		s.Min = other.Min
+	}
+	if other.Max > s.Max {
+		s.Max = other.Max
+	}
+}
+
+// StatsMap keeps count, mean, variance, min and max of the values recorded
+// for every key in O(1) memory per key. It is not safe for concurrent use,
+// per-goroutine maps can be combined with Merge instead.
+type StatsMap[K comparable, N constraints.Number] struct {
+	summaries *chainedmap.HashMap[K, Summary[N]]
+}
+
+func MakeStatsMap[K comparable, N constraints.Number]() *StatsMap[K, N] {
+	return &StatsMap[K, N]{summaries: chainedmap.MakeHashMap[K, Summary[N]]()}
+}
+
+// Record adds x to the summary of key, looking key up once
+func (m *StatsMap[K, N]) Record(key K, x N) {
+	m.summaries.Entry(key).OrInsert(Summary[N]{}).add(x)
+}
+
+func (m *StatsMap[K, N]) Summary(key K) (Summary[N], bool) {
+	if summary := m.summaries.Get(key); summary != nil {
+		return *summary, true
+	}
+	return Summary[N]{}, false
+}
+
+func (m *StatsMap[K, N]) Len() int {
+	return m.summaries.Len()
+}
+
+func (m *StatsMap[K, N]) Delete(key K) bool {
+	_, ok := m.summaries.Delete(key)
+	return ok
+}
+
+// Range visits keys in no particular order until fn returns false
+func (m *StatsMap[K, N]) Range(fn func(key K, summary Summary[N]) bool) {
+	m.summaries.Range(fn)
+}
+
+// All is Range as an iter.Seq2
+func (m *StatsMap[K, N]) All() iter.Seq2[K, Summary[N]] {
+	return m.Range
+}
+
+// Merge adds everything recorded in other, key by key
+func (m *StatsMap[K, N]) Merge(other *StatsMap[K, N]) {
+	other.summaries.Range(func(key K, summary Summary[N]) bool {
+		m.summaries.Entry(key).Update(func(existing *Summary[N]) { existing.merge(summary) }).OrInsert(summary)
+		return true
+	})
+}
@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// direct computes the summary the way a textbook does, from all samples
+func direct(samples []int) Summary[int] {
+	s := Summary[int]{Count: int64(len(samples)), Min: samples[0], Max: samples[0]}
+	sum := 0.0
+	for _, x := range samples {
+		sum += float64(x)
+		s.Min, s.Max = min(s.Min, x), max(s.Max, x)
+	}
+	s.Mean = sum / float64(len(samples))
+	for _, x := range samples {
+		s.M2 += (float64(x) - s.Mean) * (float64(x) - s.Mean)
+	}
+	return s
+}
+
+func checkSummary(t *testing.T, key string, got, want Summary[int]) {
+	t.Helper()
+	if got.Count != want.Count || got.Min != want.Min || got.Max != want.Max || !near(got.Mean, want.Mean) || !near(got.M2, want.M2) {
+		t.Fatalf("Summary(%s) = %+v, want %+v", key, got, want)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	m := MakeStatsMap[string, int]()
+	samples := map[string][]int{}
+	for i := 0; i < 1000; i++ {
+		key := string(rune('a' + r.IntN(5)))
+		x := r.IntN(2001) - 1000
+		m.Record(key, x)
+		samples[key] = append(samples[key], x)
+	}
+	if m.Len() != len(samples) {
+		t.Fatalf("Len() = %d, want %d", m.Len(), len(samples))
+	}
+	for key, xs := range samples {
+		got, ok := m.Summary(key)
+		if !ok {
+			t.Fatalf("Summary(%s) missing", key)
+		}
+		checkSummary(t, key, got, direct(xs))
+	}
+	if !m.Delete("a") || m.Delete("a") {
+		t.Fatalf("Delete(a) twice must be true, then false")
+	}
+	if _, ok := m.Summary("a"); ok {
+		t.Fatalf("Summary(a) found after Delete")
+	}
+}
+
+func TestVariance(t *testing.T) {
+	m := MakeStatsMap[int, float64]()
+	m.Record(1, 5)
+	if s, _ := m.Summary(1); s.Variance() != 0 || s.SampleVariance() != 0 {
+		t.Fatalf("variance of one sample = %v, %v, want 0", s.Variance(), s.SampleVariance())
+	}
+	for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		m.Record(2, x)
+	}
+	s, _ := m.Summary(2)
+	if s.Mean != 5 || s.Variance() != 4 || s.StdDev() != 2 || !near(s.SampleVariance(), 32.0/7) {
+		t.Fatalf("mean %v, variance %v, stddev %v, sample variance %v, want 5, 4, 2, 32/7",
+			s.Mean, s.Variance(), s.StdDev(), s.SampleVariance())
+	}
+}
+
+// Merging per-goroutine maps must give what recording everything in one does
+func TestMerge(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	a, b, all := MakeStatsMap[string, int](), MakeStatsMap[string, int](), MakeStatsMap[string, int]()
+	for i := 0; i < 500; i++ {
+		key := string(rune('a' + r.IntN(6)))
+		x := r.IntN(100)
+		all.Record(key, x)
+		if key == "f" || key != "e" && r.IntN(2) == 0 { // e only in b, f only in a
+			a.Record(key, x)
+		} else {
+			b.Record(key, x)
+		}
+	}
+	a.Merge(b)
+	if a.Len() != all.Len() {
+		t.Fatalf("Len() = %d after Merge, want %d", a.Len(), all.Len())
+	}
+	for key, want := range all.All() {
+		got, _ := a.Summary(key)
+		checkSummary(t, key, got, want)
+	}
+	if _, ok := b.Summary("f"); ok {
+		t.Fatalf("Merge modified its argument")
+	}
+}
@@ -0,0 +1,19 @@
+package swissmap
+
+import "hashmaps/internal/mapjson"
+
+// MarshalJSON writes the map as internal/mapjson describes, the pairs of
+// non-string keys come group by group
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mapjson.Marshal(m.Range, m.Len())
+}
+
+// UnmarshalJSON adds the decoded entries through TrySet. A zero HashMap,
+// e.g. one that json.Unmarshal allocated, has no control bytes to probe
+// and is made ready first.
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	if m.ctrl == nil {
+		*m = *MakeHashMap[K, V]()
+	}
+	return mapjson.Unmarshal(data, m.TrySet)
+}
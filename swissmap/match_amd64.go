@@ -0,0 +1,12 @@
+//go:build !purego && !tinygo
+
+package swissmap
+
+// matchGroup and matchEmptyOrDeleted compare the 16 control bytes with SSE2,
+// see match_amd64.s. Every amd64 CPU has SSE2, so there is no runtime check.
+
+//go:noescape
+func matchGroup(ctrl []byte, h2 byte) (matches, empty uint32)
+
+//go:noescape
+func matchEmptyOrDeleted(ctrl []byte) uint32
@@ -0,0 +1,34 @@
+//go:build !purego && !tinygo
+
+#include "textflag.h"
+
+// func matchGroup(ctrl []byte, h2 byte) (matches, empty uint32)
+TEXT ·matchGroup(SB), NOSPLIT, $0-40
+	MOVQ    ctrl_base+0(FP), AX
+	MOVBLZX h2+24(FP), BX
+	MOVOU   (AX), X0
+
+	// h2 in all 16 bytes of X1
+	IMULL    $0x01010101, BX
+	MOVQ     BX, X1
+	PSHUFL   $0, X1, X1
+	PCMPEQB  X0, X1
+	PMOVMSKB X1, CX
+	MOVL     CX, matches+32(FP)
+
+	// ctrlEmpty in all 16 bytes of X2
+	MOVL     $0x80808080, DX
+	MOVQ     DX, X2
+	PSHUFL   $0, X2, X2
+	PCMPEQB  X0, X2
+	PMOVMSKB X2, CX
+	MOVL     CX, empty+36(FP)
+	RET
+
+// func matchEmptyOrDeleted(ctrl []byte) uint32
+TEXT ·matchEmptyOrDeleted(SB), NOSPLIT, $0-28
+	MOVQ     ctrl_base+0(FP), AX
+	MOVOU    (AX), X0
+	PMOVMSKB X0, CX
+	MOVL     CX, ret+24(FP)
+	RET
@@ -0,0 +1,47 @@
+//go:build !amd64 || purego || tinygo
+
+package swissmap
+
+import "encoding/binary"
+
+// The portable matching works on the two 8 byte halves of a group with
+// plain integer arithmetic. Each half gives a word with the high bit of
+// byte i set when control byte i matches, compress packs those into
+// bits i of the result like PMOVMSKB does.
+
+const (
+	lsbs = 0x0101010101010101
+	msbs = 0x8080808080808080
+)
+
+func matchGroup(ctrl []byte, h2 byte) (matches, empty uint32) {
+	lo, hi := binary.LittleEndian.Uint64(ctrl), binary.LittleEndian.Uint64(ctrl[8:groupSize])
+	matches = compress(matchByte(lo, h2)) | compress(matchByte(hi, h2))<<8
+	empty = compress(matchEmpty(lo)) | compress(matchEmpty(hi))<<8
+	return matches, empty
+}
+
+// matchEmptyOrDeleted: those are the only control bytes with the high bit set
+func matchEmptyOrDeleted(ctrl []byte) uint32 {
+	lo, hi := binary.LittleEndian.Uint64(ctrl), binary.LittleEndian.Uint64(ctrl[8:groupSize])
+	return compress(lo&msbs) | compress(hi&msbs)<<8
+}
+
+// matchByte may report false positives in bytes after a true match,
+// callers compare the keys anyway
+func matchByte(word uint64, b byte) uint64 {
+	x := word ^ (lsbs * uint64(b))
+	return (x - lsbs) &^ x & msbs
+}
+
+// matchEmpty: empty is the only control byte with the high bit set and bit 1 clear
+func matchEmpty(word uint64) uint64 {
+	return word &^ (word << 6) & msbs
+}
+
+// compress moves the high bit of byte i to bit i. After the shift the bits
+// sit at 8i, the multiplication adds a copy of each at 56+i and nothing
+// else reaches the top byte.
+func compress(word uint64) uint32 {
+	return uint32((word >> 7) * 0x0102040810204080 >> 56)
+}
@@ -0,0 +1,87 @@
+package swissmap
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"testing"
+)
+
+// reference matches one control byte at a time
+func reference(ctrl []byte, match func(c byte) bool) uint32 {
+	var mask uint32
+	for i, c := range ctrl[:groupSize] {
+		if match(c) {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+// TestMatchGroup runs in both builds, go test -tags purego covers the
+// portable matching on amd64
+func TestMatchGroup(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	controls := []byte{ctrlEmpty, ctrlDeleted, 0, 1, 0x3f, 0x7f}
+	ctrl := make([]byte, groupSize)
+	for round := 0; round < 10_000; round++ {
+		for i := range ctrl {
+			if random.IntN(2) == 0 {
+				ctrl[i] = controls[random.IntN(len(controls))]
+			} else {
+				ctrl[i] = byte(random.IntN(0x80))
+			}
+		}
+		h2 := byte(random.IntN(0x80))
+		matches, empty := matchGroup(ctrl, h2)
+		want := reference(ctrl, func(c byte) bool { return c == h2 })
+		// the portable matching may report extra bytes after a true match
+		if matches&want != want || matches&^want != 0 && (want == 0 || bits.TrailingZeros32(matches&^want) < bits.TrailingZeros32(want)) {
+			t.Fatalf("matchGroup(%x, %x) matches = %016b, want %016b", ctrl, h2, matches, want)
+		}
+		if want := reference(ctrl, func(c byte) bool { return c == ctrlEmpty }); empty != want {
+			t.Fatalf("matchGroup(%x) empty = %016b, want %016b", ctrl, empty, want)
+		}
+		free := matchEmptyOrDeleted(ctrl)
+		if want := reference(ctrl, func(c byte) bool { return c == ctrlEmpty || c == ctrlDeleted }); free != want {
+			t.Fatalf("matchEmptyOrDeleted(%x) = %016b, want %016b", ctrl, free, want)
+		}
+	}
+}
+
+func TestDeleteKeepsProbesWorking(t *testing.T) {
+	m := MakeHashMap[int, int]()
+	for i := 0; i < 10_000; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 10_000; i += 2 {
+		m.Delete(i)
+	}
+	for i := 0; i < 10_000; i++ {
+		value := m.Get(i)
+		if i%2 == 0 && value != nil || i%2 == 1 && (value == nil || *value != i) {
+			t.Fatalf("Get(%d) = %v after deleting the even keys", i, value)
+		}
+	}
+	if m.Len() != 5_000 {
+		t.Fatalf("Len = %d, want 5000", m.Len())
+	}
+}
+
+// BenchmarkMatchGroup measures the group scan alone, compare it with
+// go test -tags purego to see what the assembly gains
+func BenchmarkMatchGroup(b *testing.B) {
+	random := rand.New(rand.NewPCG(1, 2))
+	ctrl := make([]byte, 1<<10*groupSize) // fits in L1, so memory doesn't hide the scan
+	for i := range ctrl {
+		ctrl[i] = byte(random.IntN(0x80))
+	}
+	var sink uint32
+	for i := 0; i < b.N; i++ {
+		group := i & (1<<10 - 1) * groupSize
+		matches, empty := matchGroup(ctrl[group:group+groupSize], byte(i&0x7f))
+		sink += matches | empty
+	}
+	if sink == 1 {
+		b.Log(sink)
+	}
+}
@@ -0,0 +1,298 @@
+// Package swissmap is a hashmap in the style of SwissTable: open addressing
+// over groups of 16 slots, with one control byte per slot kept in a separate
+// array. A control byte says whether the slot is empty, deleted, or full,
+// and for full slots holds 7 bits of the key's hash. A lookup compares
+// those 16 bytes at once, with SSE2 on amd64 and as two uint64 elsewhere,
+// and only looks at keys whose fragment matches, so most of the time it
+// touches a single key.
+package swissmap
+
+import (
+	"iter"
+	"math/bits"
+
+	"hashmaps/internal/hashing"
+	"hashmaps/internal/keynorm"
+)
+
+type KVPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+const (
+	groupSize = 16
+
+	ctrlEmpty   = 0x80 // 1000_0000
+	ctrlDeleted = 0xFE // 1111_1110, a tombstone
+	// full slots hold 0xxx_xxxx, the low 7 bits of the hash
+
+	// the tables grow once live and deleted slots exceed 7/8 of the capacity
+	maxLoadNumerator   = 7
+	maxLoadDenominator = 8
+
+	defaultGroups = 1
+)
+
+// The hash is split in two: h1, the high 57 bits, picks the group the probe
+// starts at, and h2, the low 7 bits, goes into the control byte. Probing
+// moves by 1, 2, 3, ... groups from the start, which visits every group
+// when the number of groups is a power of two.
+type HashMap[K comparable, V any] struct {
+	ctrl  []byte         // len(ctrl) == len(slots), a multiple of groupSize
+	slots []KVPair[K, V] // slot i belongs to ctrl[i]
+	mask  uint64         // number of groups - 1
+
+	length  int
+	deleted int          // ctrlDeleted slots
+	seed    hashing.Seed // see internal/hashing
+
+	norm keynorm.Normalizer[K] // float keys and the NaNPolicy, see internal/keynorm
+}
+
+var ErrKeyEncoding = hashing.ErrKeyEncoding
+
+func MakeHashMap[K comparable, V any]() *HashMap[K, V] {
+	m, _ := MakeHashMapWithNaNPolicy[K, V](CanonicalizeNaN) // the default policy is always valid
+	return m
+}
+
+// NaNPolicy is what MakeHashMapWithNaNPolicy takes, it is internal/keynorm's,
+// so the other map packages take the same values. Under CanonicalizeNaN all
+// NaNs share one hash, so they can't pile up in a group.
+type NaNPolicy = keynorm.NaNPolicy
+
+const (
+	CanonicalizeNaN = keynorm.CanonicalizeNaN
+	RejectNaN       = keynorm.RejectNaN
+)
+
+var (
+	ErrNaNKey           = keynorm.ErrNaNKey
+	ErrInvalidNaNPolicy = keynorm.ErrInvalidNaNPolicy
+)
+
+func MakeHashMapWithNaNPolicy[K comparable, V any](nanPolicy NaNPolicy) (*HashMap[K, V], error) {
+	norm, err := keynorm.MakeNormalizer[K](nanPolicy)
+	if err != nil {
+		return nil, err
+	}
+	m := &HashMap[K, V]{
+		seed: hashing.MakeSeed[K](),
+		norm: norm,
+	}
+	m.allocate(defaultGroups)
+	return m, nil
+}
+
+func (m *HashMap[K, V]) allocate(groups int) {
+	m.ctrl = make([]byte, groups*groupSize)
+	for i := range m.ctrl {
+		m.ctrl[i] = ctrlEmpty
+	}
+	m.slots = make([]KVPair[K, V], groups*groupSize)
+	m.mask = uint64(groups - 1)
+	m.deleted = 0
+}
+
+// Get, Set, Delete and Remove panic when the key can't be hashed, e.g. a gob encoding failure.
+// TryGet, TrySet and TryDelete return such errors instead and never panic.
+
+func (m *HashMap[K, V]) Get(key K) *V {
+	value, err := m.TryGet(key)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (m *HashMap[K, V]) TryGet(key K) (*V, error) {
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return nil, nil
+	}
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return nil, err
+	}
+	index := m.find(key, hashedKey)
+	if index < 0 {
+		return nil, nil
+	}
+	return &m.slots[index].Value, nil
+}
+
+// Set also panics when the key is rejected by the NaNPolicy
+func (m *HashMap[K, V]) Set(key K, value V) {
+	if err := m.TrySet(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (m *HashMap[K, V]) TrySet(key K, value V) error {
+	key, err := m.norm.Normalize(key)
+	if err != nil {
+		return err
+	}
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return err
+	}
+	if index := m.find(key, hashedKey); index >= 0 {
+		m.slots[index].Value = value
+		return nil
+	}
+	if (m.length+m.deleted+1)*maxLoadDenominator > len(m.ctrl)*maxLoadNumerator {
+		m.rehash()
+	}
+	m.insert(hashedKey, KVPair[K, V]{Key: key, Value: value})
+	m.length++
+	return nil
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (m *HashMap[K, V]) Remove(key K) {
+	m.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (m *HashMap[K, V]) Delete(key K) (V, bool) {
+	value, ok, err := m.TryDelete(key)
+	if err != nil {
+		panic(err)
+	}
+	return value, ok
+}
+
+// Pop is Delete, the popped slot becomes a tombstone only when its group is full
+func (m *HashMap[K, V]) Pop(key K) (V, bool) {
+	return m.Delete(key)
+}
+
+// TryDelete leaves a tombstone only when the slot's group is full. A group
+// with an empty slot ends every probe that reaches it, so no probe can
+// depend on passing through it and the slot can simply become empty.
+func (m *HashMap[K, V]) TryDelete(key K) (V, bool, error) {
+	var value V
+	key, err := m.norm.Normalize(key)
+	if err != nil { // rejected keys are never stored
+		return value, false, nil
+	}
+	hashedKey, err := hashing.Hash(m.seed, key)
+	if err != nil {
+		return value, false, err
+	}
+	index := m.find(key, hashedKey)
+	if index < 0 {
+		return value, false, nil
+	}
+	value = m.slots[index].Value
+	m.slots[index] = KVPair[K, V]{} // drops the references held by the pair
+	group := index / groupSize
+	if _, empty := matchGroup(m.group(uint64(group)), 0); empty != 0 {
+		m.ctrl[index] = ctrlEmpty
+	} else {
+		m.ctrl[index] = ctrlDeleted
+		m.deleted++
+	}
+	m.length--
+	return value, true, nil
+}
+
+// Len returns the number of entries, in O(1)
+func (m *HashMap[K, V]) Len() int {
+	return m.length
+}
+
+// Range calls fn for every entry until fn returns false, like sync.Map.Range.
+// The map must not be modified by fn.
+func (m *HashMap[K, V]) Range(fn func(key K, value V) bool) {
+	for i, c := range m.ctrl {
+		if c&ctrlEmpty == 0 && !fn(m.slots[i].Key, m.slots[i].Value) {
+			return
+		}
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return m.Range
+}
+
+func (m *HashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (m *HashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// find returns the slot holding key, or -1
+func (m *HashMap[K, V]) find(key K, hashedKey uint64) int {
+	h2 := byte(hashedKey & 0x7f)
+	group := (hashedKey >> 7) & m.mask
+	for step := uint64(1); ; step++ {
+		matches, empty := matchGroup(m.group(group), h2)
+		for ; matches != 0; matches &= matches - 1 {
+			index := int(group)*groupSize + bits.TrailingZeros32(matches)
+			if m.norm.Equal(m.slots[index].Key, key) {
+				return index
+			}
+		}
+		if empty != 0 {
+			return -1
+		}
+		group = (group + step) & m.mask
+	}
+}
+
+// insert puts a pair whose key isn't in the map yet into the first empty
+// or deleted slot on its probe sequence
+func (m *HashMap[K, V]) insert(hashedKey uint64, pair KVPair[K, V]) {
+	group := (hashedKey >> 7) & m.mask
+	for step := uint64(1); ; step++ {
+		if free := matchEmptyOrDeleted(m.group(group)); free != 0 {
+			index := int(group)*groupSize + bits.TrailingZeros32(free)
+			if m.ctrl[index] == ctrlDeleted {
+				m.deleted--
+			}
+			m.ctrl[index] = byte(hashedKey & 0x7f)
+			m.slots[index] = pair
+			return
+		}
+		group = (group + step) & m.mask
+	}
+}
+
+// rehash drops the tombstones, and doubles the number of groups unless
+// the live entries alone fill less than half of the allowed load
+func (m *HashMap[K, V]) rehash() {
+	oldCtrl, oldSlots := m.ctrl, m.slots
+	groups := len(oldCtrl) / groupSize
+	if m.length*maxLoadDenominator*2 > len(oldCtrl)*maxLoadNumerator {
+		groups *= 2
+	}
+	m.allocate(groups)
+	for i, c := range oldCtrl {
+		if c&ctrlEmpty == 0 {
+			// keys in the map were hashed before
+			hashedKey, _ := hashing.Hash(m.seed, oldSlots[i].Key)
+			m.insert(hashedKey, oldSlots[i])
+		}
+	}
+}
+
+// group returns the 16 control bytes of a group. matchGroup and
+// matchEmptyOrDeleted turn them into a bitmask with bit i set when control
+// byte i matches, see match_amd64.s and match_generic.go.
+func (m *HashMap[K, V]) group(group uint64) []byte {
+	return m.ctrl[group*groupSize : (group+1)*groupSize]
+}
@@ -0,0 +1,407 @@
+// Package treemap is a sorted map backed by a red-black tree. It answers
+// what the hashmaps can't: the smallest or largest key, the nearest key
+// below or above a given one, and every key between two bounds, in order.
+// Lookups, inserts and deletes are O(log n).
+package treemap
+
+import (
+	"cmp"
+	"iter"
+
+	"hashmaps/constraints"
+)
+
+// Every node is red or black. The root is black, a red node has no red
+// children, and every path from a node down to a missing child passes
+// the same number of black nodes, so no path is more than twice as long
+// as another.
+type node[K, V any] struct {
+	key                 K
+	value               V
+	red                 bool
+	left, right, parent *node[K, V]
+}
+
+func isRed[K, V any](n *node[K, V]) bool {
+	return n != nil && n.red
+}
+
+// TreeMap orders its keys with less, which must be a strict weak ordering.
+// Keys a and b are the same key when neither is less than the other.
+type TreeMap[K, V any] struct {
+	root   *node[K, V]
+	length int
+	less   func(a, b K) bool
+}
+
+func MakeTreeMap[K, V any](less func(a, b K) bool) *TreeMap[K, V] {
+	return &TreeMap[K, V]{less: less}
+}
+
+// MakeOrderedTreeMap orders the keys with <, NaNs sort before every other float
+func MakeOrderedTreeMap[K constraints.Ordered, V any]() *TreeMap[K, V] {
+	return MakeTreeMap[K, V](cmp.Less[K])
+}
+
+// Get returns a pointer to the value of key, or nil
+func (t *TreeMap[K, V]) Get(key K) *V {
+	if n := t.find(key); n != nil {
+		return &n.value
+	}
+	return nil
+}
+
+func (t *TreeMap[K, V]) Set(key K, value V) {
+	var parent *node[K, V]
+	current := t.root
+	for current != nil {
+		parent = current
+		switch {
+		case t.less(key, current.key):
+			current = current.left
+		case t.less(current.key, key):
+			current = current.right
+		default:
+			current.value = value
+			return
+		}
+	}
+	n := &node[K, V]{key: key, value: value, red: true, parent: parent}
+	switch {
+	case parent == nil:
+		t.root = n
+	case t.less(key, parent.key):
+		parent.left = n
+	default:
+		parent.right = n
+	}
+	t.length++
+	t.insertFixup(n)
+}
+
+// Remove is Delete for callers that don't care about the old value
+func (t *TreeMap[K, V]) Remove(key K) {
+	t.Delete(key)
+}
+
+// Delete removes key and returns its value, ok is false when key wasn't there
+func (t *TreeMap[K, V]) Delete(key K) (V, bool) {
+	n := t.find(key)
+	if n == nil {
+		var zero V
+		return zero, false
+	}
+	t.delete(n)
+	t.length--
+	return n.value, true
+}
+
+// Len returns the number of entries, in O(1)
+func (t *TreeMap[K, V]) Len() int {
+	return t.length
+}
+
+// Min returns the entry with the smallest key, ok is false when the map is empty
+func (t *TreeMap[K, V]) Min() (key K, value V, ok bool) {
+	return t.root.minimum().entry()
+}
+
+// Max returns the entry with the largest key, ok is false when the map is empty
+func (t *TreeMap[K, V]) Max() (key K, value V, ok bool) {
+	return t.root.maximum().entry()
+}
+
+// Floor returns the entry with the largest key <= key, ok is false when there is none
+func (t *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	var floor *node[K, V]
+	for current := t.root; current != nil; {
+		if t.less(key, current.key) {
+			current = current.left
+		} else {
+			floor = current
+			current = current.right
+		}
+	}
+	return floor.entry()
+}
+
+// Ceiling returns the entry with the smallest key >= key, ok is false when there is none
+func (t *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return t.ceiling(key).entry()
+}
+
+// Range calls fn for every entry in ascending key order until fn returns false.
+// The map must not be modified by fn.
+func (t *TreeMap[K, V]) Range(fn func(key K, value V) bool) {
+	for n := t.root.minimum(); n != nil; n = n.successor() {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// RangeBetween is Range limited to the keys from from, inclusive, to to, exclusive
+func (t *TreeMap[K, V]) RangeBetween(from, to K, fn func(key K, value V) bool) {
+	for n := t.ceiling(from); n != nil && t.less(n.key, to); n = n.successor() {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// All, Keys and Values are the range-over-func forms of Range,
+// Between is the one of RangeBetween
+func (t *TreeMap[K, V]) All() iter.Seq2[K, V] {
+	return t.Range
+}
+
+func (t *TreeMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		t.Range(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (t *TreeMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		t.Range(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+func (t *TreeMap[K, V]) Between(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.RangeBetween(from, to, yield)
+	}
+}
+
+func (t *TreeMap[K, V]) find(key K) *node[K, V] {
+	current := t.root
+	for current != nil {
+		switch {
+		case t.less(key, current.key):
+			current = current.left
+		case t.less(current.key, key):
+			current = current.right
+		default:
+			return current
+		}
+	}
+	return nil
+}
+
+func (t *TreeMap[K, V]) ceiling(key K) *node[K, V] {
+	var ceiling *node[K, V]
+	for current := t.root; current != nil; {
+		if t.less(current.key, key) {
+			current = current.right
+		} else {
+			ceiling = current
+			current = current.left
+		}
+	}
+	return ceiling
+}
+
+// entry unpacks a node, ok is false for nil
+func (n *node[K, V]) entry() (key K, value V, ok bool) {
+	if n == nil {
+		return key, value, false
+	}
+	return n.key, n.value, true
+}
+
+func (n *node[K, V]) minimum() *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func (n *node[K, V]) maximum() *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+// successor is the next node in key order, or nil
+func (n *node[K, V]) successor() *node[K, V] {
+	if n.right != nil {
+		return n.right.minimum()
+	}
+	for n.parent != nil && n == n.parent.right {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// insertFixup restores the invariants after n was added as a red leaf,
+// the only one that can break is a red parent
+func (t *TreeMap[K, V]) insertFixup(n *node[K, V]) {
+	for isRed(n.parent) {
+		parent := n.parent
+		grandparent := parent.parent // exists, the root is black
+		if parent == grandparent.left {
+			if uncle := grandparent.right; isRed(uncle) {
+				// push the black down from the grandparent and continue above it
+				parent.red, uncle.red, grandparent.red = false, false, true
+				n = grandparent
+				continue
+			}
+			if n == parent.right {
+				n = parent
+				t.rotateLeft(n)
+				parent = n.parent
+			}
+			parent.red, grandparent.red = false, true
+			t.rotateRight(grandparent)
+		} else {
+			if uncle := grandparent.left; isRed(uncle) {
+				parent.red, uncle.red, grandparent.red = false, false, true
+				n = grandparent
+				continue
+			}
+			if n == parent.left {
+				n = parent
+				t.rotateRight(n)
+				parent = n.parent
+			}
+			parent.red, grandparent.red = false, true
+			t.rotateLeft(grandparent)
+		}
+	}
+	t.root.red = false
+}
+
+// delete unlinks n. When n has two children its successor takes its place,
+// the successor has no left child so it is easy to unlink itself.
+func (t *TreeMap[K, V]) delete(n *node[K, V]) {
+	removedRed := n.red
+	var child, parent *node[K, V] // child took the place of the removed node under parent
+	switch {
+	case n.left == nil:
+		child, parent = n.right, n.parent
+		t.transplant(n, n.right)
+	case n.right == nil:
+		child, parent = n.left, n.parent
+		t.transplant(n, n.left)
+	default:
+		successor := n.right.minimum()
+		removedRed = successor.red
+		child = successor.right
+		if successor.parent == n {
+			parent = successor
+		} else {
+			parent = successor.parent
+			t.transplant(successor, successor.right)
+			successor.right = n.right
+			successor.right.parent = successor
+		}
+		t.transplant(n, successor)
+		successor.left = n.left
+		successor.left.parent = successor
+		successor.red = n.red
+	}
+	n.left, n.right, n.parent = nil, nil, nil
+	if !removedRed {
+		t.deleteFixup(child, parent)
+	}
+}
+
+// deleteFixup restores the black heights after a black node was removed
+// above child, which may be nil. The paths through child are one black
+// node short until either child is red and turns black, or the missing
+// black is moved over from the sibling's side.
+func (t *TreeMap[K, V]) deleteFixup(child, parent *node[K, V]) {
+	for child != t.root && !isRed(child) {
+		if child == parent.left {
+			sibling := parent.right // exists, its side has a black node more than child's
+			if sibling.red {
+				sibling.red, parent.red = false, true
+				t.rotateLeft(parent)
+				sibling = parent.right
+			}
+			if !isRed(sibling.left) && !isRed(sibling.right) {
+				sibling.red = true
+				child, parent = parent, parent.parent
+				continue
+			}
+			if !isRed(sibling.right) {
+				sibling.left.red, sibling.red = false, true
+				t.rotateRight(sibling)
+				sibling = parent.right
+			}
+			sibling.red, parent.red, sibling.right.red = parent.red, false, false
+			t.rotateLeft(parent)
+		} else {
+			sibling := parent.left
+			if sibling.red {
+				sibling.red, parent.red = false, true
+				t.rotateRight(parent)
+				sibling = parent.left
+			}
+			if !isRed(sibling.left) && !isRed(sibling.right) {
+				sibling.red = true
+				child, parent = parent, parent.parent
+				continue
+			}
+			if !isRed(sibling.left) {
+				sibling.right.red, sibling.red = false, true
+				t.rotateLeft(sibling)
+				sibling = parent.left
+			}
+			sibling.red, parent.red, sibling.left.red = parent.red, false, false
+			t.rotateRight(parent)
+		}
+		child = t.root
+	}
+	if child != nil {
+		child.red = false
+	}
+}
+
+// transplant puts replacement, which may be nil, where n hangs
+func (t *TreeMap[K, V]) transplant(n, replacement *node[K, V]) {
+	switch {
+	case n.parent == nil:
+		t.root = replacement
+	case n == n.parent.left:
+		n.parent.left = replacement
+	default:
+		n.parent.right = replacement
+	}
+	if replacement != nil {
+		replacement.parent = n.parent
+	}
+}
+
+func (t *TreeMap[K, V]) rotateLeft(n *node[K, V]) {
+	right := n.right
+	n.right = right.left
+	if right.left != nil {
+		right.left.parent = n
+	}
+	t.transplant(n, right)
+	right.left = n
+	n.parent = right
+}
+
+func (t *TreeMap[K, V]) rotateRight(n *node[K, V]) {
+	left := n.left
+	n.left = left.right
+	if left.right != nil {
+		left.right.parent = n
+	}
+	t.transplant(n, left)
+	left.right = n
+	n.parent = left
+}
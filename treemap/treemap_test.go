@@ -0,0 +1,158 @@
+package treemap
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkRedBlack fails when a red node has a red child or two paths down
+// from the root pass different numbers of black nodes
+func checkRedBlack(t *testing.T, m *TreeMap[int, int]) {
+	t.Helper()
+	if isRed(m.root) {
+		t.Fatal("red root")
+	}
+	var blackHeight func(n *node[int, int]) int
+	blackHeight = func(n *node[int, int]) int {
+		if n == nil {
+			return 1
+		}
+		if n.red && (isRed(n.left) || isRed(n.right)) {
+			t.Fatalf("red node %d has a red child", n.key)
+		}
+		left, right := blackHeight(n.left), blackHeight(n.right)
+		if left != right {
+			t.Fatalf("black heights %d and %d below %d", left, right, n.key)
+		}
+		if !n.red {
+			left++
+		}
+		return left
+	}
+	blackHeight(m.root)
+}
+
+func between(t *testing.T, m *TreeMap[int, int], from, to int) []int {
+	t.Helper()
+	var keys []int
+	m.RangeBetween(from, to, func(key, value int) bool {
+		if value != -key {
+			t.Fatalf("RangeBetween(%d, %d) passed %d with the value %d", from, to, key, value)
+		}
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Floor, Ceiling and RangeBetween must find what a search of the sorted
+// keys finds, for present and missing keys, the ends and past them
+func TestOrderedQueriesAgainstSortedSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	m := MakeOrderedTreeMap[int, int]()
+	var keys []int // sorted, all even so odd probes are always missing
+	for i := 0; i < 2000; i++ {
+		key := 2 * r.IntN(500)
+		position, found := slices.BinarySearch(keys, key)
+		if r.IntN(3) == 0 {
+			if _, ok := m.Delete(key); ok != found {
+				t.Fatalf("Delete(%d) = %v, want %v", key, ok, found)
+			}
+			if found {
+				keys = slices.Delete(keys, position, position+1)
+			}
+		} else {
+			m.Set(key, -key)
+			if !found {
+				keys = slices.Insert(keys, position, key)
+			}
+		}
+	}
+	checkRedBlack(t, m)
+	if m.Len() != len(keys) || !slices.Equal(slices.Collect(m.Keys()), keys) {
+		t.Fatalf("Len() = %d with keys out of order", m.Len())
+	}
+	if low, _, _ := m.Min(); low != keys[0] {
+		t.Fatalf("Min() = %d, want %d", low, keys[0])
+	}
+	if high, _, _ := m.Max(); high != keys[len(keys)-1] {
+		t.Fatalf("Max() = %d, want %d", high, keys[len(keys)-1])
+	}
+	for probe := -3; probe <= 1003; probe++ {
+		position, found := slices.BinarySearch(keys, probe)
+		floor, floorValue, ok := m.Floor(probe)
+		switch {
+		case found:
+			if !ok || floor != probe || floorValue != -probe {
+				t.Fatalf("Floor(%d) = %d, %v for a present key", probe, floor, ok)
+			}
+		case position == 0:
+			if ok {
+				t.Fatalf("Floor(%d) = %d below the smallest key", probe, floor)
+			}
+		default:
+			if !ok || floor != keys[position-1] {
+				t.Fatalf("Floor(%d) = %d, %v, want %d", probe, floor, ok, keys[position-1])
+			}
+		}
+		ceiling, _, ok := m.Ceiling(probe)
+		if position == len(keys) {
+			if ok {
+				t.Fatalf("Ceiling(%d) = %d above the largest key", probe, ceiling)
+			}
+		} else if !ok || ceiling != keys[position] {
+			t.Fatalf("Ceiling(%d) = %d, %v, want %d", probe, ceiling, ok, keys[position])
+		}
+	}
+	for i := 0; i < 500; i++ {
+		from, to := r.IntN(1010)-5, r.IntN(1010)-5
+		low, _ := slices.BinarySearch(keys, from)
+		high, _ := slices.BinarySearch(keys, to)
+		want := keys[low:max(low, high)]
+		if got := between(t, m, from, to); !slices.Equal(got, want) {
+			t.Fatalf("RangeBetween(%d, %d) = %v, want %v", from, to, got, want)
+		}
+	}
+}
+
+func TestEmptyAndSingleEntry(t *testing.T) {
+	m := MakeOrderedTreeMap[int, int]()
+	if _, _, ok := m.Min(); ok {
+		t.Fatal("Min of an empty map found a key")
+	}
+	if _, _, ok := m.Floor(0); ok {
+		t.Fatal("Floor of an empty map found a key")
+	}
+	if _, _, ok := m.Ceiling(0); ok {
+		t.Fatal("Ceiling of an empty map found a key")
+	}
+	if got := between(t, m, -10, 10); len(got) != 0 {
+		t.Fatalf("RangeBetween on an empty map = %v", got)
+	}
+	m.Set(5, -5)
+	for _, tc := range []struct {
+		from, to int
+		want     []int
+	}{
+		{5, 6, []int{5}},
+		{5, 5, nil}, // to is exclusive
+		{6, 4, nil}, // from after to
+		{0, 5, nil},
+		{6, 100, nil},
+	} {
+		if got := between(t, m, tc.from, tc.to); !slices.Equal(got, tc.want) {
+			t.Fatalf("RangeBetween(%d, %d) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+	if floor, _, ok := m.Floor(4); ok {
+		t.Fatalf("Floor(4) = %d below the only key", floor)
+	}
+	if ceiling, _, ok := m.Ceiling(6); ok {
+		t.Fatalf("Ceiling(6) = %d above the only key", ceiling)
+	}
+	m.Remove(5)
+	if m.Len() != 0 || m.root != nil {
+		t.Fatal("Remove of the only key left a node")
+	}
+}